@@ -181,6 +181,30 @@ func getBoneRouter(d *Hoverfly) *bone.Mux {
 		negroni.HandlerFunc(am.RequireTokenAuthentication),
 		negroni.HandlerFunc(d.StatsHandler),
 	))
+	mux.Get("/metrics", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.PrometheusMetricsHandler),
+	))
+	mux.Get("/api/hooks", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetHooksHandler),
+	))
+	mux.Post("/api/hooks", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.AddHookHandler),
+	))
+	mux.Delete("/api/hooks", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteHooksHandler),
+	))
+	mux.Delete("/api/hooks/:id", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteHookHandler),
+	))
+	mux.Get("/api/events", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.EventsWSHandler),
+	))
 	// TODO: check auth for websocket connection
 	mux.Get("/api/statsws", http.HandlerFunc(d.StatsWSHandler))
 
@@ -227,6 +251,191 @@ func getBoneRouter(d *Hoverfly) *bone.Mux {
 		negroni.HandlerFunc(d.DeleteAllResponseDelaysHandler),
 	))
 
+	mux.Get("/api/throttles", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetThrottlesHandler),
+	))
+
+	mux.Put("/api/throttles", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateThrottlesHandler),
+	))
+
+	mux.Delete("/api/throttles", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteAllThrottlesHandler),
+	))
+
+	mux.Get("/api/faults", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetFaultsHandler),
+	))
+
+	mux.Put("/api/faults", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateFaultsHandler),
+	))
+
+	mux.Delete("/api/faults", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteAllFaultsHandler),
+	))
+
+	mux.Get("/api/chaos", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetChaosHandler),
+	))
+
+	mux.Put("/api/chaos", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateChaosHandler),
+	))
+
+	mux.Delete("/api/chaos", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteChaosHandler),
+	))
+
+	mux.Get("/api/rate-limits", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetRateLimitsHandler),
+	))
+
+	mux.Put("/api/rate-limits", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateRateLimitsHandler),
+	))
+
+	mux.Delete("/api/rate-limits", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteRateLimitsHandler),
+	))
+
+	mux.Get("/api/rate-limits/state", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetRateLimitStateHandler),
+	))
+
+	mux.Delete("/api/rate-limits/state", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteRateLimitStateHandler),
+	))
+
+	mux.Get("/api/journal", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetJournalHandler),
+	))
+
+	mux.Delete("/api/journal", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteJournalHandler),
+	))
+
+	mux.Post("/api/verify", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.VerifyHandler),
+	))
+
+	mux.Get("/api/coverage", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetCoverageHandler),
+	))
+
+	mux.Delete("/api/coverage", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteCoverageHandler),
+	))
+
+	mux.Get("/api/fingerprint", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetFingerprintPolicyHandler),
+	))
+
+	mux.Put("/api/fingerprint", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateFingerprintPolicyHandler),
+	))
+
+	mux.Delete("/api/fingerprint", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteFingerprintPolicyHandler),
+	))
+
+	mux.Get("/api/redaction", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetRedactionRulesHandler),
+	))
+
+	mux.Put("/api/redaction", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateRedactionRulesHandler),
+	))
+
+	mux.Delete("/api/redaction", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteRedactionRulesHandler),
+	))
+
+	mux.Get("/api/capture-filters", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetCaptureFiltersHandler),
+	))
+
+	mux.Put("/api/capture-filters", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateCaptureFiltersHandler),
+	))
+
+	mux.Delete("/api/capture-filters", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.DeleteCaptureFiltersHandler),
+	))
+
+	mux.Get("/api/capture-policy", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetCapturePolicyHandler),
+	))
+
+	mux.Put("/api/capture-policy", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateCapturePolicyHandler),
+	))
+
+	mux.Get("/api/history", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetCaptureHistoryListHandler),
+	))
+
+	mux.Get("/api/history/:key", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetCaptureHistoryHandler),
+	))
+
+	mux.Post("/api/history/:key/promote", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.PromoteCaptureHandler),
+	))
+
+	mux.Get("/api/capture-tags", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetCaptureTagsHandler),
+	))
+
+	mux.Put("/api/capture-tags", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateCaptureTagsHandler),
+	))
+
+	mux.Get("/api/latency-replay", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.GetLatencyReplayHandler),
+	))
+
+	mux.Put("/api/latency-replay", negroni.New(
+		negroni.HandlerFunc(am.RequireTokenAuthentication),
+		negroni.HandlerFunc(d.UpdateLatencyReplayHandler),
+	))
+
 	if d.Cfg.Development {
 		// since hoverfly is not started from cmd/hoverfly/hoverfly
 		// we have to target to that directory
@@ -264,16 +473,26 @@ func getBoneRouter(d *Hoverfly) *bone.Mux {
 	return mux
 }
 
-// AllRecordsHandler returns JSON content type http response
+// AllRecordsHandler returns JSON content type http response, records can be filtered by tag and
+// 'used=true' returns only records that were matched since coverage was last reset
 func (d *Hoverfly) AllRecordsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
-	records, err := d.RequestCache.GetAllValues()
+	records, err := d.RequestCache.GetAllEntries()
 
 	if err == nil {
 
 		var payloads []views.PayloadView
 
-		for _, v := range records {
-			if payload, err := models.NewPayloadFromBytes(v); err == nil {
+		tag := req.URL.Query().Get("tag")
+		used := req.URL.Query().Get("used") == "true"
+
+		for _, key := range sortedKeys(records) {
+			if payload, err := models.NewPayloadFromBytes(records[key]); err == nil {
+				if tag != "" && !payload.Metadata.HasTag(tag) {
+					continue
+				}
+				if used && d.RequestMatcher.Coverage.RecordHits(key) == 0 {
+					continue
+				}
 				payloadView := payload.ConvertToPayloadView()
 				payloads = append(payloads, *payloadView)
 			} else {
@@ -287,6 +506,12 @@ func (d *Hoverfly) AllRecordsHandler(w http.ResponseWriter, req *http.Request, n
 
 		var response views.PayloadViewData
 		response.Data = payloads
+		if policy := d.GetFingerprintPolicy(); policy != nil {
+			response.FingerprintPolicy = policy.ConvertToFingerprintPolicyView()
+		}
+		if rules := d.GetRedactionRules(); rules != nil {
+			response.RedactionRules = rules.ConvertToRedactionRulesView()
+		}
 		b, err := json.Marshal(response)
 
 		if err != nil {
@@ -430,6 +655,53 @@ func (d *Hoverfly) StatsWSHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// EventsWSHandler - sends every entry fired through hooks to the websocket as it happens, subscriber can
+// select action types with actionType query parameters
+func (d *Hoverfly) EventsWSHandler(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	actionTypes, err := ParseActionTypes(r.URL.Query()["actionType"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("failed to upgrade websocket")
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := d.Events.Subscribe(actionTypes)
+	defer unsubscribe()
+
+	// messages from subscribers are ignored, reading only detects closed connection
+	closed := make(chan struct{})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				close(closed)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			if err := conn.WriteJSON(event); err != nil {
+				log.WithFields(log.Fields{
+					"error": err.Error(),
+				}).Debug("Got error when writing event...")
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
 // ImportRecordsHandler - accepts JSON payload and saves it to cache
 func (d *Hoverfly) ImportRecordsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 
@@ -457,7 +729,7 @@ func (d *Hoverfly) ImportRecordsHandler(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	err = d.ImportPayloads(requests.Data)
+	err = d.ImportPayloadViewData(requests)
 
 	if err != nil {
 		response.Message = err.Error()
@@ -569,19 +841,12 @@ func (d *Hoverfly) ManualAddHandler(w http.ResponseWriter, req *http.Request, ne
 // DeleteAllRecordsHandler - deletes all captured requests
 func (d *Hoverfly) DeleteAllRecordsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 	err := d.RequestCache.DeleteData()
-
-	var en Entry
-	en.ActionType = ActionTypeWipeDB
-	en.Message = "wipe"
-	en.Time = time.Now()
-
-	if err := d.Hooks.Fire(ActionTypeWipeDB, &en); err != nil {
-		log.WithFields(log.Fields{
-			"error":      err.Error(),
-			"message":    en.Message,
-			"actionType": ActionTypeWipeDB,
-		}).Error("failed to fire hook")
+	if d.RequestMatcher.HistoryCache != nil {
+		d.RequestMatcher.HistoryCache.DeleteData()
 	}
+	d.RequestMatcher.Coverage.ResetRecords()
+
+	d.fireHook(ActionTypeWipeDB, "wipe", nil)
 
 	w.Header().Set("Content-Type", "application/json")
 
@@ -684,6 +949,7 @@ func (d *Hoverfly) ImportTemplatesHandler(w http.ResponseWriter, req *http.Reque
 // DeleteAllRecordsHandler - deletes all captured requests
 func (d *Hoverfly) DeleteAllTemplatesHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 	d.RequestMatcher.TemplateStore.Wipe()
+	d.RequestMatcher.Coverage.ResetTemplates()
 
 	// TODO: add hooks for consistency with records
 
@@ -746,7 +1012,7 @@ func (d *Hoverfly) StateHandler(w http.ResponseWriter, r *http.Request, next htt
 		return
 	}
 
-	if d.Cfg.Webserver {
+	if d.Cfg.Webserver && d.Cfg.WebserverUpstream == "" {
 		log.Error("Can't change state when configured as a webserver ")
 		http.Error(w, "Hoverfly is currently configured to act as webserver, which can only operate in simulate mode", 403)
 		return
@@ -757,6 +1023,7 @@ func (d *Hoverfly) StateHandler(w http.ResponseWriter, r *http.Request, next htt
 		"capture":    true,
 		"modify":     true,
 		"synthesize": true,
+		"spy":        true,
 	}
 
 	if sr.Mode != "" {
@@ -764,7 +1031,7 @@ func (d *Hoverfly) StateHandler(w http.ResponseWriter, r *http.Request, next htt
 			log.WithFields(log.Fields{
 				"suppliedMode": sr.Mode,
 			}).Error("Wrong mode found, can't change state")
-			http.Error(w, "Bad mode supplied, available modes: simulate, capture, modify, synthesize.", 400)
+			http.Error(w, "Bad mode supplied, available modes: simulate, capture, modify, synthesize, spy.", 400)
 			return
 		}
 		log.WithFields(log.Fields{
@@ -774,8 +1041,14 @@ func (d *Hoverfly) StateHandler(w http.ResponseWriter, r *http.Request, next htt
 		}).Info("Handling state change request!")
 
 		// setting new state
+		previousMode := d.Cfg.GetMode()
 		d.Cfg.SetMode(sr.Mode)
 
+		if sr.Mode != previousMode {
+			modeChange, _ := json.Marshal(views.ModeChangeView{Mode: sr.Mode, PreviousMode: previousMode})
+			d.fireHook(ActionTypeModeChanged, "mode changed", modeChange)
+		}
+
 	}
 
 	// checking whether we should update destination
@@ -787,19 +1060,7 @@ func (d *Hoverfly) StateHandler(w http.ResponseWriter, r *http.Request, next htt
 		}
 	}
 
-	var en Entry
-	en.ActionType = ActionTypeConfigurationChanged
-	en.Message = "changed"
-	en.Time = time.Now()
-	en.Data = body
-
-	if err := d.Hooks.Fire(ActionTypeConfigurationChanged, &en); err != nil {
-		log.WithFields(log.Fields{
-			"error":      err.Error(),
-			"message":    en.Message,
-			"actionType": ActionTypeConfigurationChanged,
-		}).Error("failed to fire hook")
-	}
+	d.fireConfigurationChanged("state", body)
 
 	var resp stateRequest
 	resp.Mode = d.Cfg.GetMode()
@@ -1003,6 +1264,7 @@ func (d *Hoverfly) GetResponseDelaysHandler(w http.ResponseWriter, req *http.Req
 
 func (d *Hoverfly) DeleteAllResponseDelaysHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 	d.ResponseDelays = &models.ResponseDelayList{}
+	d.fireConfigurationChanged("delays", nil)
 
 	var response messageResponse
 	response.Message = "Delays deleted successfuly"
@@ -1076,6 +1338,7 @@ func (d *Hoverfly) UpdateResponseDelaysHandler(w http.ResponseWriter, req *http.
 			w.WriteHeader(422)
 		} else {
 			d.UpdateResponseDelays(*rd.Data)
+			d.fireConfigurationChanged("delays", body)
 			mr.Message = "Response delays updated."
 			w.WriteHeader(201)
 		}
@@ -1095,6 +1358,108 @@ func (d *Hoverfly) UpdateResponseDelaysHandler(w http.ResponseWriter, req *http.
 
 }
 
+func (d *Hoverfly) GetThrottlesHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b := d.Throttles.Json()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+func (d *Hoverfly) DeleteAllThrottlesHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.Throttles = &models.ThrottleList{}
+	d.fireConfigurationChanged("throttles", nil)
+
+	var response messageResponse
+	response.Message = "Throttles deleted successfuly"
+	w.WriteHeader(200)
+
+	b, err := response.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+	return
+}
+
+func (d *Hoverfly) UpdateThrottlesHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var td models.ThrottleJson
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read response body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+
+		b, err := mr.Encode()
+		if err != nil {
+			// failed to read response body
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Could not encode response body!")
+			http.Error(w, "Failed to encode response", 500)
+			return
+		}
+		w.Write(b)
+		return
+	}
+
+	err = json.Unmarshal(body, &td)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if td.Data == nil {
+		log.Error("No throttle data in the request body!")
+		mr.Message = fmt.Sprintf("Failed to get data from the request body.")
+		w.WriteHeader(422)
+	} else {
+		err = models.ValidateThrottleJson(td)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Error validating throttles config supplied")
+			mr.Message = fmt.Sprintf("Failed to validate throttles config. Error: %s", err.Error())
+			w.WriteHeader(422)
+		} else {
+			d.UpdateThrottles(*td.Data)
+			d.fireConfigurationChanged("throttles", body)
+			mr.Message = "Throttles updated."
+			w.WriteHeader(201)
+		}
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+	return
+
+}
+
 func (d *Hoverfly) HealthHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -1115,3 +1480,1151 @@ func (d *Hoverfly) HealthHandler(w http.ResponseWriter, req *http.Request, next
 	w.Write(b)
 	return
 }
+
+// GetFingerprintPolicyHandler - returns current request fingerprint policy
+func (d *Hoverfly) GetFingerprintPolicyHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(d.GetFingerprintPolicy().ConvertToFingerprintPolicyView())
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// UpdateFingerprintPolicyHandler - sets new request fingerprint policy, stored records are re-keyed
+func (d *Hoverfly) UpdateFingerprintPolicyHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var pv views.FingerprintPolicyView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &pv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = d.SetFingerprintPolicy(models.NewFingerprintPolicyFromView(pv)); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to update fingerprint policy")
+		mr.Message = fmt.Sprintf("Failed to update fingerprint policy. Error: %s", err.Error())
+		w.WriteHeader(422)
+	} else {
+		d.fireConfigurationChanged("fingerprint", body)
+		mr.Message = "Fingerprint policy updated."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// DeleteFingerprintPolicyHandler - restores default request fingerprint policy
+func (d *Hoverfly) DeleteFingerprintPolicyHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var mr messageResponse
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	if err := d.SetFingerprintPolicy(nil); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to reset fingerprint policy")
+		mr.Message = fmt.Sprintf("Failed to reset fingerprint policy. Error: %s", err.Error())
+		w.WriteHeader(500)
+	} else {
+		d.fireConfigurationChanged("fingerprint", nil)
+		mr.Message = "Fingerprint policy reset to default."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetRedactionRulesHandler - returns rules used to redact secrets from captured payloads
+func (d *Hoverfly) GetRedactionRulesHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(d.GetRedactionRules().ConvertToRedactionRulesView())
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// UpdateRedactionRulesHandler - sets new redaction rules, stored payloads are redacted as well
+func (d *Hoverfly) UpdateRedactionRulesHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var rv views.RedactionRulesView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &rv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if rules, err := models.NewRedactionRulesFromView(rv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Error validating redaction rules supplied")
+		mr.Message = fmt.Sprintf("Failed to validate redaction rules. Error: %s", err.Error())
+		w.WriteHeader(422)
+	} else {
+		d.SetRedactionRules(rules)
+		d.fireConfigurationChanged("redaction", body)
+		mr.Message = "Redaction rules updated."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// DeleteRedactionRulesHandler - removes all redaction rules
+func (d *Hoverfly) DeleteRedactionRulesHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.SetRedactionRules(nil)
+	d.fireConfigurationChanged("redaction", nil)
+
+	var mr messageResponse
+	mr.Message = "Redaction rules deleted successfuly"
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(200)
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetCaptureFiltersHandler - returns filters deciding which exchanges are captured
+func (d *Hoverfly) GetCaptureFiltersHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(d.GetCaptureFilters().ConvertToCaptureFiltersView())
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// UpdateCaptureFiltersHandler - sets new capture filters
+func (d *Hoverfly) UpdateCaptureFiltersHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var cv views.CaptureFiltersView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &cv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if filters, err := models.NewCaptureFiltersFromView(cv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Error validating capture filters supplied")
+		mr.Message = fmt.Sprintf("Failed to validate capture filters. Error: %s", err.Error())
+		w.WriteHeader(422)
+	} else {
+		d.SetCaptureFilters(filters)
+		d.fireConfigurationChanged("capture-filters", body)
+		mr.Message = "Capture filters updated."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// DeleteCaptureFiltersHandler - removes all capture filters, every exchange is captured again
+func (d *Hoverfly) DeleteCaptureFiltersHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.SetCaptureFilters(nil)
+	d.fireConfigurationChanged("capture-filters", nil)
+
+	var mr messageResponse
+	mr.Message = "Capture filters deleted successfuly"
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(200)
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetCapturePolicyHandler - returns policy used when a request that was already captured is captured again
+func (d *Hoverfly) GetCapturePolicyHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(views.CapturePolicyView{Policy: d.GetCapturePolicy()})
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// UpdateCapturePolicyHandler - sets capture policy: overwrite, keep-first or append
+func (d *Hoverfly) UpdateCapturePolicyHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var pv views.CapturePolicyView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &pv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = d.SetCapturePolicy(pv.Policy); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Error validating capture policy supplied")
+		mr.Message = fmt.Sprintf("Failed to set capture policy. Error: %s", err.Error())
+		w.WriteHeader(422)
+	} else {
+		d.fireConfigurationChanged("capture-policy", body)
+		mr.Message = "Capture policy updated."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetCaptureHistoryListHandler - returns every request with capture history
+func (d *Hoverfly) GetCaptureHistoryListHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	list, err := d.GetCaptureHistoryList()
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b, err := json.Marshal(list)
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// GetCaptureHistoryHandler - returns every response captured for one request
+func (d *Hoverfly) GetCaptureHistoryHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	key := bone.GetValue(req, "key")
+
+	history, err := d.GetCaptureHistory(key)
+	if err != nil {
+		var mr messageResponse
+		mr.Message = fmt.Sprintf("No capture history found for key '%s'", key)
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusNotFound)
+		b, _ := mr.Encode()
+		w.Write(b)
+		return
+	}
+
+	b, err := json.Marshal(history)
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// PromoteCaptureHandler - makes older captured response the active response of its request
+func (d *Hoverfly) PromoteCaptureHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var pv views.PromoteCaptureView
+	var mr messageResponse
+
+	key := bone.GetValue(req, "key")
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &pv); err != nil || pv.Index == nil {
+		mr.Message = "Failed to decode request body, history entry index is required."
+		w.WriteHeader(400)
+	} else if _, err = d.RequestMatcher.GetCaptureHistory(key); err != nil {
+		mr.Message = fmt.Sprintf("No capture history found for key '%s'", key)
+		w.WriteHeader(http.StatusNotFound)
+	} else if err = d.RequestMatcher.PromoteCapture(key, *pv.Index); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+			"key":   key,
+		}).Error("Failed to promote captured response")
+		mr.Message = fmt.Sprintf("Failed to promote captured response. Error: %s", err.Error())
+		if matchingErr, ok := err.(*matching.MatchingError); ok {
+			w.WriteHeader(matchingErr.StatusCode)
+		} else {
+			w.WriteHeader(500)
+		}
+	} else {
+		log.WithFields(log.Fields{
+			"key":   key,
+			"index": *pv.Index,
+		}).Info("captured response promoted")
+		mr.Message = "Captured response promoted."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetCaptureTagsHandler - returns tags that are added to every captured payload
+func (d *Hoverfly) GetCaptureTagsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(views.CaptureTagsView{Tags: d.GetCaptureTags()})
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// UpdateCaptureTagsHandler - sets tags that are added to every captured payload
+func (d *Hoverfly) UpdateCaptureTagsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var tv views.CaptureTagsView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &tv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else {
+		d.SetCaptureTags(tv.Tags)
+		d.fireConfigurationChanged("capture-tags", body)
+		mr.Message = "Capture tags updated."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetLatencyReplayHandler - returns whether recorded latency is replayed in simulate mode
+func (d *Hoverfly) GetLatencyReplayHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(d.GetLatencyReplay().ConvertToLatencyReplayView())
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// UpdateLatencyReplayHandler - enables or disables replaying of recorded latency, factor defaults to 1
+func (d *Hoverfly) UpdateLatencyReplayHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var lv views.LatencyReplayView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &lv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = d.SetLatencyReplay(NewLatencyReplayFromView(lv)); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Error validating latency replay settings supplied")
+		mr.Message = fmt.Sprintf("Failed to update latency replay. Error: %s", err.Error())
+		w.WriteHeader(422)
+	} else {
+		d.fireConfigurationChanged("latency-replay", body)
+		mr.Message = "Latency replay updated."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+func (d *Hoverfly) GetFaultsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b := d.Faults.Json()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+func (d *Hoverfly) DeleteAllFaultsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.Faults = &models.FaultList{}
+	d.fireConfigurationChanged("faults", nil)
+
+	var response messageResponse
+	response.Message = "Faults deleted successfuly"
+	w.WriteHeader(200)
+
+	b, err := response.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+	return
+}
+
+func (d *Hoverfly) UpdateFaultsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var td models.FaultJson
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read response body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+
+		b, err := mr.Encode()
+		if err != nil {
+			// failed to read response body
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Could not encode response body!")
+			http.Error(w, "Failed to encode response", 500)
+			return
+		}
+		w.Write(b)
+		return
+	}
+
+	err = json.Unmarshal(body, &td)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if td.Data == nil {
+		log.Error("No fault data in the request body!")
+		mr.Message = fmt.Sprintf("Failed to get data from the request body.")
+		w.WriteHeader(422)
+	} else {
+		err = models.ValidateFaultJson(td)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Error validating faults config supplied")
+			mr.Message = fmt.Sprintf("Failed to validate faults config. Error: %s", err.Error())
+			w.WriteHeader(422)
+		} else {
+			d.UpdateFaults(*td.Data)
+			d.fireConfigurationChanged("faults", body)
+			mr.Message = "Faults updated."
+			w.WriteHeader(201)
+		}
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+	return
+
+}
+
+// GetChaosHandler - returns rules deciding which responses are replaced with error responses
+func (d *Hoverfly) GetChaosHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(d.GetChaos().ConvertToChaosView())
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// UpdateChaosHandler - sets new chaos rules, random numbers are generated from given seed
+func (d *Hoverfly) UpdateChaosHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var cv views.ChaosView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &cv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if chaos, err := models.NewChaosFromView(cv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Error validating chaos rules supplied")
+		mr.Message = fmt.Sprintf("Failed to validate chaos rules. Error: %s", err.Error())
+		w.WriteHeader(422)
+	} else {
+		d.SetChaos(chaos)
+		d.fireConfigurationChanged("chaos", body)
+		mr.Message = "Chaos rules updated."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// DeleteChaosHandler - removes all chaos rules
+func (d *Hoverfly) DeleteChaosHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.SetChaos(nil)
+	d.fireConfigurationChanged("chaos", nil)
+
+	var mr messageResponse
+	mr.Message = "Chaos rules deleted successfuly"
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(200)
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetRateLimitsHandler - returns rate limits applied to requests
+func (d *Hoverfly) GetRateLimitsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(d.GetRateLimiter().ConvertToRateLimitsView())
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// UpdateRateLimitsHandler - sets new rate limits, all buckets start full
+func (d *Hoverfly) UpdateRateLimitsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var rv views.RateLimitsView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &rv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if rateLimiter, err := models.NewRateLimiterFromView(rv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Error validating rate limits supplied")
+		mr.Message = fmt.Sprintf("Failed to validate rate limits. Error: %s", err.Error())
+		w.WriteHeader(422)
+	} else {
+		d.SetRateLimiter(rateLimiter)
+		d.fireConfigurationChanged("rate-limits", body)
+		mr.Message = "Rate limits updated."
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// DeleteRateLimitsHandler - removes all rate limits
+func (d *Hoverfly) DeleteRateLimitsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.SetRateLimiter(nil)
+	d.fireConfigurationChanged("rate-limits", nil)
+
+	var mr messageResponse
+	mr.Message = "Rate limits deleted successfuly"
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(200)
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetRateLimitStateHandler - returns remaining requests of every rate limit bucket that was used
+func (d *Hoverfly) GetRateLimitStateHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(d.GetRateLimiter().ConvertToRateLimitStateView(time.Now()))
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// DeleteRateLimitStateHandler - fills all rate limit buckets, rate limits are kept
+func (d *Hoverfly) DeleteRateLimitStateHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.GetRateLimiter().Reset()
+
+	var mr messageResponse
+	mr.Message = "Rate limit state reset successfuly"
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(200)
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetJournalHandler - returns journaled requests selected by query parameters, oldest first
+func (d *Hoverfly) GetJournalHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	filter, err := NewJournalFilterFromQuery(req.URL.Query())
+	if err != nil {
+		var mr messageResponse
+		mr.Message = fmt.Sprintf("Failed to filter journal. Error: %s", err.Error())
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(400)
+
+		b, _ := mr.Encode()
+		w.Write(b)
+		return
+	}
+
+	b, err := json.Marshal(d.Journal.ConvertToJournalView(filter))
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// DeleteJournalHandler - removes all journaled requests
+func (d *Hoverfly) DeleteJournalHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var mr messageResponse
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	if err := d.ClearJournal(); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to clear journal")
+		mr.Message = fmt.Sprintf("Failed to clear journal. Error: %s", err.Error())
+		w.WriteHeader(500)
+	} else {
+		mr.Message = "Journal cleared successfuly"
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// VerifyHandler - returns count and details of journaled requests matching request template from the body
+func (d *Hoverfly) VerifyHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var template matching.RequestTemplate
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not read request body!")
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+	} else if err = json.Unmarshal(body, &template); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to unmarshal request body!")
+		mr.Message = fmt.Sprintf("Failed to decode request template. Error: %s", err.Error())
+	} else {
+		b, err := json.Marshal(d.Verify(template))
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(b)
+		return
+	}
+
+	w.WriteHeader(400)
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// GetCoverageHandler - returns records and templates that were never matched and the most matched ones,
+// 'top' query parameter limits how many of the most matched ones are returned
+func (d *Hoverfly) GetCoverageHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	top := DefaultCoverageTop
+	if value := req.URL.Query().Get("top"); value != "" {
+		var err error
+		if top, err = strconv.Atoi(value); err != nil || top < 0 {
+			var mr messageResponse
+			mr.Message = fmt.Sprintf("Failed to get coverage, 'top' must be a non negative number, got '%s'", value)
+
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.WriteHeader(400)
+
+			b, _ := mr.Encode()
+			w.Write(b)
+			return
+		}
+	}
+
+	coverage, err := d.GetCoverage(top)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get coverage")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b, err := json.Marshal(coverage)
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// DeleteCoverageHandler - forgets how many times records and templates were matched
+func (d *Hoverfly) DeleteCoverageHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.ResetCoverage()
+
+	var mr messageResponse
+	mr.Message = "Coverage reset successfuly"
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(200)
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// PrometheusMetricsHandler - returns request counters, latency histograms, applied delays and record counts
+// in Prometheus text format
+func (d *Hoverfly) PrometheusMetricsHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var buf bytes.Buffer
+	if err := d.WritePrometheusMetrics(&buf); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to collect metrics")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+// GetHooksHandler - returns hooks registered with flags or through the admin API
+func (d *Hoverfly) GetHooksHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	b, err := json.Marshal(d.GetConfiguredHooks())
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// AddHookHandler - registers webhook, returns the hook with its ID. File hooks are only registered with flags.
+func (d *Hoverfly) AddHookHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var hv views.HookView
+	var mr messageResponse
+
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		mr.Message = fmt.Sprintf("Failed to read request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if err = json.Unmarshal(body, &hv); err != nil {
+		mr.Message = fmt.Sprintf("Failed to decode request body. Error: %s", err.Error())
+		w.WriteHeader(400)
+	} else if hv.Type == FileHookType {
+		mr.Message = fmt.Sprintf("Failed to register hook. Error: %s", ErrFileHookNotAllowed.Error())
+		w.WriteHeader(http.StatusForbidden)
+	} else if hv, err = d.AddConfiguredHook(hv); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Error validating hook supplied")
+		mr.Message = fmt.Sprintf("Failed to register hook. Error: %s", err.Error())
+		w.WriteHeader(422)
+	} else {
+		b, err := json.Marshal(hv)
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		d.fireConfigurationChanged("hooks", b)
+		w.Write(b)
+		return
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// DeleteHookHandler - removes hook with given ID
+func (d *Hoverfly) DeleteHookHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	var mr messageResponse
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	if err := d.RemoveConfiguredHook(bone.GetValue(req, "id")); err != nil {
+		mr.Message = err.Error()
+		w.WriteHeader(http.StatusNotFound)
+	} else {
+		d.fireConfigurationChanged("hooks", nil)
+		mr.Message = "Hook deleted successfuly"
+		w.WriteHeader(200)
+	}
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
+
+// DeleteHooksHandler - removes all hooks registered with flags or through the admin API
+func (d *Hoverfly) DeleteHooksHandler(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	d.RemoveConfiguredHooks()
+	d.fireConfigurationChanged("hooks", nil)
+
+	var mr messageResponse
+	mr.Message = "Hooks deleted successfuly"
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(200)
+
+	b, err := mr.Encode()
+	if err != nil {
+		// failed to read response body
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Could not encode response body!")
+		http.Error(w, "Failed to encode response", 500)
+		return
+	}
+	w.Write(b)
+}
@@ -380,6 +380,47 @@ func TestSetSynthesizeState(t *testing.T) {
 	Expect(dbClient.Cfg.GetMode()).To(Equal(SynthesizeMode))
 }
 
+func TestSetStateInWebserverModeWithoutUpstream(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.SetMode(SimulateMode)
+
+	req, err := http.NewRequest("POST", "/api/state", ioutil.NopCloser(bytes.NewBufferString(`{"mode":"capture"}`)))
+	Expect(err).To(BeNil())
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusForbidden))
+	Expect(dbClient.Cfg.GetMode()).To(Equal(SimulateMode))
+}
+
+func TestSetStateInWebserverModeWithUpstream(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.WebserverUpstream = "http://api.service.com"
+	dbClient.Cfg.SetMode(SimulateMode)
+
+	req, err := http.NewRequest("POST", "/api/state", ioutil.NopCloser(bytes.NewBufferString(`{"mode":"spy"}`)))
+	Expect(err).To(BeNil())
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.Cfg.GetMode()).To(Equal(SpyMode))
+}
+
 func TestSetRandomState(t *testing.T) {
 	RegisterTestingT(t)
 
@@ -26,13 +26,13 @@ func (c *InMemoryCache) Set(key, value []byte) (err error) {
 
 func (c *InMemoryCache) Get(key []byte) (value []byte, err error) {
 	c.RLock()
+	defer c.RUnlock()
 	bytes := c.elements[string(key)]
 	value = make([]byte, len(bytes), len(bytes))
 	copy(value, bytes)
 	if (len(value) == 0) {
 		return nil, fmt.Errorf("key %q not found \n", key)
 	}
-	c.RUnlock()
 	return value, nil
 }
 
@@ -24,6 +24,22 @@ func TestCacheGetIsEmptyByDefault(t *testing.T) {
 	Expect(actualValue).To(HaveLen(0))
 }
 
+func TestSetAfterGettingMissingKey(t *testing.T) {
+	RegisterTestingT(t)
+
+	cache := NewInMemoryCache()
+
+	_, err := cache.Get(expectedKey1)
+	Expect(err).ToNot(BeNil())
+
+	err = cache.Set(expectedKey1, expectedValue1)
+	Expect(err).To(BeNil())
+
+	actualValue, err := cache.Get(expectedKey1)
+	Expect(err).To(BeNil())
+	Expect(actualValue).To(Equal(expectedValue1))
+}
+
 func TestCacheGetAllEntriesIsEmptyByDefault(t *testing.T) {
 	RegisterTestingT(t)
 
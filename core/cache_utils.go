@@ -6,7 +6,8 @@ import (
 	"github.com/SpectoLabs/hoverfly/core/models"
 )
 
-func rebuildHashes(db cache.Cache, webserver bool) {
+// rebuildHashes - re-keys stored payloads whose fingerprint changed, hits of re-keyed records move with them
+func rebuildHashes(db cache.Cache, webserver bool, policy *models.FingerprintPolicy, coverage *models.Coverage) {
 	log.Info("Checking if keys in cache need rehashing")
 
 	entries, err := db.GetAllEntries()
@@ -23,16 +24,12 @@ func rebuildHashes(db cache.Cache, webserver bool) {
 				"key":   key,
 			}).Error("Failed to decode payload")
 		}
-		var newKey string
-		if webserver {
-			newKey = payload.IdWithoutHost()
-		} else {
-			newKey = payload.Id()
-		}
+		newKey := payload.Request.Fingerprint(policy, !webserver)
 
 		if key != newKey {
 			db.Delete([]byte(key))
 			db.Set([]byte(newKey), bytes)
+			coverage.MoveRecord(key, newKey)
 		}
 	}
 }
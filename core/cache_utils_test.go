@@ -27,7 +27,7 @@ func Test_rebuildHashes_whenDataIsHashedForAProxy_andStillAProxy_keysAreNotChang
 
 	db.Set([]byte(testPayload.Id()), testPayloadBytes)
 
-	rebuildHashes(db, webserver)
+	rebuildHashes(db, webserver, nil, nil)
 
 	result, err := db.Get([]byte(testPayload.Id()))
 
@@ -55,7 +55,7 @@ func Test_rebuildHashes_whenDataIsHashedForAWebserver_andStillAWebserver_keysAre
 
 	db.Set([]byte(testPayload.IdWithoutHost()), testPayloadBytes)
 
-	rebuildHashes(db, webserver)
+	rebuildHashes(db, webserver, nil, nil)
 
 	result, err := db.Get([]byte(testPayload.IdWithoutHost()))
 
@@ -83,7 +83,7 @@ func Test_rebuildHashes_whenDataIsHashedForAProxy_andIsNowAWebserver_keysAreChan
 
 	db.Set([]byte(testPayload.Id()), testPayloadBytes)
 
-	rebuildHashes(db, webserver)
+	rebuildHashes(db, webserver, nil, nil)
 
 	result, err := db.Get([]byte(testPayload.IdWithoutHost()))
 
@@ -0,0 +1,28 @@
+package hoverfly
+
+import (
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+)
+
+// GetCaptureFilters - returns filters deciding which exchanges are captured, nil when everything is captured
+func (hf *Hoverfly) GetCaptureFilters() *models.CaptureFilters {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	return hf.CaptureFilters
+}
+
+// SetCaptureFilters - sets filters deciding which exchanges are captured, already captured payloads are kept
+func (hf *Hoverfly) SetCaptureFilters(filters *models.CaptureFilters) {
+	if filters.IsEmpty() {
+		filters = nil
+	}
+
+	hf.mu.Lock()
+	hf.CaptureFilters = filters
+	hf.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"filters": filters.ConvertToCaptureFiltersView(),
+	}).Info("capture filters updated")
+}
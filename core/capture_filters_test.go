@@ -0,0 +1,94 @@
+package hoverfly
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func TestCaptureFiltersDecideWhichRequestsAreSaved(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	health, err := models.ParseCaptureFilter("path=^/health")
+	Expect(err).To(BeNil())
+	dbClient.SetCaptureFilters(&models.CaptureFilters{Exclude: []models.CaptureFilter{*health}})
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	for _, path := range []string{"/health", "/items"} {
+		r, err := http.NewRequest("GET", "http://somehost.com"+path, nil)
+		Expect(err).To(BeNil())
+
+		_, resp := dbClient.processRequest(r)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	}
+
+	values, err := dbClient.RequestCache.GetAllValues()
+	Expect(err).To(BeNil())
+	Expect(values).To(HaveLen(1))
+
+	payload, err := models.NewPayloadFromBytes(values[0])
+	Expect(err).To(BeNil())
+	Expect(payload.Request.Path).To(Equal("/items"))
+}
+
+func TestCaptureFiltersMatchResponseStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(503, `unavailable`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	ok, err := models.ParseCaptureFilter("status=200-299")
+	Expect(err).To(BeNil())
+	dbClient.SetCaptureFilters(&models.CaptureFilters{Include: []models.CaptureFilter{*ok}})
+
+	r, err := http.NewRequest("GET", "http://somehost.com/items", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+
+	count, err := dbClient.RequestCache.RecordsCount()
+	Expect(err).To(BeNil())
+	Expect(count).To(Equal(0))
+}
+
+func TestUpdateCaptureFiltersHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/capture-filters", bytes.NewBufferString(`{"exclude": [{"path": "(unclosed"}]}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+	Expect(dbClient.GetCaptureFilters()).To(BeNil())
+
+	req, err = http.NewRequest("PUT", "/api/capture-filters", bytes.NewBufferString(`{"include": [{"methods": ["GET"], "contentTypes": ["application/json"]}]}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetCaptureFilters().Include).To(HaveLen(1))
+	Expect(dbClient.GetCaptureFilters().Include[0].Methods).To(Equal([]string{"GET"}))
+
+	req, err = http.NewRequest("DELETE", "/api/capture-filters", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetCaptureFilters()).To(BeNil())
+}
@@ -0,0 +1,128 @@
+package hoverfly
+
+import (
+	"sort"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/cache"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// GetCapturePolicy - returns policy used when a request that was already captured is captured again
+func (hf *Hoverfly) GetCapturePolicy() string {
+	if hf.RequestMatcher.CapturePolicy == "" {
+		return models.CapturePolicyOverwrite
+	}
+	return hf.RequestMatcher.CapturePolicy
+}
+
+// SetHistoryCache - sets cache keeping payloads captured with append capture policy, capture history
+// is not available until it's set
+func (hf *Hoverfly) SetHistoryCache(historyCache cache.Cache) {
+	hf.RequestMatcher.HistoryCache = historyCache
+}
+
+// SetCapturePolicy - sets policy used when a request that was already captured is captured again
+func (hf *Hoverfly) SetCapturePolicy(policy string) error {
+	if err := models.ValidateCapturePolicy(policy); err != nil {
+		return err
+	}
+
+	hf.RequestMatcher.CapturePolicy = policy
+
+	log.WithFields(log.Fields{
+		"policy": policy,
+	}).Info("capture policy updated")
+
+	return nil
+}
+
+// GetCaptureHistoryList - returns every request with capture history
+func (hf *Hoverfly) GetCaptureHistoryList() (*views.CaptureHistoryListView, error) {
+	list := &views.CaptureHistoryListView{Data: []views.CaptureHistorySummaryView{}}
+	if hf.RequestMatcher.HistoryCache == nil {
+		return list, nil
+	}
+
+	entries, err := hf.RequestMatcher.HistoryCache.GetAllEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		history, err := models.NewCaptureHistoryFromBytes(entries[key])
+		if err != nil || len(history) == 0 {
+			continue
+		}
+
+		request := history[len(history)-1].Payload.Request
+		list.Data = append(list.Data, views.CaptureHistorySummaryView{
+			Key:         key,
+			Method:      request.Method,
+			Destination: request.Destination,
+			Path:        request.Path,
+			Query:       request.Query,
+			Entries:     len(history),
+		})
+	}
+
+	return list, nil
+}
+
+// GetCaptureHistory - returns history of given key, active entry is marked
+func (hf *Hoverfly) GetCaptureHistory(key string) (*views.CaptureHistoryView, error) {
+	history, err := hf.RequestMatcher.GetCaptureHistory(key)
+	if err != nil {
+		return nil, err
+	}
+
+	active, _ := hf.RequestMatcher.GetActivePayload(key)
+	return history.ConvertToCaptureHistoryView(key, active), nil
+}
+
+// rebuildCaptureHistory - redacts history entries and re-keys histories, called whenever stored
+// payloads are redacted or re-keyed so histories stay attached to their requests
+func rebuildCaptureHistory(hf *Hoverfly) {
+	historyCache := hf.RequestMatcher.HistoryCache
+	if historyCache == nil {
+		return
+	}
+
+	entries, err := historyCache.GetAllEntries()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Unable to read capture history")
+		return
+	}
+
+	for key, bts := range entries {
+		history, err := models.NewCaptureHistoryFromBytes(bts)
+		if err != nil || len(history) == 0 {
+			log.WithFields(log.Fields{
+				"key": key,
+			}).Error("Failed to decode capture history")
+			continue
+		}
+
+		for i := range history {
+			hf.RequestMatcher.Redaction.Redact(&history[i].Payload)
+		}
+
+		newKey := history[0].Payload.Request.Fingerprint(hf.RequestMatcher.FingerprintPolicy, !*hf.RequestMatcher.Webserver)
+		historyCache.Delete([]byte(key))
+		if err := hf.RequestMatcher.SaveCaptureHistory(newKey, history); err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"key":   newKey,
+			}).Error("Failed to save capture history")
+		}
+	}
+}
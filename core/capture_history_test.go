@@ -0,0 +1,245 @@
+package hoverfly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+// captureThreeTimes - captures the same request three times, upstream responds with a different body every time
+func captureThreeTimes(dbClient *Hoverfly) {
+	calls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, "response %d", calls)
+	}))
+	defer upstream.Close()
+
+	dbClient.HTTP = &http.Client{Transport: &http.Transport{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			return url.Parse(upstream.URL)
+		},
+	}}
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	for i := 0; i < 3; i++ {
+		r, err := http.NewRequest("GET", "http://somehost.com/items", nil)
+		Expect(err).To(BeNil())
+		dbClient.processRequest(r)
+	}
+}
+
+func simulatedBody(dbClient *Hoverfly) string {
+	r, err := http.NewRequest("GET", "http://somehost.com/items", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(r)
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).To(BeNil())
+	return string(body)
+}
+
+func TestCapturePolicyOverwriteKeepsLastResponse(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	Expect(dbClient.GetCapturePolicy()).To(Equal(models.CapturePolicyOverwrite))
+	captureThreeTimes(dbClient)
+
+	Expect(simulatedBody(dbClient)).To(Equal("response 3"))
+
+	list, err := dbClient.GetCaptureHistoryList()
+	Expect(err).To(BeNil())
+	Expect(list.Data).To(BeEmpty())
+}
+
+func TestCapturePolicyKeepFirstKeepsFirstResponse(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	Expect(dbClient.SetCapturePolicy(models.CapturePolicyKeepFirst)).To(BeNil())
+	captureThreeTimes(dbClient)
+
+	Expect(simulatedBody(dbClient)).To(Equal("response 1"))
+}
+
+func TestCapturePolicyAppendKeepsHistoryAndOlderResponseCanBePromoted(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	Expect(dbClient.SetCapturePolicy(models.CapturePolicyAppend)).To(BeNil())
+	captureThreeTimes(dbClient)
+
+	Expect(simulatedBody(dbClient)).To(Equal("response 3"))
+
+	list, err := dbClient.GetCaptureHistoryList()
+	Expect(err).To(BeNil())
+	Expect(list.Data).To(HaveLen(1))
+	Expect(list.Data[0].Path).To(Equal("/items"))
+	Expect(list.Data[0].Entries).To(Equal(3))
+
+	key := list.Data[0].Key
+	history, err := dbClient.GetCaptureHistory(key)
+	Expect(err).To(BeNil())
+	Expect(history.Entries).To(HaveLen(3))
+	Expect(history.Entries[0].Payload.Response.Body).To(Equal("response 1"))
+	Expect(history.Entries[0].Active).To(BeFalse())
+	Expect(history.Entries[2].Active).To(BeTrue())
+	Expect(history.Entries[2].CapturedAt).ToNot(BeEmpty())
+
+	err = dbClient.RequestMatcher.PromoteCapture(key, 0)
+	Expect(err).To(BeNil())
+	Expect(simulatedBody(dbClient)).To(Equal("response 1"))
+
+	err = dbClient.RequestMatcher.PromoteCapture(key, 3)
+	Expect(err).ToNot(BeNil())
+}
+
+func TestCapturePolicyAppendKeepsCaptureTimeOfPreviouslyActivePayload(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	capturedAt := time.Date(2016, 5, 10, 12, 30, 0, 0, time.UTC)
+	payload := &models.Payload{
+		Request:  models.RequestDetails{Path: "/items", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: "first"},
+		Metadata: &models.CaptureMetadata{CapturedAt: capturedAt},
+	}
+	Expect(dbClient.RequestMatcher.SavePayload(payload)).To(BeNil())
+
+	Expect(dbClient.SetCapturePolicy(models.CapturePolicyAppend)).To(BeNil())
+	_, err := dbClient.RequestMatcher.CapturePayload(&models.Payload{
+		Request:  payload.Request,
+		Response: models.ResponseDetails{Status: 200, Body: "second"},
+		Metadata: &models.CaptureMetadata{CapturedAt: capturedAt.Add(time.Hour)},
+	})
+	Expect(err).To(BeNil())
+
+	history, err := dbClient.RequestMatcher.GetCaptureHistory(payload.Id())
+	Expect(err).To(BeNil())
+	Expect(history).To(HaveLen(2))
+	Expect(history[0].CapturedAt.Equal(capturedAt)).To(BeTrue())
+}
+
+func TestCapturePolicyAppendKeepsLimitedHistory(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	Expect(dbClient.SetCapturePolicy(models.CapturePolicyAppend)).To(BeNil())
+	for i := 0; i <= models.MaxCaptureHistorySize; i++ {
+		_, err := dbClient.RequestMatcher.CapturePayload(&models.Payload{
+			Request:  models.RequestDetails{Path: "/items", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+			Response: models.ResponseDetails{Status: 200, Body: fmt.Sprintf("response %d", i)},
+		})
+		Expect(err).To(BeNil())
+	}
+
+	list, err := dbClient.GetCaptureHistoryList()
+	Expect(err).To(BeNil())
+	Expect(list.Data).To(HaveLen(1))
+	Expect(list.Data[0].Entries).To(Equal(models.MaxCaptureHistorySize))
+
+	history, err := dbClient.RequestMatcher.GetCaptureHistory(list.Data[0].Key)
+	Expect(err).To(BeNil())
+	Expect(history[0].Payload.Response.Body).To(Equal("response 1"))
+}
+
+func TestSetCapturePolicy_ReturnsErrorForUnknownPolicy(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+
+	Expect(dbClient.SetCapturePolicy("replace")).ToNot(BeNil())
+	Expect(dbClient.GetCapturePolicy()).To(Equal(models.CapturePolicyOverwrite))
+}
+
+func TestCaptureHistoryHandlers(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/capture-policy", bytes.NewBufferString(`{"policy": "sometimes"}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+
+	req, err = http.NewRequest("PUT", "/api/capture-policy", bytes.NewBufferString(`{"policy": "append"}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	captureThreeTimes(dbClient)
+
+	req, err = http.NewRequest("GET", "/api/history", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var list views.CaptureHistoryListView
+	Expect(json.Unmarshal(rec.Body.Bytes(), &list)).To(BeNil())
+	Expect(list.Data).To(HaveLen(1))
+	key := list.Data[0].Key
+
+	req, err = http.NewRequest("GET", "/api/history/"+key, nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var history views.CaptureHistoryView
+	Expect(json.Unmarshal(rec.Body.Bytes(), &history)).To(BeNil())
+	Expect(history.Key).To(Equal(key))
+	Expect(history.Entries).To(HaveLen(3))
+
+	req, err = http.NewRequest("POST", "/api/history/"+key+"/promote", bytes.NewBufferString(`{"index": 7}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+
+	req, err = http.NewRequest("POST", "/api/history/"+key+"/promote", bytes.NewBufferString(`{"index": 1}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(simulatedBody(dbClient)).To(Equal("response 2"))
+
+	req, err = http.NewRequest("GET", "/api/history/unknown", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusNotFound))
+}
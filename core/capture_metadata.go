@@ -0,0 +1,38 @@
+package hoverfly
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/pborman/uuid"
+)
+
+// GetCaptureTags - returns tags that are added to every captured payload
+func (hf *Hoverfly) GetCaptureTags() []string {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	return append([]string{}, hf.captureTags...)
+}
+
+// SetCaptureTags - sets tags that are added to every captured payload, i.e. name of the current test run
+func (hf *Hoverfly) SetCaptureTags(tags []string) {
+	hf.mu.Lock()
+	hf.captureTags = append([]string{}, tags...)
+	hf.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"tags": tags,
+	}).Info("capture tags updated")
+}
+
+// newCaptureMetadata - starts metadata of request that is about to be captured
+func (hf *Hoverfly) newCaptureMetadata(remoteAddr string) *models.CaptureMetadata {
+	return &models.CaptureMetadata{
+		ID:         uuid.New(),
+		CapturedAt: time.Now(),
+		RemoteAddr: remoteAddr,
+		Mode:       hf.Cfg.GetMode(),
+		Tags:       hf.GetCaptureTags(),
+	}
+}
@@ -0,0 +1,85 @@
+package hoverfly
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestCapturedPayloadsHaveMetadata(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.SetCaptureTags([]string{"run-1"})
+	dbClient.Cfg.SetMode(CaptureMode)
+
+	for _, path := range []string{"/first", "/second"} {
+		r, err := http.NewRequest("GET", "http://somehost.com"+path, nil)
+		Expect(err).To(BeNil())
+		r.RemoteAddr = "10.0.0.1:51000"
+
+		before := time.Now()
+		dbClient.processRequest(r)
+
+		values, err := dbClient.RequestCache.GetAllValues()
+		Expect(err).To(BeNil())
+
+		var payload *models.Payload
+		for _, v := range values {
+			p, err := models.NewPayloadFromBytes(v)
+			Expect(err).To(BeNil())
+			if p.Request.Path == path {
+				payload = p
+			}
+		}
+
+		Expect(payload).ToNot(BeNil())
+		Expect(payload.Metadata).ToNot(BeNil())
+		Expect(payload.Metadata.ID).ToNot(BeEmpty())
+		Expect(payload.Metadata.CapturedAt.Before(before)).To(BeFalse())
+		Expect(payload.Metadata.RemoteAddr).To(Equal("10.0.0.1:51000"))
+		Expect(payload.Metadata.Mode).To(Equal(CaptureMode))
+		Expect(payload.Metadata.Tags).To(Equal([]string{"run-1"}))
+		Expect(payload.Metadata.TLS).To(BeNil())
+	}
+}
+
+func TestAllRecordsHandler_FiltersByTag(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	for _, tag := range []string{"run-1", "run-2"} {
+		dbClient.SetCaptureTags([]string{tag})
+
+		r, err := http.NewRequest("GET", "http://somehost.com/"+tag, nil)
+		Expect(err).To(BeNil())
+		dbClient.processRequest(r)
+	}
+
+	req, err := http.NewRequest("GET", "/api/records?tag=run-2", nil)
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var records views.PayloadViewData
+	Expect(json.Unmarshal(rec.Body.Bytes(), &records)).To(BeNil())
+	Expect(records.Data).To(HaveLen(1))
+	Expect(records.Data[0].Request.Path).To(Equal("/run-2"))
+	Expect(records.Data[0].Metadata.Tags).To(Equal([]string{"run-2"}))
+	Expect(records.Data[0].Metadata.Mode).To(Equal(CaptureMode))
+}
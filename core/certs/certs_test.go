@@ -1,6 +1,7 @@
 package certs
 
 import (
+	"crypto/tls"
 	"crypto/x509"
 	"os"
 	"reflect"
@@ -107,3 +108,41 @@ func TestGenerateAndSave(t *testing.T) {
 	}
 
 }
+
+func TestNewHostCertificate(t *testing.T) {
+	caCert, caKey, err := NewCertificatePair("certy.com", "cert authority", 365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to generate certificate and key pair, got error: %s", err.Error())
+	}
+	ca := tls.Certificate{Certificate: [][]byte{caCert.Raw}, PrivateKey: caKey}
+
+	tlsc, err := NewHostCertificate(ca, []string{"api.example.com", "127.0.0.1"}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to get host certificate, got error: %s", err.Error())
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+
+	for _, host := range []string{"api.example.com", "127.0.0.1"} {
+		if _, err := tlsc.Leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Errorf("tlsc.Leaf.Verify(%q): got %v, want no error", host, err)
+		}
+	}
+
+	if err := tlsc.Leaf.VerifyHostname("other.example.com"); err == nil {
+		t.Error("tlsc.Leaf.VerifyHostname(\"other.example.com\"): got no error, want error")
+	}
+}
+
+func TestNewHostCertificateWithoutHosts(t *testing.T) {
+	caCert, caKey, err := NewCertificatePair("certy.com", "cert authority", 365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to generate certificate and key pair, got error: %s", err.Error())
+	}
+
+	_, err = NewHostCertificate(tls.Certificate{Certificate: [][]byte{caCert.Raw}, PrivateKey: caKey}, nil, time.Hour)
+	if err == nil {
+		t.Error("NewHostCertificate: got no error, want error when no hosts are given")
+	}
+}
@@ -0,0 +1,56 @@
+package hoverfly
+
+import (
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+)
+
+// GetChaos - returns rules deciding which responses are replaced with error responses, nil when none are
+func (hf *Hoverfly) GetChaos() *models.Chaos {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	return hf.chaos
+}
+
+// SetChaos - sets rules deciding which responses are replaced with error responses
+func (hf *Hoverfly) SetChaos(chaos *models.Chaos) {
+	if chaos.IsEmpty() {
+		chaos = nil
+	}
+
+	hf.mu.Lock()
+	hf.chaos = chaos
+	hf.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"chaos": chaos.ConvertToChaosView(),
+	}).Info("chaos rules updated")
+}
+
+// applyChaos - replaces response with error response when chaos rules pick the request. Request is
+// processed as usual first, so exchanges are still captured and modified.
+func (hf *Hoverfly) applyChaos(req *http.Request, resp *http.Response) *http.Response {
+	response := hf.GetChaos().Pick(req.Host, req.URL.Path)
+	if response == nil {
+		return resp
+	}
+
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+
+	mode := hf.Cfg.GetMode()
+	hf.Counter.CountFault(mode)
+
+	log.WithFields(log.Fields{
+		"chaos":       true,
+		"status":      response.Status,
+		"mode":        mode,
+		"path":        req.URL.Path,
+		"destination": req.Host,
+	}).Warn("chaos error response injected")
+
+	return NewConstructor(req, models.Payload{Response: *response}).ReconstructResponse()
+}
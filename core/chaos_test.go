@@ -0,0 +1,123 @@
+package hoverfly
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/metrics"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func newTestChaos(destination, path string, percentage float64, response models.ResponseDetails) *models.Chaos {
+	rule, err := models.NewChaosRule(destination, path, percentage, []models.ResponseDetails{response})
+	Expect(err).To(BeNil())
+	return models.NewChaos(1, []models.ChaosRule{*rule})
+}
+
+func TestProcessRequest_ChaosReplacesSimulatedResponse(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	r, err := http.NewRequest("GET", "http://somehost.com/users", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	dbClient.processRequest(r)
+
+	dbClient.SetChaos(newTestChaos("somehost.com", "^/users", 100, models.ResponseDetails{
+		Status:  http.StatusServiceUnavailable,
+		Body:    "try later",
+		Headers: map[string][]string{"Retry-After": {"30"}},
+	}))
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(r)
+
+	Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+	Expect(resp.Header.Get("Retry-After")).To(Equal("30"))
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("try later"))
+
+	Expect(dbClient.Counter.Flush().Counters[metrics.FaultsCounterPrefix+SimulateMode]).To(Equal(int64(1)))
+}
+
+func TestProcessRequest_ChaosInCaptureModeStillCaptures(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.SetChaos(newTestChaos("", "", 100, models.ResponseDetails{Status: http.StatusTooManyRequests}))
+
+	r, err := http.NewRequest("GET", "http://somehost.com/users", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+
+	count, err := dbClient.RequestCache.RecordsCount()
+	Expect(err).To(BeNil())
+	Expect(count).To(Equal(1))
+	Expect(dbClient.Counter.Flush().Counters[metrics.FaultsCounterPrefix+CaptureMode]).To(Equal(int64(1)))
+}
+
+func TestProcessRequest_ChaosKeepsResponsesOfOtherPaths(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.SetChaos(newTestChaos("", "^/orders", 100, models.ResponseDetails{Status: http.StatusTooManyRequests}))
+
+	r, err := http.NewRequest("GET", "http://somehost.com/users", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SpyMode)
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+}
+
+func TestUpdateChaosHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/chaos", bytes.NewBufferString(`{"rules": [{"percentage": 10, "responses": []}]}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+
+	req, err = http.NewRequest("PUT", "/api/chaos", bytes.NewBufferString(`{"seed": 5, "rules": [{"path": "^/users", "percentage": 10, "responses": [{"status": 503}]}]}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetChaos().Seed).To(Equal(int64(5)))
+
+	req, err = http.NewRequest("GET", "/api/chaos", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Body.String()).To(ContainSubstring(`"seed":5`))
+
+	req, err = http.NewRequest("DELETE", "/api/chaos", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetChaos()).To(BeNil())
+}
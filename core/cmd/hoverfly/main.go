@@ -33,6 +33,7 @@ import (
 	"github.com/SpectoLabs/hoverfly/core/authentication/backends"
 	"github.com/SpectoLabs/hoverfly/core/cache"
 	hvc "github.com/SpectoLabs/hoverfly/core/certs"
+	"github.com/SpectoLabs/hoverfly/core/models"
 	"github.com/rusenask/goproxy"
 )
 
@@ -49,6 +50,18 @@ func (i *arrayFlags) Set(value string) error {
 
 var importFlags arrayFlags
 var destinationFlags arrayFlags
+var clientCertFlags arrayFlags
+var webserverTLSHostFlags arrayFlags
+var virtualHostFlags arrayFlags
+var redactHeaderFlags arrayFlags
+var redactQueryFlags arrayFlags
+var redactBodyPathFlags arrayFlags
+var redactBodyPatternFlags arrayFlags
+var captureIncludeFlags arrayFlags
+var captureExcludeFlags arrayFlags
+var captureTagFlags arrayFlags
+var webhookFlags arrayFlags
+var hookFileFlags arrayFlags
 
 const boltBackend = "boltdb"
 const inmemoryBackend = "memory"
@@ -61,6 +74,7 @@ var (
 	capture     = flag.Bool("capture", false, "start Hoverfly in capture mode - transparently intercepts and saves requests/response")
 	synthesize  = flag.Bool("synthesize", false, "start Hoverfly in synthesize mode (middleware is required)")
 	modify      = flag.Bool("modify", false, "start Hoverfly in modify mode - applies middleware (required) to both outgoing and incomming HTTP traffic")
+	spy         = flag.Bool("spy", false, "start Hoverfly in spy mode - simulates requests that have a stored response and forwards the rest")
 	middleware  = flag.String("middleware", "", "should proxy use middleware")
 	proxyPort   = flag.String("pp", "", "proxy port - run proxy on another port (i.e. '-pp 9999' to run proxy on port 9999)")
 	adminPort   = flag.String("ap", "", "admin port - run admin interface on another port (i.e. '-ap 1234' to run admin UI on port 1234)")
@@ -69,6 +83,22 @@ var (
 	destination = flag.String("destination", ".", "destination URI to catch")
 	webserver   = flag.Bool("webserver", false, "start Hoverfly in webserver mode (simulate mode)")
 
+	webserverUpstream = flag.String("webserver-upstream", "", "base URL of the service webserver mode reverse proxies to in capture, modify and spy modes (i.e. '-webserver -webserver-upstream https://api.service.com')")
+	transparentPort   = flag.String("tp", "", "transparent proxy port - accept connections redirected by iptables from clients that are not proxy aware (i.e. '-tp 8501')")
+
+	captureErrors       = flag.Bool("capture-errors", false, "capture mode stores upstream transport errors (DNS failure, connection refused or reset, timeout, TLS error) and simulate mode reproduces them")
+	capturePolicy       = flag.String("capture-policy", models.CapturePolicyOverwrite, "what happens when captured request is captured again: overwrite, keep-first or append (every response is kept in history)")
+	replayLatency       = flag.Bool("replay-latency", false, "simulate mode waits for the upstream latency recorded during capture before responding, response delays take precedence")
+	replayLatencyFactor = flag.Float64("replay-latency-factor", hv.DefaultLatencyFactor, "multiplier applied to replayed latency (i.e. '-replay-latency -replay-latency-factor 0.5' to replay half of recorded latency)")
+	redactPlaceholder   = flag.String("redact-placeholder", models.DefaultRedactionPlaceholder, "value that redacted secrets are replaced with")
+
+	journalSize    = flag.Int("journal-size", models.DefaultJournalSize, "number of proxied requests kept in the journal, oldest requests are dropped first ('-journal-size 0' disables the journal)")
+	journalPersist = flag.Bool("journal-persist", false, "persist the journal in the bolt database, so it survives restarts")
+
+	webserverTLSCert  = flag.String("webserver-tls-cert", "", "certificate used to serve webserver mode over HTTPS")
+	webserverTLSKey   = flag.String("webserver-tls-key", "", "private key of the certificate used to serve webserver mode over HTTPS")
+	webserverClientCA = flag.String("webserver-client-ca", "", "CA bundle (PEM) used to verify client certificates, supply it to require client certificates in webserver mode")
+
 	addNew       = flag.Bool("add", false, "add new user '-add -username hfadmin -password hfpass'")
 	addUser      = flag.String("username", "", "username for new user")
 	addPassword  = flag.String("password", "", "password for new user")
@@ -82,6 +112,7 @@ var (
 	key        = flag.String("key", "", "private key of the CA used to sign MITM certificates")
 
 	tlsVerification = flag.Bool("tls-verification", true, "turn on/off tls verification for outgoing requests (will not try to verify certificates) - defaults to true")
+	upstreamCA      = flag.String("upstream-ca", "", "CA bundle (PEM) used to verify certificates of upstream services instead of system roots")
 
 	databasePath = flag.String("db-path", "", "database location - supply it to provide specific database location (will be created there if it doesn't exist)")
 	database     = flag.String("db", "boltdb", "Persistance storage to use - 'boltdb' or 'memory' which will not write anything to disk")
@@ -153,6 +184,18 @@ func main() {
 	log.SetFormatter(&log.JSONFormatter{})
 	flag.Var(&importFlags, "import", "import from file or from URL (i.e. '-import my_service.json' or '-import http://mypage.com/service_x.json'")
 	flag.Var(&destinationFlags, "dest", "specify which hosts to process (i.e. '-dest fooservice.org -dest barservice.org -dest catservice.org') - other hosts will be ignored will passthrough'")
+	flag.Var(&webserverTLSHostFlags, "webserver-tls-host", "serve webserver mode over HTTPS with a certificate signed by Hoverfly CA for given hostname or IP (i.e. '-webserver-tls-host api.service.com -webserver-tls-host 127.0.0.1')")
+	flag.Var(&virtualHostFlags, "virtual-host", "simulate several services in webserver mode, requests are routed by Host header or by optional port (i.e. '-virtual-host api.service.com -virtual-host auth.service.com,8501')")
+	flag.Var(&redactHeaderFlags, "redact-header", "replace values of given request and response header in captured payloads (i.e. '-redact-header Authorization -redact-header Set-Cookie')")
+	flag.Var(&redactQueryFlags, "redact-query", "replace values of given query parameter in captured payloads (i.e. '-redact-query api_key')")
+	flag.Var(&redactBodyPathFlags, "redact-body-path", "replace value at given JSON path in captured request and response bodies (i.e. '-redact-body-path $.credentials.password')")
+	flag.Var(&redactBodyPatternFlags, "redact-body-pattern", "replace body parts matching given regexp in captured payloads, only capturing groups are replaced when present (i.e. '-redact-body-pattern \"password\":\"([^\"]*)\"')")
+	flag.Var(&captureIncludeFlags, "capture-include", "capture only exchanges matching given rule of method, path regexp, status range and content type (i.e. '-capture-include \"method=GET,POST;path=^/api/;status=200-299;content-type=application/json\"')")
+	flag.Var(&captureExcludeFlags, "capture-exclude", "don't capture exchanges matching given rule, takes precedence over include rules (i.e. '-capture-exclude path=^/health -capture-exclude status=500-599')")
+	flag.Var(&captureTagFlags, "capture-tag", "add tag to every captured payload, tags are exported with payload metadata (i.e. '-capture-tag nightly-run-42')")
+	flag.Var(&clientCertFlags, "client-cert", "client certificate for upstream hosts matching destination regexp (i.e. '-client-cert partner.com,client.pem,client-key.pem')")
+	flag.Var(&webhookFlags, "webhook", "POST hook entries as JSON to given URL, options follow separated by semicolons (i.e. '-webhook \"https://ci.local/notify;actions=requestCaptured,wipeDatabase;retries=5\"')")
+	flag.Var(&hookFileFlags, "hook-file", "append hook entries as JSON lines to given file, options follow separated by semicolons, without max-files the file is truncated when it reaches max-size (i.e. '-hook-file \"events.log;actions=requestCaptured;max-size=10485760;max-files=5\"')")
 	flag.Parse()
 
 	if *version {
@@ -200,6 +243,9 @@ func main() {
 	if *adminPort != "" {
 		cfg.AdminPort = *adminPort
 	}
+	if *transparentPort != "" {
+		cfg.TransparentPort = *transparentPort
+	}
 
 	// development settings
 	cfg.Development = *dev
@@ -217,12 +263,30 @@ func main() {
 		cfg.AuthEnabled = true
 	}
 
+	cfg.CaptureUpstreamErrors = *captureErrors
+
 	// disabling tls verification if flag or env variable is set to 'false' (defaults to true)
 	if !cfg.TLSVerification || !*tlsVerification {
 		cfg.TLSVerification = false
 		log.Info("tls certificate verification is now turned off!")
 	}
 
+	if *upstreamCA != "" {
+		cfg.UpstreamCABundle = *upstreamCA
+	}
+
+	for _, v := range clientCertFlags {
+		parts := strings.Split(v, ",")
+		if len(parts) != 3 {
+			log.Fatalf("client certificate should be given as 'destination,certificate,key', got: %s", v)
+		}
+		cfg.ClientCertificates = append(cfg.ClientCertificates, hv.ClientCertificate{
+			DestinationPattern: parts[0],
+			CertificatePath:    parts[1],
+			KeyPath:            parts[2],
+		})
+	}
+
 	if len(destinationFlags) > 0 {
 		cfg.Destination = strings.Join(destinationFlags[:], "|")
 
@@ -233,8 +297,10 @@ func main() {
 
 	var requestCache cache.Cache
 	var metadataCache cache.Cache
+	var historyCache cache.Cache
 	var tokenCache cache.Cache
 	var userCache cache.Cache
+	var journalCache cache.Cache
 
 	if *databasePath != "" {
 		cfg.DatabasePath = *databasePath
@@ -246,8 +312,12 @@ func main() {
 		defer db.Close()
 		requestCache = cache.NewBoltDBCache(db, []byte("requestsBucket"))
 		metadataCache = cache.NewBoltDBCache(db, []byte("metadataBucket"))
+		historyCache = cache.NewBoltDBCache(db, []byte("historyBucket"))
 		tokenCache = cache.NewBoltDBCache(db, []byte(backends.TokenBucketName))
 		userCache = cache.NewBoltDBCache(db, []byte(backends.UserBucketName))
+		if *journalPersist {
+			journalCache = cache.NewBoltDBCache(db, []byte("journalBucket"))
+		}
 	} else if *database == inmemoryBackend {
 		log.Info("Creating in memory map backend...")
 		log.Warn("Turning off authentication...")
@@ -255,6 +325,7 @@ func main() {
 
 		requestCache = cache.NewInMemoryCache()
 		metadataCache = cache.NewInMemoryCache()
+		historyCache = cache.NewInMemoryCache()
 		tokenCache = cache.NewInMemoryCache()
 		userCache = cache.NewInMemoryCache()
 	} else {
@@ -264,6 +335,83 @@ func main() {
 	authBackend := backends.NewCacheBasedAuthBackend(tokenCache, userCache)
 
 	hoverfly := hv.GetNewHoverfly(cfg, requestCache, metadataCache, authBackend)
+	hoverfly.SetHistoryCache(historyCache)
+	hoverfly.Journal = models.NewJournal(*journalSize, journalCache)
+
+	redactionRules, err := models.NewRedactionRules(redactHeaderFlags, redactQueryFlags, redactBodyPathFlags, redactBodyPatternFlags, *redactPlaceholder)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("invalid redaction rules")
+	}
+	// rules stored with the simulation are kept unless new rules are given
+	if !redactionRules.IsEmpty() {
+		hoverfly.SetRedactionRules(redactionRules)
+	}
+
+	captureFilters := &models.CaptureFilters{}
+	for _, rule := range captureIncludeFlags {
+		filter, err := models.ParseCaptureFilter(rule)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"rule":  rule,
+			}).Fatal("invalid capture include rule")
+		}
+		captureFilters.Include = append(captureFilters.Include, *filter)
+	}
+	for _, rule := range captureExcludeFlags {
+		filter, err := models.ParseCaptureFilter(rule)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"rule":  rule,
+			}).Fatal("invalid capture exclude rule")
+		}
+		captureFilters.Exclude = append(captureFilters.Exclude, *filter)
+	}
+	hoverfly.SetCaptureFilters(captureFilters)
+	hoverfly.SetCaptureTags(captureTagFlags)
+
+	hookFlags := []struct {
+		hookType string
+		specs    arrayFlags
+	}{{hv.WebhookHookType, webhookFlags}, {hv.FileHookType, hookFileFlags}}
+	for _, hooks := range hookFlags {
+		for _, spec := range hooks.specs {
+			view, err := hv.ParseHookView(hooks.hookType, spec)
+			if err == nil {
+				_, err = hoverfly.AddConfiguredHook(view)
+			}
+			if err != nil {
+				log.WithFields(log.Fields{
+					"error": err.Error(),
+					"hook":  spec,
+				}).Fatal("invalid hook")
+			}
+		}
+	}
+
+	err = hoverfly.SetLatencyReplay(hv.LatencyReplay{Enabled: *replayLatency, Factor: *replayLatencyFactor})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("invalid latency replay settings")
+	}
+
+	err = hoverfly.SetCapturePolicy(*capturePolicy)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("invalid capture policy")
+	}
+
+	err = hoverfly.ConfigureUpstreamTLS()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("failed to configure upstream TLS")
+	}
 
 	// if add new user supplied - adding it to database
 	if *addNew {
@@ -340,14 +488,45 @@ func main() {
 	}
 
 	cfg.Webserver = *webserver
+	cfg.WebserverUpstream = *webserverUpstream
+	cfg.WebserverTLSCertificate = *webserverTLSCert
+	cfg.WebserverTLSKey = *webserverTLSKey
+	cfg.WebserverTLSHosts = webserverTLSHostFlags
+	cfg.WebserverClientCA = *webserverClientCA
+	if err := cfg.ValidateWebserverTLS(); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("invalid webserver TLS settings")
+	}
+
+	for _, v := range virtualHostFlags {
+		parts := strings.Split(v, ",")
+		if len(parts) > 2 || parts[0] == "" {
+			log.Fatalf("virtual host should be given as 'destination' or 'destination,port', got: %s", v)
+		}
+		vh := hv.VirtualHost{Destination: parts[0]}
+		if len(parts) == 2 {
+			vh.Port = parts[1]
+		}
+		cfg.VirtualHosts = append(cfg.VirtualHosts, vh)
+	}
 
-	err := hoverfly.StartProxy()
+	err = hoverfly.StartProxy()
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error": err.Error(),
 		}).Fatal("failed to start proxy...")
 	}
 
+	if cfg.TransparentPort != "" {
+		err = hoverfly.StartTransparentProxy()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Fatal("failed to start transparent proxy...")
+		}
+	}
+
 	// starting admin interface, this is blocking
 	hoverfly.StartAdminInterface()
 }
@@ -390,18 +569,25 @@ func createSuperUser(h *hv.Hoverfly) {
 }
 
 func getInitialMode(cfg *hv.Configuration) (string) {
-	if *webserver {
+	if *webserver && *webserverUpstream == "" {
 		return hv.SimulateMode
 	}
 
 	if *capture {
 		// checking whether user supplied other modes
-		if *synthesize == true || *modify == true {
+		if *synthesize == true || *modify == true || *spy == true {
 			log.Fatal("Two or more modes supplied, check your flags")
 		}
 
 		return hv.CaptureMode
 
+	} else if *spy {
+		if *synthesize == true || *modify == true {
+			log.Fatal("Two or more modes supplied, check your flags")
+		}
+
+		return hv.SpyMode
+
 	} else if *synthesize {
 
 
@@ -409,7 +595,7 @@ func getInitialMode(cfg *hv.Configuration) (string) {
 			log.Fatal("Synthesize mode chosen although middleware not supplied")
 		}
 
-		if *capture == true || *modify == true {
+		if *capture == true || *modify == true || *spy == true {
 			log.Fatal("Two or more modes supplied, check your flags")
 		}
 
@@ -420,7 +606,7 @@ func getInitialMode(cfg *hv.Configuration) (string) {
 			log.Fatal("Modify mode chosen although middleware not supplied")
 		}
 
-		if *capture == true || *synthesize == true {
+		if *capture == true || *synthesize == true || *spy == true {
 			log.Fatal("Two or more modes supplied, check your flags")
 		}
 
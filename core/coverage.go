@@ -0,0 +1,107 @@
+package hoverfly
+
+import (
+	"sort"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// DefaultCoverageTop - how many most used records and templates coverage lists by default
+const DefaultCoverageTop = 10
+
+// GetCoverage - returns records and templates that were never matched and at most top of the most
+// matched ones. Records are listed before templates, both in the order they are exported in.
+func (hf *Hoverfly) GetCoverage(top int) (*views.CoverageView, error) {
+	entries, err := hf.RequestCache.GetAllEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	view := &views.CoverageView{Unused: []views.CoverageEntryView{}, MostUsed: []views.CoverageEntryView{}}
+	var used []views.CoverageEntryView
+
+	add := func(entry views.CoverageEntryView) {
+		if entry.Hits == 0 {
+			view.Unused = append(view.Unused, entry)
+		} else {
+			used = append(used, entry)
+		}
+	}
+
+	for _, key := range sortedKeys(entries) {
+		payload, err := models.NewPayloadFromBytes(entries[key])
+		if err != nil {
+			return nil, err
+		}
+
+		entry := views.CoverageEntryView{
+			Key:         key,
+			Method:      payload.Request.Method,
+			Destination: payload.Request.Destination,
+			Path:        payload.Request.Path,
+			Query:       payload.Request.Query,
+			Hits:        hf.RequestMatcher.Coverage.RecordHits(key),
+		}
+		if payload.Metadata != nil {
+			entry.ID = payload.Metadata.ID
+		}
+		view.Records++
+		add(entry)
+	}
+
+	for i, template := range hf.RequestMatcher.TemplateStore {
+		index := i
+		entry := views.CoverageEntryView{
+			Template: &index,
+			Hits:     hf.RequestMatcher.Coverage.TemplateHits(i),
+		}
+		setIfPresent(&entry.Method, template.RequestTemplate.Method)
+		setIfPresent(&entry.Destination, template.RequestTemplate.Destination)
+		setIfPresent(&entry.Path, template.RequestTemplate.Path)
+		setIfPresent(&entry.Query, template.RequestTemplate.Query)
+		view.Templates++
+		add(entry)
+	}
+
+	view.Used = len(used)
+	if total := view.Records + view.Templates; total > 0 {
+		view.Coverage = float64(view.Used) * 100 / float64(total)
+	}
+
+	sort.Stable(coverageByHits(used))
+	if top >= 0 && len(used) > top {
+		used = used[:top]
+	}
+	view.MostUsed = append(view.MostUsed, used...)
+
+	return view, nil
+}
+
+// ResetCoverage - forgets how many times records and templates were matched
+func (hf *Hoverfly) ResetCoverage() {
+	hf.RequestMatcher.Coverage.ResetRecords()
+	hf.RequestMatcher.Coverage.ResetTemplates()
+}
+
+// coverageByHits - sorts coverage entries from the most matched one
+type coverageByHits []views.CoverageEntryView
+
+func (c coverageByHits) Len() int           { return len(c) }
+func (c coverageByHits) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c coverageByHits) Less(i, j int) bool { return c[i].Hits > c[j].Hits }
+
+func sortedKeys(entries map[string][]byte) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func setIfPresent(field *string, value *string) {
+	if value != nil {
+		*field = *value
+	}
+}
@@ -0,0 +1,153 @@
+package hoverfly
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/matching"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func saveCoveragePayload(dbClient *Hoverfly, path string) {
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: path, Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: path},
+	})
+	Expect(err).To(BeNil())
+}
+
+func simulateRequests(dbClient *Hoverfly, paths ...string) {
+	dbClient.Cfg.SetMode(SimulateMode)
+	for _, path := range paths {
+		r, err := http.NewRequest("GET", "http://somehost.com"+path, nil)
+		Expect(err).To(BeNil())
+		dbClient.processRequest(r)
+	}
+}
+
+func TestGetCoverage_ListsUnusedAndMostUsedEntries(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	saveCoveragePayload(dbClient, "/unused")
+	saveCoveragePayload(dbClient, "/once")
+	saveCoveragePayload(dbClient, "/twice")
+
+	templatePath := "/template"
+	dbClient.RequestMatcher.TemplateStore = append(dbClient.RequestMatcher.TemplateStore,
+		matching.RequestTemplatePayload{RequestTemplate: matching.RequestTemplate{Path: &templatePath}, Response: models.ResponseDetails{Status: 200}})
+
+	simulateRequests(dbClient, "/twice", "/once", "/twice", "/template", "/unknown")
+
+	coverage, err := dbClient.GetCoverage(2)
+	Expect(err).To(BeNil())
+
+	Expect(coverage.Records).To(Equal(3))
+	Expect(coverage.Templates).To(Equal(1))
+	Expect(coverage.Used).To(Equal(3))
+	Expect(coverage.Coverage).To(Equal(75.0))
+
+	Expect(coverage.Unused).To(HaveLen(1))
+	Expect(coverage.Unused[0].Path).To(Equal("/unused"))
+	Expect(coverage.Unused[0].Key).ToNot(BeEmpty())
+
+	Expect(coverage.MostUsed).To(HaveLen(2))
+	Expect(coverage.MostUsed[0].Path).To(Equal("/twice"))
+	Expect(coverage.MostUsed[0].Hits).To(Equal(2))
+	Expect(coverage.MostUsed[1].Hits).To(Equal(1))
+
+	dbClient.ResetCoverage()
+	coverage, err = dbClient.GetCoverage(DefaultCoverageTop)
+	Expect(err).To(BeNil())
+	Expect(coverage.Used).To(Equal(0))
+	Expect(coverage.Unused).To(HaveLen(4))
+	Expect(*coverage.Unused[3].Template).To(Equal(0))
+	Expect(coverage.Unused[3].Path).To(Equal("/template"))
+}
+
+func TestGetCoverage_KeepsHitsWhenRecordsAreRekeyed(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/items", Method: "GET", Destination: "somehost.com", Scheme: "http", Query: "b=2&a=1"},
+		Response: models.ResponseDetails{Status: 200},
+	})
+	Expect(err).To(BeNil())
+	simulateRequests(dbClient, "/items?b=2&a=1")
+
+	err = dbClient.SetFingerprintPolicy(&models.FingerprintPolicy{SortQuery: true})
+	Expect(err).To(BeNil())
+
+	coverage, err := dbClient.GetCoverage(DefaultCoverageTop)
+	Expect(err).To(BeNil())
+	Expect(coverage.Used).To(Equal(1))
+	Expect(coverage.Unused).To(BeEmpty())
+	Expect(coverage.MostUsed[0].Hits).To(Equal(1))
+}
+
+func TestCoverageHandlers(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	saveCoveragePayload(dbClient, "/unused")
+	saveCoveragePayload(dbClient, "/used")
+	simulateRequests(dbClient, "/used")
+
+	req, err := http.NewRequest("GET", "/api/coverage?top=1", nil)
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	body, err := ioutil.ReadAll(rec.Body)
+	Expect(err).To(BeNil())
+	var coverage views.CoverageView
+	Expect(json.Unmarshal(body, &coverage)).To(BeNil())
+	Expect(coverage.Used).To(Equal(1))
+	Expect(coverage.MostUsed[0].Path).To(Equal("/used"))
+
+	req, err = http.NewRequest("GET", "/api/records?used=true", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	body, err = ioutil.ReadAll(rec.Body)
+	Expect(err).To(BeNil())
+	var records views.PayloadViewData
+	Expect(json.Unmarshal(body, &records)).To(BeNil())
+	Expect(records.Data).To(HaveLen(1))
+	Expect(records.Data[0].Request.Path).To(Equal("/used"))
+
+	req, err = http.NewRequest("GET", "/api/coverage?top=-1", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+	req, err = http.NewRequest("DELETE", "/api/coverage", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	coverageAfterReset, err := dbClient.GetCoverage(DefaultCoverageTop)
+	Expect(err).To(BeNil())
+	Expect(coverageAfterReset.Used).To(Equal(0))
+}
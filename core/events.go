@@ -0,0 +1,196 @@
+package hoverfly
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// eventSubscriberBuffer - how many events are queued for a subscriber, events are dropped for subscribers
+// that can't keep up so proxying is never blocked
+const eventSubscriberBuffer = 100
+
+// EventStream - hook passing every fired entry to its subscribers
+type EventStream struct {
+	subscribers map[*eventSubscriber]bool
+	mu          sync.Mutex
+}
+
+type eventSubscriber struct {
+	actionTypes map[ActionType]bool
+	events      chan views.EventView
+}
+
+// NewEventStream - returns event stream without subscribers
+func NewEventStream() *EventStream {
+	return &EventStream{subscribers: make(map[*eventSubscriber]bool)}
+}
+
+// ActionTypes - event stream receives entries of all action types
+func (s *EventStream) ActionTypes() []ActionType {
+	return ActionTypes
+}
+
+// Fire - passes entry to subscribers of its action type
+func (s *EventStream) Fire(entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var event *views.EventView
+	for subscriber := range s.subscribers {
+		if len(subscriber.actionTypes) > 0 && !subscriber.actionTypes[entry.ActionType] {
+			continue
+		}
+		if event == nil {
+			event = convertToEventView(entry)
+		}
+
+		select {
+		case subscriber.events <- *event:
+		default:
+			log.WithFields(log.Fields{
+				"actionType": entry.ActionType,
+			}).Warn("Event subscriber is too slow, dropping event")
+		}
+	}
+	return nil
+}
+
+// Subscribe - returns channel receiving events of given action types, all events are received when no
+// action types are given. Returned function cancels the subscription.
+func (s *EventStream) Subscribe(actionTypes []ActionType) (<-chan views.EventView, func()) {
+	subscriber := &eventSubscriber{
+		actionTypes: make(map[ActionType]bool),
+		events:      make(chan views.EventView, eventSubscriberBuffer),
+	}
+	for _, actionType := range actionTypes {
+		subscriber.actionTypes[actionType] = true
+	}
+
+	s.mu.Lock()
+	s.subscribers[subscriber] = true
+	s.mu.Unlock()
+
+	return subscriber.events, func() {
+		s.mu.Lock()
+		delete(s.subscribers, subscriber)
+		s.mu.Unlock()
+	}
+}
+
+// convertToEventView - captured payloads are gob encoded, so they are converted to their JSON view, other
+// data is passed as it is when it's JSON and as a JSON string otherwise
+func convertToEventView(entry *Entry) *views.EventView {
+	event := &views.EventView{
+		ActionType: string(entry.ActionType),
+		Time:       entry.Time.Format(time.RFC3339Nano),
+		Message:    entry.Message,
+	}
+	if len(entry.Data) == 0 {
+		return event
+	}
+
+	var err error
+	if entry.ActionType == ActionTypeRequestCaptured {
+		var payload *models.Payload
+		if payload, err = models.NewPayloadFromBytes(entry.Data); err == nil {
+			event.Data, err = json.Marshal(payload.ConvertToPayloadView())
+		}
+	} else if err = json.Unmarshal(entry.Data, &event.Data); err != nil {
+		event.Data, err = json.Marshal(string(entry.Data))
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":      err.Error(),
+			"actionType": entry.ActionType,
+		}).Error("Failed to convert event data")
+		event.Data = nil
+	}
+	return event
+}
+
+// ParseActionTypes - reads action types given as repeated or comma separated values
+func ParseActionTypes(values []string) ([]ActionType, error) {
+	known := make(map[ActionType]bool)
+	for _, actionType := range ActionTypes {
+		known[actionType] = true
+	}
+
+	var actionTypes []ActionType
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			actionType := ActionType(strings.TrimSpace(name))
+			if actionType == "" {
+				continue
+			}
+			if !known[actionType] {
+				return nil, fmt.Errorf("Unknown action type '%s'", actionType)
+			}
+			actionTypes = append(actionTypes, actionType)
+		}
+	}
+	return actionTypes, nil
+}
+
+// fireHook - fires hooks of given action type with given message and data
+func (hf *Hoverfly) fireHook(actionType ActionType, message string, data []byte) {
+	var en Entry
+	en.ActionType = actionType
+	en.Message = message
+	en.Time = time.Now()
+	en.Data = data
+
+	hf.hooksMu.RLock()
+	defer hf.hooksMu.RUnlock()
+
+	if err := hf.Hooks.Fire(actionType, &en); err != nil {
+		log.WithFields(log.Fields{
+			"error":      err.Error(),
+			"message":    en.Message,
+			"actionType": actionType,
+		}).Error("failed to fire hook")
+	}
+}
+
+// fireConfigurationChanged - fires configuration changed hooks with changed setting and its new value
+// given as JSON, value is nil when setting was deleted
+func (hf *Hoverfly) fireConfigurationChanged(setting string, value []byte) {
+	data, err := json.Marshal(views.ConfigurationChangeView{Setting: setting, Value: value})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":   err.Error(),
+			"setting": setting,
+		}).Error("Failed to serialize configuration change")
+		return
+	}
+	hf.fireHook(ActionTypeConfigurationChanged, "changed", data)
+}
+
+// fireMatchHook - fires simulate hit or miss hooks with the journal entry of request simulated in simulate
+// or spy mode
+func (hf *Hoverfly) fireMatchHook(entry *models.JournalEntry) {
+	if (entry.Mode != SimulateMode && entry.Mode != SpyMode) || entry.Match == "" {
+		return
+	}
+
+	actionType, message := ActionType(ActionTypeSimulateHit), "hit"
+	if entry.Match == models.JournalMissed {
+		actionType, message = ActionTypeSimulateMiss, "miss"
+	}
+
+	data, err := json.Marshal(entry.ConvertToJournalEntryView())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to serialize journal entry")
+		return
+	}
+	hf.fireHook(actionType, message, data)
+}
@@ -0,0 +1,236 @@
+package hoverfly
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/gomega"
+)
+
+func TestEventStream_FiltersByActionType(t *testing.T) {
+	RegisterTestingT(t)
+
+	stream := NewEventStream()
+	all, unsubscribeAll := stream.Subscribe(nil)
+	defer unsubscribeAll()
+	wipes, unsubscribeWipes := stream.Subscribe([]ActionType{ActionTypeWipeDB})
+
+	stream.Fire(&Entry{ActionType: ActionTypeConfigurationChanged, Message: "changed", Data: []byte(`{"mode":"capture"}`)})
+	stream.Fire(&Entry{ActionType: ActionTypeWipeDB, Message: "wipe"})
+
+	event := <-all
+	Expect(event.ActionType).To(Equal(ActionTypeConfigurationChanged))
+	Expect(string(event.Data)).To(Equal(`{"mode":"capture"}`))
+	Expect((<-all).ActionType).To(Equal(ActionTypeWipeDB))
+
+	event = <-wipes
+	Expect(event.ActionType).To(Equal(ActionTypeWipeDB))
+	Expect(event.Data).To(BeNil())
+	Expect(wipes).To(BeEmpty())
+
+	unsubscribeWipes()
+	stream.Fire(&Entry{ActionType: ActionTypeWipeDB, Message: "wipe"})
+	Expect(wipes).To(BeEmpty())
+	Expect(all).To(HaveLen(1))
+}
+
+func TestEventStream_ConvertsCapturedPayload(t *testing.T) {
+	RegisterTestingT(t)
+
+	payload := models.Payload{
+		Request:  models.RequestDetails{Path: "/path", Method: "GET", Destination: "somehost.com"},
+		Response: models.ResponseDetails{Status: 200, Body: "body"},
+	}
+	bts, err := payload.Encode()
+	Expect(err).To(BeNil())
+
+	stream := NewEventStream()
+	events, unsubscribe := stream.Subscribe([]ActionType{ActionTypeRequestCaptured})
+	defer unsubscribe()
+	stream.Fire(&Entry{ActionType: ActionTypeRequestCaptured, Message: "captured", Data: bts})
+
+	var view views.PayloadView
+	Expect(json.Unmarshal((<-events).Data, &view)).To(BeNil())
+	Expect(view.Request.Destination).To(Equal("somehost.com"))
+	Expect(view.Response.Body).To(Equal("body"))
+}
+
+func TestParseActionTypes(t *testing.T) {
+	RegisterTestingT(t)
+
+	actionTypes, err := ParseActionTypes([]string{"simulateHit, simulateMiss", "modeChanged"})
+	Expect(err).To(BeNil())
+	Expect(actionTypes).To(Equal([]ActionType{ActionTypeSimulateHit, ActionTypeSimulateMiss, ActionTypeModeChanged}))
+
+	_, err = ParseActionTypes([]string{"simulateHit,somethingElse"})
+	Expect(err).ToNot(BeNil())
+}
+
+func TestProcessRequest_FiresSimulateHitAndMiss(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/found", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: "found"},
+	})
+	Expect(err).To(BeNil())
+
+	events, unsubscribe := dbClient.Events.Subscribe(nil)
+	defer unsubscribe()
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	for _, path := range []string{"/found", "/missing"} {
+		r, err := http.NewRequest("GET", "http://somehost.com"+path, nil)
+		Expect(err).To(BeNil())
+		dbClient.processRequest(r)
+	}
+
+	var entry views.JournalEntryView
+	event := <-events
+	Expect(event.ActionType).To(Equal(ActionTypeSimulateHit))
+	Expect(json.Unmarshal(event.Data, &entry)).To(BeNil())
+	Expect(entry.Path).To(Equal("/found"))
+
+	event = <-events
+	Expect(event.ActionType).To(Equal(ActionTypeSimulateMiss))
+	Expect(json.Unmarshal(event.Data, &entry)).To(BeNil())
+	Expect(entry.Path).To(Equal("/missing"))
+}
+
+func TestProcessRequest_FiresSimulateHitAndMissInSpyMode(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/found", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: "found"},
+	})
+	Expect(err).To(BeNil())
+
+	events, unsubscribe := dbClient.Events.Subscribe([]ActionType{ActionTypeSimulateHit, ActionTypeSimulateMiss})
+	defer unsubscribe()
+
+	dbClient.Cfg.SetMode(SpyMode)
+	for _, path := range []string{"/found", "/missing"} {
+		r, err := http.NewRequest("GET", "http://somehost.com"+path, nil)
+		Expect(err).To(BeNil())
+		dbClient.processRequest(r)
+	}
+
+	Expect((<-events).ActionType).To(Equal(ActionTypeSimulateHit))
+	Expect((<-events).ActionType).To(Equal(ActionTypeSimulateMiss))
+}
+
+func TestEventsWSHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	admin := httptest.NewServer(getBoneRouter(dbClient))
+	defer admin.Close()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/events?actionType=unknown", nil)
+	Expect(err).To(BeNil())
+	getBoneRouter(dbClient).ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+	wsURL := "ws" + strings.TrimPrefix(admin.URL, "http") + "/api/events?actionType=modeChanged"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	Expect(err).To(BeNil())
+	defer conn.Close()
+
+	// subscription is made after the handshake, so wait for it before changing modes
+	Eventually(func() int {
+		dbClient.Events.mu.Lock()
+		defer dbClient.Events.mu.Unlock()
+		return len(dbClient.Events.subscribers)
+	}).Should(Equal(1))
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	resp, err := http.Post(admin.URL+"/api/state", "application/json", bytes.NewBufferString(`{"mode":"capture"}`))
+	Expect(err).To(BeNil())
+	resp.Body.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event views.EventView
+	Expect(conn.ReadJSON(&event)).To(BeNil())
+	Expect(event.ActionType).To(Equal(ActionTypeModeChanged))
+
+	var modeChange views.ModeChangeView
+	Expect(json.Unmarshal(event.Data, &modeChange)).To(BeNil())
+	Expect(modeChange).To(Equal(views.ModeChangeView{Mode: CaptureMode, PreviousMode: SimulateMode}))
+}
+
+func TestConfigurationHandlers_FireConfigurationChanged(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	events, unsubscribe := dbClient.Events.Subscribe([]ActionType{ActionTypeConfigurationChanged})
+	defer unsubscribe()
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "/api/capture-tags", bytes.NewBufferString(`{"tags": ["smoke"]}`))
+	Expect(err).To(BeNil())
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var change views.ConfigurationChangeView
+	event := <-events
+	Expect(event.Message).To(Equal("changed"))
+	Expect(json.Unmarshal(event.Data, &change)).To(BeNil())
+	Expect(change.Setting).To(Equal("capture-tags"))
+	Expect(string(change.Value)).To(MatchJSON(`{"tags": ["smoke"]}`))
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("DELETE", "/api/chaos", nil)
+	Expect(err).To(BeNil())
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	change = views.ConfigurationChangeView{}
+	event = <-events
+	Expect(json.Unmarshal(event.Data, &change)).To(BeNil())
+	Expect(change.Setting).To(Equal("chaos"))
+	Expect(change.Value).To(BeNil())
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", "/api/state", bytes.NewBufferString(`{"mode": "capture"}`))
+	Expect(err).To(BeNil())
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	change = views.ConfigurationChangeView{}
+	event = <-events
+	Expect(json.Unmarshal(event.Data, &change)).To(BeNil())
+	Expect(change.Setting).To(Equal("state"))
+	Expect(string(change.Value)).To(MatchJSON(`{"mode": "capture"}`))
+
+	// failed updates don't change configuration
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("PUT", "/api/chaos", bytes.NewBufferString(`{"rules": [{"percentage": 10, "responses": []}]}`))
+	Expect(err).To(BeNil())
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+	Consistently(events).ShouldNot(Receive())
+}
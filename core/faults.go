@@ -0,0 +1,202 @@
+package hoverfly
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+)
+
+// malformedResponse - bytes sent to clients instead of a response when malformed fault is injected
+const malformedResponse = "HTTP/1.1 ??? Hoverfly-Malformed-Response\r\nContent-Length: -1\r\n\r\n\x00\xff"
+
+var errFaultInjected = errors.New("connection closed by injected fault")
+
+// clientConns - connections accepted by Hoverfly listeners, looked up by client address so faults can
+// be injected into the connection a request came from
+type clientConns struct {
+	conns map[string]*trackedConn
+	mu    sync.Mutex
+}
+
+func (c *clientConns) track(conn net.Conn) net.Conn {
+	tc := &trackedConn{Conn: conn, owner: c, closed: make(chan struct{})}
+
+	c.mu.Lock()
+	if c.conns == nil {
+		c.conns = make(map[string]*trackedConn)
+	}
+	c.conns[conn.RemoteAddr().String()] = tc
+	c.mu.Unlock()
+
+	return tc
+}
+
+func (c *clientConns) get(remoteAddr string) *trackedConn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conns[remoteAddr]
+}
+
+func (c *clientConns) remove(tc *trackedConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := tc.RemoteAddr().String()
+	if c.conns[key] == tc {
+		delete(c.conns, key)
+	}
+}
+
+// trackedConn - client connection that stops being tracked once it is closed
+type trackedConn struct {
+	net.Conn
+	owner *clientConns
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.owner.remove(c)
+	})
+	return err
+}
+
+// reset - closes connection with TCP RST instead of a graceful close
+func (c *trackedConn) reset() error {
+	if tcpConn, ok := c.Conn.(*net.TCPConn); ok {
+		tcpConn.SetLinger(0)
+	}
+	return c.Close()
+}
+
+// UpdateFaults - sets faults injected into responses
+func (hf *Hoverfly) UpdateFaults(faults models.FaultList) {
+	hf.Faults = &faults
+	log.Info("Fault config updated on hoverfly")
+}
+
+// getFault - returns fault that should be injected for the request this time
+func (hf *Hoverfly) getFault(req *http.Request) *models.Fault {
+	fault := hf.Faults.GetFault(req.URL.String(), req.Method)
+	if fault == nil || !fault.Applies() {
+		return nil
+	}
+	return fault
+}
+
+// injectConnectionFault - resets, hangs or writes garbage to the connection of the request. Raw bytes
+// are written below TLS, so HTTPS clients see malformed response as a broken TLS stream. Returned
+// response is only seen by clients when connection isn't accepted by Hoverfly listeners.
+func (hf *Hoverfly) injectConnectionFault(req *http.Request, fault *models.Fault) *http.Response {
+	conn := hf.conns.get(req.RemoteAddr)
+	if conn == nil {
+		log.WithFields(log.Fields{
+			"fault":      fault.Type,
+			"remoteAddr": req.RemoteAddr,
+		}).Warn("connection of the request is unknown, fault can't be injected")
+		return hoverflyError(req, fmt.Errorf("connection %s is unknown", req.RemoteAddr), "Could not inject fault", http.StatusBadGateway)
+	}
+
+	log.WithFields(log.Fields{
+		"fault":       fault.Type,
+		"path":        req.URL.Path,
+		"destination": req.Host,
+	}).Info("injecting fault")
+
+	switch fault.Type {
+	case models.FaultReset:
+		conn.reset()
+
+	case models.FaultMalformed:
+		io.WriteString(conn.Conn, malformedResponse)
+		conn.Close()
+
+	case models.FaultHang:
+		select {
+		case <-time.After(fault.GetDuration()):
+		case <-conn.closed:
+		}
+		conn.Close()
+	}
+
+	return hoverflyError(req, errFaultInjected, "Fault injected", http.StatusBadGateway)
+}
+
+// injectResponseFault - announces the full length of the response, but closes the connection before
+// the whole body is sent. Length is made up when it isn't known so clients notice the body is cut.
+func injectResponseFault(req *http.Request, resp *http.Response, fault *models.Fault) {
+	if resp == nil {
+		return
+	}
+
+	truncateAt := 0
+	if fault.Type == models.FaultTruncate {
+		truncateAt = fault.TruncateAt
+	}
+
+	length := resp.ContentLength
+	if length <= int64(truncateAt) {
+		length = int64(truncateAt) + 1
+	}
+
+	log.WithFields(log.Fields{
+		"fault":       fault.Type,
+		"truncateAt":  truncateAt,
+		"path":        req.URL.Path,
+		"destination": req.Host,
+	}).Info("injecting fault")
+
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	resp.Header.Set("Content-Length", strconv.FormatInt(length, 10))
+	resp.ContentLength = length
+	resp.Close = true
+
+	body := resp.Body
+	if body == nil {
+		body = ioutil.NopCloser(strings.NewReader(""))
+	}
+	resp.Body = &truncatedBody{body: body, remaining: truncateAt}
+}
+
+// truncatedBody - returns at most remaining bytes of the body and fails afterwards, so the response is
+// never completed
+type truncatedBody struct {
+	body      io.ReadCloser
+	remaining int
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, errFaultInjected
+	}
+	if len(p) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.body.Read(p)
+	b.remaining -= n
+	if err == io.EOF {
+		err = errFaultInjected
+	}
+	return n, err
+}
+
+func (b *truncatedBody) Close() error {
+	return b.body.Close()
+}
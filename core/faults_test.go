@@ -0,0 +1,199 @@
+package hoverfly
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+// startFaultyWebserver - starts webserver simulating a single endpoint with given faults
+func startFaultyWebserver(port string, faults models.FaultList) (*Hoverfly, func()) {
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+
+	dbClient.Cfg.ProxyPort = port
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.SetMode(SimulateMode)
+
+	err := dbClient.StartProxy()
+	Expect(err).To(BeNil())
+
+	err = dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/faulty", Method: "GET", Destination: "localhost:" + port},
+		Response: models.ResponseDetails{Status: 200, Body: "0123456789"},
+	})
+	Expect(err).To(BeNil())
+	dbClient.UpdateFaults(faults)
+
+	return dbClient, func() {
+		dbClient.StopProxy()
+		dbClient.RequestCache.DeleteData()
+		server.Close()
+	}
+}
+
+func TestFault_ResetClosesConnectionWithoutResponse(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, stop := startFaultyWebserver("9801", models.FaultList{{UrlPattern: "faulty", Type: models.FaultReset}})
+	defer stop()
+
+	_, err := http.Get("http://localhost:9801/faulty")
+	Expect(err).ToNot(BeNil())
+}
+
+func TestFault_MalformedResponseIsRejectedByClient(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, stop := startFaultyWebserver("9802", models.FaultList{{UrlPattern: "faulty", Type: models.FaultMalformed}})
+	defer stop()
+
+	_, err := http.Get("http://localhost:9802/faulty")
+	Expect(err).ToNot(BeNil())
+	Expect(err.Error()).To(ContainSubstring("malformed"))
+}
+
+func TestFault_HangHoldsRequestForDuration(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, stop := startFaultyWebserver("9803", models.FaultList{{UrlPattern: "faulty", Type: models.FaultHang, Duration: 200}})
+	defer stop()
+
+	start := time.Now()
+	_, err := http.Get("http://localhost:9803/faulty")
+	Expect(err).ToNot(BeNil())
+	Expect(time.Since(start)).To(BeNumerically(">=", 200*time.Millisecond))
+}
+
+func TestFault_TruncateCutsBody(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, stop := startFaultyWebserver("9804", models.FaultList{{UrlPattern: "faulty", Type: models.FaultTruncate, TruncateAt: 4}})
+	defer stop()
+
+	resp, err := http.Get("http://localhost:9804/faulty")
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	Expect(resp.ContentLength).To(Equal(int64(10)))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).ToNot(BeNil())
+	Expect(string(body)).To(Equal("0123"))
+}
+
+func TestFault_CloseAfterHeadersSendsNoBody(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, stop := startFaultyWebserver("9805", models.FaultList{{UrlPattern: "faulty", Type: models.FaultCloseAfterHeaders}})
+	defer stop()
+
+	resp, err := http.Get("http://localhost:9805/faulty")
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).ToNot(BeNil())
+	Expect(body).To(BeEmpty())
+}
+
+func TestFault_IsNotInjectedWithZeroProbability(t *testing.T) {
+	RegisterTestingT(t)
+
+	never := 0.0
+	_, stop := startFaultyWebserver("9806", models.FaultList{{UrlPattern: "faulty", Type: models.FaultReset, Probability: &never}})
+	defer stop()
+
+	resp, err := http.Get("http://localhost:9806/faulty")
+	Expect(err).To(BeNil())
+
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("0123456789"))
+}
+
+func TestFault_TruncateCutsBodyServedByProxy(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.Cfg.ProxyPort = "9807"
+	dbClient.Cfg.SetMode(SimulateMode)
+	err := dbClient.StartProxy()
+	Expect(err).To(BeNil())
+	defer dbClient.StopProxy()
+
+	err = dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/faulty", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: "0123456789"},
+	})
+	Expect(err).To(BeNil())
+	dbClient.UpdateFaults(models.FaultList{{UrlPattern: "somehost.com/faulty", Type: models.FaultTruncate, TruncateAt: 6}})
+
+	proxyURL, _ := url.Parse("http://localhost:9807")
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get("http://somehost.com/faulty")
+	Expect(err).To(BeNil())
+
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).ToNot(BeNil())
+	Expect(string(body)).To(Equal("012345"))
+}
+
+func TestProcessRequest_ConnectionFaultOfUnknownConnectionReturnsError(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+
+	dbClient.UpdateFaults(models.FaultList{{UrlPattern: ".", Type: models.FaultReset}})
+
+	r, err := http.NewRequest("GET", "http://somehost.com/faulty", nil)
+	Expect(err).To(BeNil())
+	r.RemoteAddr = "127.0.0.1:1"
+
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+}
+
+func TestUpdateFaultsHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/faults", bytes.NewBufferString(`{"data": [{"urlPattern": ".", "type": "explode"}]}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+
+	req, err = http.NewRequest("PUT", "/api/faults", bytes.NewBufferString(`{"data": [{"urlPattern": ".", "type": "truncate", "truncateAt": 100}]}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusCreated))
+	Expect(dbClient.Faults.Len()).To(Equal(1))
+
+	req, err = http.NewRequest("GET", "/api/faults", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Body.String()).To(ContainSubstring(`"truncateAt":100`))
+
+	req, err = http.NewRequest("DELETE", "/api/faults", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.Faults.Len()).To(Equal(0))
+}
@@ -0,0 +1,72 @@
+package hoverfly
+
+import (
+	"encoding/json"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/cache"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// fingerprintPolicyKey - metadata key under which fingerprint policy is stored together with the simulation
+const fingerprintPolicyKey = "hoverfly.fingerprintPolicy"
+
+// GetFingerprintPolicy - returns current request fingerprint policy, nil when default policy is used
+func (hf *Hoverfly) GetFingerprintPolicy() *models.FingerprintPolicy {
+	return hf.RequestMatcher.FingerprintPolicy
+}
+
+// SetFingerprintPolicy - validates and stores given fingerprint policy, existing records are re-keyed
+// so they can be matched with the new policy. Nil policy restores default fingerprinting.
+func (hf *Hoverfly) SetFingerprintPolicy(policy *models.FingerprintPolicy) error {
+	if policy.IsDefault() {
+		policy = nil
+	}
+
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	if policy != nil {
+		b, err := json.Marshal(policy.ConvertToFingerprintPolicyView())
+		if err != nil {
+			return err
+		}
+
+		if err := hf.MetadataCache.Set([]byte(fingerprintPolicyKey), b); err != nil {
+			return err
+		}
+	} else {
+		hf.MetadataCache.Delete([]byte(fingerprintPolicyKey))
+	}
+
+	hf.RequestMatcher.FingerprintPolicy = policy
+	rebuildHashes(hf.RequestCache, *hf.RequestMatcher.Webserver, policy, hf.RequestMatcher.Coverage)
+	rebuildCaptureHistory(hf)
+
+	log.WithFields(log.Fields{
+		"policy": policy.ConvertToFingerprintPolicyView(),
+	}).Info("fingerprint policy updated")
+
+	return nil
+}
+
+// loadFingerprintPolicy - reads fingerprint policy stored with the simulation
+func loadFingerprintPolicy(metadataCache cache.Cache) *models.FingerprintPolicy {
+	b, err := metadataCache.Get([]byte(fingerprintPolicyKey))
+	if err != nil {
+		return nil
+	}
+
+	var view views.FingerprintPolicyView
+	if err := json.Unmarshal(b, &view); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+			"value": string(b),
+		}).Error("Failed to decode stored fingerprint policy, default policy will be used")
+		return nil
+	}
+
+	return models.NewFingerprintPolicyFromView(view)
+}
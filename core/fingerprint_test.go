@@ -0,0 +1,132 @@
+package hoverfly
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestSetFingerprintPolicy_RekeysStoredRecords(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	r, err := http.NewRequest("GET", "http://somehost.com/items?b=2&a=1", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	dbClient.processRequest(r)
+
+	err = dbClient.SetFingerprintPolicy(&models.FingerprintPolicy{SortQuery: true})
+	Expect(err).To(BeNil())
+
+	reordered, err := http.NewRequest("GET", "http://somehost.com/items?a=1&b=2", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(reordered)
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+
+	// policy is stored with the simulation
+	Expect(loadFingerprintPolicy(dbClient.MetadataCache)).To(Equal(&models.FingerprintPolicy{
+		Headers:            []string{},
+		IgnoredQueryParams: []string{},
+		SortQuery:          true,
+		IgnoredBodyPaths:   []string{},
+	}))
+
+	err = dbClient.SetFingerprintPolicy(nil)
+	Expect(err).To(BeNil())
+	Expect(loadFingerprintPolicy(dbClient.MetadataCache)).To(BeNil())
+
+	_, resp = dbClient.processRequest(reordered)
+	Expect(resp.StatusCode).To(Equal(http.StatusPreconditionFailed))
+}
+
+func TestSetFingerprintPolicy_RejectsEmptyHeaderNames(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+
+	err := dbClient.SetFingerprintPolicy(&models.FingerprintPolicy{Headers: []string{" "}})
+	Expect(err).ToNot(BeNil())
+	Expect(dbClient.GetFingerprintPolicy()).To(BeNil())
+}
+
+func TestFingerprintPolicyHandlers(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/fingerprint", bytes.NewBufferString(`{"headers": ["X-Tenant"], "ignoredQueryParams": ["_ts"]}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	req, err = http.NewRequest("GET", "/api/fingerprint", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var pv views.FingerprintPolicyView
+	Expect(json.Unmarshal(rec.Body.Bytes(), &pv)).To(BeNil())
+	Expect(pv.Headers).To(Equal([]string{"X-Tenant"}))
+	Expect(pv.IgnoredQueryParams).To(Equal([]string{"_ts"}))
+
+	// policy is exported together with records
+	req, err = http.NewRequest("GET", "/api/records", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	var export views.PayloadViewData
+	Expect(json.Unmarshal(rec.Body.Bytes(), &export)).To(BeNil())
+	Expect(export.FingerprintPolicy).To(Equal(&pv))
+
+	req, err = http.NewRequest("DELETE", "/api/fingerprint", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetFingerprintPolicy()).To(BeNil())
+}
+
+func TestImportRecordsAppliesExportedFingerprintPolicy(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	body := `{"data": [{"request": {"path": "/items", "method": "GET", "destination": "somehost.com", "query": "page=1&_ts=1"}, "response": {"status": 201, "body": "ok"}}],
+		"fingerprintPolicy": {"ignoredQueryParams": ["_ts"]}}`
+
+	req, err := http.NewRequest("POST", "/api/records", ioutil.NopCloser(bytes.NewBufferString(body)))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	r, err := http.NewRequest("GET", "http://somehost.com/items?page=1&_ts=2", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+}
@@ -0,0 +1,416 @@
+package hoverfly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// types of hooks that can be registered with flags or through the admin API
+const (
+	// WebhookHookType - hook POSTing entries to a URL
+	WebhookHookType = "webhook"
+	// FileHookType - hook appending entries to a file
+	FileHookType = "file"
+)
+
+// DefaultWebhookRetries - how many times failed delivery to a webhook is retried by default
+const DefaultWebhookRetries = 3
+
+// webhookQueueSize - how many entries wait for delivery, entries are dropped when webhook can't keep up
+const webhookQueueSize = 100
+
+// webhookRetryDelay - delay before the first retry of failed delivery, it doubles with every retry
+var webhookRetryDelay = time.Second
+
+// closableHook - hook holding resources that are released when the hook is removed
+type closableHook interface {
+	Hook
+	Close() error
+}
+
+// WebhookHook - hook POSTing entries as JSON to URL. Entries are delivered in background, so slow
+// receivers don't block proxying, failed deliveries are retried with exponential backoff.
+type WebhookHook struct {
+	URL     string
+	Retries int
+
+	actionTypes []ActionType
+	client      *http.Client
+	queue       chan views.EventView
+}
+
+// NewWebhookHook - returns webhook for given action types and starts delivering its entries
+func NewWebhookHook(url string, actionTypes []ActionType, retries int) *WebhookHook {
+	hook := &WebhookHook{
+		URL:         url,
+		Retries:     retries,
+		actionTypes: actionTypes,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan views.EventView, webhookQueueSize),
+	}
+	go hook.deliverAll(webhookRetryDelay)
+	return hook
+}
+
+// ActionTypes - action types the webhook was registered for
+func (h *WebhookHook) ActionTypes() []ActionType {
+	return h.actionTypes
+}
+
+// Fire - queues entry for delivery
+func (h *WebhookHook) Fire(entry *Entry) error {
+	select {
+	case h.queue <- *convertToEventView(entry):
+	default:
+		log.WithFields(log.Fields{
+			"url":        h.URL,
+			"actionType": entry.ActionType,
+		}).Warn("Webhook can't keep up, dropping entry")
+	}
+	return nil
+}
+
+// Close - stops delivery, entries that are already queued are still delivered
+func (h *WebhookHook) Close() error {
+	close(h.queue)
+	return nil
+}
+
+func (h *WebhookHook) deliverAll(retryDelay time.Duration) {
+	for event := range h.queue {
+		body, err := json.Marshal(event)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Failed to serialize webhook entry")
+			continue
+		}
+
+		delay := retryDelay
+		for attempt := 0; ; attempt++ {
+			if err = h.post(body); err == nil || attempt >= h.Retries {
+				break
+			}
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":      err.Error(),
+				"url":        h.URL,
+				"actionType": event.ActionType,
+			}).Error("Failed to deliver entry to webhook")
+		}
+	}
+}
+
+func (h *WebhookHook) post(body []byte) error {
+	resp, err := h.client.Post(h.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FileHook - hook appending entries as JSON lines to the file at Path. File is rotated before it would
+// grow over MaxSize bytes, MaxFiles rotated files are kept as Path.1 (newest) to Path.N. Without rotated
+// files the file is truncated in place.
+type FileHook struct {
+	Path     string
+	MaxSize  int64
+	MaxFiles int
+
+	actionTypes []ActionType
+	file        *os.File
+	size        int64
+	mu          sync.Mutex
+}
+
+// NewFileHook - opens file for given action types, zero maxSize turns off rotation
+func NewFileHook(path string, actionTypes []ActionType, maxSize int64, maxFiles int) (*FileHook, error) {
+	hook := &FileHook{
+		Path:        path,
+		MaxSize:     maxSize,
+		MaxFiles:    maxFiles,
+		actionTypes: actionTypes,
+	}
+	if err := hook.open(); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (h *FileHook) open() error {
+	file, err := os.OpenFile(h.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	h.file = file
+	h.size = info.Size()
+	return nil
+}
+
+// ActionTypes - action types the file hook was registered for
+func (h *FileHook) ActionTypes() []ActionType {
+	return h.actionTypes
+}
+
+// Fire - appends entry to the file, rotating it when it's full
+func (h *FileHook) Fire(entry *Entry) error {
+	line, err := json.Marshal(convertToEventView(entry))
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.file == nil {
+		return errors.New("file hook is closed")
+	}
+	if h.MaxSize > 0 && h.size > 0 && h.size+int64(len(line)) > h.MaxSize {
+		if err := h.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := h.file.Write(line)
+	h.size += int64(n)
+	return err
+}
+
+func (h *FileHook) rotatedPath(index int) string {
+	return h.Path + "." + strconv.Itoa(index)
+}
+
+func (h *FileHook) rotate() error {
+	if h.MaxFiles == 0 {
+		if err := h.file.Truncate(0); err != nil {
+			return err
+		}
+		h.size = 0
+		return nil
+	}
+
+	h.file.Close()
+	h.file = nil
+
+	// the oldest file is dropped, missing files are fine, rotation may not have happened yet
+	os.Remove(h.rotatedPath(h.MaxFiles))
+	for i := h.MaxFiles - 1; i > 0; i-- {
+		os.Rename(h.rotatedPath(i), h.rotatedPath(i+1))
+	}
+	if err := os.Rename(h.Path, h.rotatedPath(1)); err != nil {
+		return err
+	}
+
+	return h.open()
+}
+
+// Close - closes the file
+func (h *FileHook) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.file == nil {
+		return nil
+	}
+	err := h.file.Close()
+	h.file = nil
+	return err
+}
+
+// configuredHook - hook registered with flags or through the admin API
+type configuredHook struct {
+	view views.HookView
+	hook closableHook
+}
+
+// newHookFromView - validates hook described by view and creates it, defaults are filled in the view.
+// Hook is registered for all action types when none are given.
+func newHookFromView(view *views.HookView) (closableHook, error) {
+	actionTypes, err := ParseActionTypes(view.ActionTypes)
+	if err != nil {
+		return nil, err
+	}
+	if len(actionTypes) == 0 {
+		actionTypes = ActionTypes
+	}
+	view.ActionTypes = nil
+	for _, actionType := range actionTypes {
+		view.ActionTypes = append(view.ActionTypes, string(actionType))
+	}
+
+	switch view.Type {
+	case WebhookHookType:
+		if u, err := url.Parse(view.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("Webhook URL must be an absolute http or https URL, got '%s'", view.URL)
+		}
+		if view.Retries == nil {
+			retries := DefaultWebhookRetries
+			view.Retries = &retries
+		}
+		if *view.Retries < 0 {
+			return nil, fmt.Errorf("Webhook retries can't be negative, got %d", *view.Retries)
+		}
+		return NewWebhookHook(view.URL, actionTypes, *view.Retries), nil
+
+	case FileHookType:
+		if view.Path == "" {
+			return nil, errors.New("File hook requires path")
+		}
+		if view.MaxSize < 0 || view.MaxFiles < 0 {
+			return nil, errors.New("File hook max size and max files can't be negative")
+		}
+		hook, err := NewFileHook(view.Path, actionTypes, view.MaxSize, view.MaxFiles)
+		if err != nil {
+			return nil, err
+		}
+		return hook, nil
+	}
+
+	return nil, fmt.Errorf("Hook type must be '%s' or '%s', got '%s'", WebhookHookType, FileHookType, view.Type)
+}
+
+// ParseHookView - reads hook given on the command line as URL or path followed by options separated by
+// semicolons (i.e. 'https://ci.local/notify;actions=requestCaptured,wipeDatabase;retries=5' or
+// 'events.log;max-size=1048576;max-files=3')
+func ParseHookView(hookType, spec string) (views.HookView, error) {
+	view := views.HookView{Type: hookType}
+
+	parts := strings.Split(spec, ";")
+	if hookType == WebhookHookType {
+		view.URL = strings.TrimSpace(parts[0])
+	} else {
+		view.Path = strings.TrimSpace(parts[0])
+	}
+
+	for _, option := range parts[1:] {
+		nameValue := strings.SplitN(option, "=", 2)
+		if len(nameValue) != 2 {
+			return view, fmt.Errorf("Hook option must be given as 'name=value', got '%s'", option)
+		}
+		name, value := strings.TrimSpace(nameValue[0]), strings.TrimSpace(nameValue[1])
+
+		var err error
+		switch name {
+		case "actions":
+			view.ActionTypes = append(view.ActionTypes, value)
+		case "retries":
+			var retries int
+			retries, err = strconv.Atoi(value)
+			view.Retries = &retries
+		case "max-size":
+			view.MaxSize, err = strconv.ParseInt(value, 10, 64)
+		case "max-files":
+			view.MaxFiles, err = strconv.Atoi(value)
+		default:
+			return view, fmt.Errorf("Unknown hook option '%s'", name)
+		}
+		if err != nil {
+			return view, fmt.Errorf("Hook option '%s' must be a number, got '%s'", name, value)
+		}
+	}
+	return view, nil
+}
+
+// ErrFileHookNotAllowed - file hooks write to arbitrary paths, so they can only be registered with flags
+var ErrFileHookNotAllowed = errors.New("File hooks can only be registered with the -hook-file flag")
+
+// AddConfiguredHook - creates hook described by view and registers it, returns the view with ID of the hook
+func (hf *Hoverfly) AddConfiguredHook(view views.HookView) (views.HookView, error) {
+	view.ID = ""
+	hook, err := newHookFromView(&view)
+	if err != nil {
+		return view, err
+	}
+
+	hf.hooksMu.Lock()
+	defer hf.hooksMu.Unlock()
+
+	hf.nextHookID++
+	view.ID = strconv.Itoa(hf.nextHookID)
+	hf.Hooks.Add(hook)
+	hf.configuredHooks = append(hf.configuredHooks, &configuredHook{view: view, hook: hook})
+
+	log.WithFields(log.Fields{
+		"id":          view.ID,
+		"type":        view.Type,
+		"actionTypes": view.ActionTypes,
+	}).Info("hook registered")
+	return view, nil
+}
+
+// GetConfiguredHooks - returns hooks registered with flags or through the admin API
+func (hf *Hoverfly) GetConfiguredHooks() views.HooksView {
+	hf.hooksMu.RLock()
+	defer hf.hooksMu.RUnlock()
+
+	hooks := views.HooksView{Data: []views.HookView{}}
+	for _, configured := range hf.configuredHooks {
+		hooks.Data = append(hooks.Data, configured.view)
+	}
+	return hooks
+}
+
+// RemoveConfiguredHook - removes hook with given ID, returns error when there is no such hook
+func (hf *Hoverfly) RemoveConfiguredHook(id string) error {
+	hf.hooksMu.Lock()
+	defer hf.hooksMu.Unlock()
+
+	for i, configured := range hf.configuredHooks {
+		if configured.view.ID == id {
+			hf.removeConfiguredHook(configured)
+			hf.configuredHooks = append(hf.configuredHooks[:i], hf.configuredHooks[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("Hook '%s' not found", id)
+}
+
+// RemoveConfiguredHooks - removes all hooks registered with flags or through the admin API
+func (hf *Hoverfly) RemoveConfiguredHooks() {
+	hf.hooksMu.Lock()
+	defer hf.hooksMu.Unlock()
+
+	for _, configured := range hf.configuredHooks {
+		hf.removeConfiguredHook(configured)
+	}
+	hf.configuredHooks = nil
+}
+
+// removeConfiguredHook - unregisters and closes hook, hooks lock must be held, so the hook isn't being fired
+func (hf *Hoverfly) removeConfiguredHook(configured *configuredHook) {
+	hf.Hooks.Remove(configured.hook)
+	if err := configured.hook.Close(); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+			"id":    configured.view.ID,
+		}).Warn("Failed to close hook")
+	}
+}
@@ -0,0 +1,202 @@
+package hoverfly
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestWebhookHook_RetriesFailedDelivery(t *testing.T) {
+	RegisterTestingT(t)
+
+	defer func(delay time.Duration) { webhookRetryDelay = delay }(webhookRetryDelay)
+	webhookRetryDelay = time.Millisecond
+
+	var mu sync.Mutex
+	var attempts int
+	var received views.EventView
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer receiver.Close()
+
+	hook := NewWebhookHook(receiver.URL, []ActionType{ActionTypeWipeDB}, 2)
+	defer hook.Close()
+	Expect(hook.Fire(&Entry{ActionType: ActionTypeWipeDB, Message: "wipe"})).To(BeNil())
+
+	Eventually(func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts
+	}).Should(Equal(3))
+
+	mu.Lock()
+	defer mu.Unlock()
+	Expect(received.ActionType).To(Equal(ActionTypeWipeDB))
+	Expect(received.Message).To(Equal("wipe"))
+}
+
+func TestFileHook_AppendsAndRotates(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "hoverfly-hooks")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.log")
+
+	hook, err := NewFileHook(path, []ActionType{ActionTypeWipeDB}, 150, 1)
+	Expect(err).To(BeNil())
+	defer hook.Close()
+
+	for _, message := range []string{"first", "second", "third"} {
+		Expect(hook.Fire(&Entry{ActionType: ActionTypeWipeDB, Message: message})).To(BeNil())
+	}
+
+	current, err := ioutil.ReadFile(path)
+	Expect(err).To(BeNil())
+	rotated, err := ioutil.ReadFile(path + ".1")
+	Expect(err).To(BeNil())
+	_, err = os.Stat(path + ".2")
+	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	var event views.EventView
+	lines := strings.Split(strings.TrimSpace(string(current)), "\n")
+	Expect(lines).To(HaveLen(1))
+	Expect(json.Unmarshal([]byte(lines[0]), &event)).To(BeNil())
+	Expect(event.Message).To(Equal("third"))
+
+	lines = strings.Split(strings.TrimSpace(string(rotated)), "\n")
+	Expect(lines).To(HaveLen(1))
+	Expect(json.Unmarshal([]byte(lines[0]), &event)).To(BeNil())
+	Expect(event.Message).To(Equal("second"))
+}
+
+func TestFileHook_TruncatesWithoutRotatedFiles(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "hoverfly-hooks")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.log")
+
+	hook, err := NewFileHook(path, []ActionType{ActionTypeWipeDB}, 150, 0)
+	Expect(err).To(BeNil())
+	defer hook.Close()
+
+	for _, message := range []string{"first", "second"} {
+		Expect(hook.Fire(&Entry{ActionType: ActionTypeWipeDB, Message: message})).To(BeNil())
+	}
+
+	current, err := ioutil.ReadFile(path)
+	Expect(err).To(BeNil())
+	Expect(string(current)).ToNot(ContainSubstring("first"))
+	Expect(string(current)).To(ContainSubstring("second"))
+	_, err = os.Stat(path + ".1")
+	Expect(os.IsNotExist(err)).To(BeTrue())
+}
+
+func TestParseHookView(t *testing.T) {
+	RegisterTestingT(t)
+
+	view, err := ParseHookView(WebhookHookType, "http://ci.local/notify?a=b;actions=requestCaptured,wipeDatabase;retries=5")
+	Expect(err).To(BeNil())
+	Expect(view.URL).To(Equal("http://ci.local/notify?a=b"))
+	Expect(view.ActionTypes).To(Equal([]string{"requestCaptured,wipeDatabase"}))
+	Expect(*view.Retries).To(Equal(5))
+
+	view, err = ParseHookView(FileHookType, "events.log;max-size=1024;max-files=2")
+	Expect(err).To(BeNil())
+	Expect(view.Path).To(Equal("events.log"))
+	Expect(view.MaxSize).To(Equal(int64(1024)))
+	Expect(view.MaxFiles).To(Equal(2))
+
+	_, err = ParseHookView(FileHookType, "events.log;max-size=big")
+	Expect(err).ToNot(BeNil())
+
+	_, err = ParseHookView(FileHookType, "events.log;colour=red")
+	Expect(err).ToNot(BeNil())
+}
+
+func TestHooksHandlers(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	dir, err := ioutil.TempDir("", "hoverfly-hooks")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.log")
+
+	req, err := http.NewRequest("POST", "/api/hooks", bytes.NewBufferString(`{"type": "file", "path": "`+path+`", "actionTypes": ["wipeDatabase"]}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusForbidden))
+	_, err = os.Stat(path)
+	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	hook, err := dbClient.AddConfiguredHook(views.HookView{Type: FileHookType, Path: path, ActionTypes: []string{ActionTypeWipeDB}})
+	Expect(err).To(BeNil())
+	Expect(hook.ID).To(Equal("1"))
+
+	req, err = http.NewRequest("POST", "/api/hooks", bytes.NewBufferString(`{"type": "webhook", "url": "not a url"}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+
+	req, err = http.NewRequest("DELETE", "/api/records", nil)
+	Expect(err).To(BeNil())
+	m.ServeHTTP(httptest.NewRecorder(), req)
+
+	content, err := ioutil.ReadFile(path)
+	Expect(err).To(BeNil())
+	Expect(string(content)).To(ContainSubstring(`"actionType":"wipeDatabase"`))
+
+	req, err = http.NewRequest("GET", "/api/hooks", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var hooks views.HooksView
+	Expect(json.Unmarshal(rec.Body.Bytes(), &hooks)).To(BeNil())
+	Expect(hooks.Data).To(HaveLen(1))
+	Expect(hooks.Data[0].Path).To(Equal(path))
+	Expect(hooks.Data[0].ActionTypes).To(Equal([]string{ActionTypeWipeDB}))
+
+	req, err = http.NewRequest("DELETE", "/api/hooks/1", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetConfiguredHooks().Data).To(BeEmpty())
+	Expect(dbClient.Hooks[ActionTypeWipeDB]).To(HaveLen(1))
+
+	req, err = http.NewRequest("DELETE", "/api/hooks/1", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusNotFound))
+}
@@ -31,6 +31,9 @@ const ModifyMode = "modify"
 // CaptureMode - requests are captured and stored in cache
 const CaptureMode = "capture"
 
+// SpyMode - requests are simulated when a match is found, otherwise they are forwarded to the real service
+const SpyMode = "spy"
+
 // orPanic - wrapper for logging errors
 func orPanic(err error) {
 	if err != nil {
@@ -52,10 +55,30 @@ type Hoverfly struct {
 	Hooks          ActionTypeHooks
 
 	ResponseDelays models.ResponseDelays
+	Throttles      models.Throttles
+	Faults         models.Faults
+	CaptureFilters *models.CaptureFilters
+	Journal        *models.Journal
+	Events         *EventStream
+
+	hooksMu         sync.RWMutex
+	configuredHooks []*configuredHook
+	nextHookID      int
+
+	captureTags   []string
+	latencyReplay LatencyReplay
+	chaos         *models.Chaos
+	rateLimiter   *models.RateLimiter
 
 	Proxy *goproxy.ProxyHttpServer
 	SL    *StoppableListener
-	mu    sync.Mutex
+
+	TransparentSL  *StoppableListener
+	VirtualHostSLs []*StoppableListener
+
+	conns clientConns
+
+	mu sync.Mutex
 }
 
 // GetNewHoverfly returns a configured ProxyHttpServer and DBClient
@@ -64,34 +87,61 @@ func GetNewHoverfly(cfg *Configuration, requestCache, metadataCache cache.Cache,
 		RequestCache:  requestCache,
 		TemplateStore: matching.RequestTemplateStore{},
 		Webserver:     &cfg.Webserver,
+		Coverage:      models.NewCoverage(),
+
+		FingerprintPolicy: loadFingerprintPolicy(metadataCache),
+		Redaction:         loadRedactionRules(metadataCache),
 	}
 	h := &Hoverfly{
 		RequestCache:   requestCache,
 		MetadataCache:  metadataCache,
 		Authentication: authentication,
-		HTTP: &http.Client{Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.TLSVerification},
-		}},
+		HTTP: &http.Client{
+			Transport: newUpstreamHTTPTransport(cfg.TLSVerification, nil, nil),
+		},
 		Cfg:            cfg,
-		Counter:        metrics.NewModeCounter([]string{SimulateMode, SynthesizeMode, ModifyMode, CaptureMode}),
+		Counter:        metrics.NewModeCounter([]string{SimulateMode, SynthesizeMode, ModifyMode, CaptureMode, SpyMode}),
 		Hooks:          make(ActionTypeHooks),
 		ResponseDelays: &models.ResponseDelayList{},
+		Throttles:      &models.ThrottleList{},
+		Faults:         &models.FaultList{},
+		Journal:        models.NewJournal(models.DefaultJournalSize, nil),
+		Events:         NewEventStream(),
 		RequestMatcher: requestMatcher,
 	}
+	h.AddHook(h.Events)
 	return h
 }
 
 // StartProxy - starts proxy with current configuration, this method is non blocking.
 func (hf *Hoverfly) StartProxy() error {
 
-	rebuildHashes(hf.RequestCache, hf.Cfg.Webserver)
+	hashWithoutHost := hf.Cfg.HashWithoutHost()
+	hf.RequestMatcher.Webserver = &hashWithoutHost
+	rebuildHashes(hf.RequestCache, hashWithoutHost, hf.RequestMatcher.FingerprintPolicy, hf.RequestMatcher.Coverage)
+	rebuildCaptureHistory(hf)
 
 	if hf.Cfg.ProxyPort == "" {
 		return fmt.Errorf("Proxy port is not set!")
 	}
 
+	var tlsConfig *tls.Config
 	if hf.Cfg.Webserver {
+		if _, err := newReverseProxy(hf.Cfg.WebserverUpstream); err != nil {
+			return err
+		}
+
+		if len(hf.Cfg.VirtualHosts) > 0 && hf.Cfg.WebserverUpstream != "" {
+			return fmt.Errorf("virtual hosts can't be used together with webserver upstream")
+		}
+
 		hf.Proxy = NewWebserverProxy(hf)
+
+		var err error
+		tlsConfig, err = hf.webserverTLSConfig()
+		if err != nil {
+			return err
+		}
 	} else {
 		hf.Proxy = NewProxy(hf)
 	}
@@ -112,9 +162,15 @@ func (hf *Hoverfly) StartProxy() error {
 	if err != nil {
 		return err
 	}
+	sl.conns = &hf.conns
 	hf.SL = sl
 	server := http.Server{}
 
+	var serverListener net.Listener = sl
+	if tlsConfig != nil {
+		serverListener = tls.NewListener(sl, tlsConfig)
+	}
+
 	hf.Cfg.ProxyControlWG.Add(1)
 
 	go func() {
@@ -124,15 +180,23 @@ func (hf *Hoverfly) StartProxy() error {
 		}()
 		log.Info("serving proxy")
 		server.Handler = hf.Proxy
-		log.Warn(server.Serve(sl))
+		log.Warn(server.Serve(serverListener))
 	}()
 
+	if hf.Cfg.Webserver {
+		return hf.startVirtualHosts(tlsConfig)
+	}
+
 	return nil
 }
 
 // StopProxy - stops proxy
 func (hf *Hoverfly) StopProxy() {
 	hf.SL.Stop()
+	for _, sl := range hf.VirtualHostSLs {
+		sl.Stop()
+	}
+	hf.VirtualHostSLs = nil
 	hf.Cfg.ProxyControlWG.Wait()
 }
 
@@ -187,6 +251,20 @@ func (hf *Hoverfly) UpdateResponseDelays(responseDelays models.ResponseDelayList
 	log.Info("Response delay config updated on hoverfly")
 }
 
+// applyResponseDelay - applies response delay configured for the request and records it in metrics and
+// journal, returns false when there is no delay configured
+func (hf *Hoverfly) applyResponseDelay(req *http.Request) bool {
+	respDelay := hf.ResponseDelays.GetDelay(req.URL.String(), req.Method)
+	if respDelay == nil {
+		return false
+	}
+
+	delay := respDelay.Execute()
+	hf.Counter.RecordDelay(delay)
+	journalEntry(req).Delay += delay
+	return true
+}
+
 func hoverflyError(req *http.Request, err error, msg string, statusCode int) *http.Response {
 	return goproxy.NewResponse(req,
 		goproxy.ContentTypeText, statusCode,
@@ -194,8 +272,43 @@ func hoverflyError(req *http.Request, err error, msg string, statusCode int) *ht
 }
 
 // processRequest - processes incoming requests and based on proxy state (record/playback)
-// returns HTTP response.
-func (hf *Hoverfly) processRequest(req *http.Request) (*http.Request, *http.Response) {
+// returns HTTP response. Requests are rate limited, request and response bodies are throttled, error
+// responses and faults are injected when configured. Every request is journaled.
+func (hf *Hoverfly) processRequest(req *http.Request) (_ *http.Request, resp *http.Response) {
+	req, entry := hf.withJournalEntry(req)
+	defer func() {
+		hf.journal(entry, resp)
+		hf.recordRequestMetrics(entry)
+		hf.fireMatchHook(entry)
+	}()
+
+	fault := hf.getFault(req)
+	if fault != nil {
+		hf.Counter.CountFault(hf.Cfg.GetMode())
+	}
+	if fault != nil && fault.IsConnectionFault() {
+		return req, hf.injectConnectionFault(req, fault)
+	}
+
+	limited, decision := hf.rateLimit(req)
+	if limited != nil {
+		return req, limited
+	}
+
+	hf.throttleRequest(req)
+	req, resp = hf.processRequestInMode(req)
+	resp = hf.applyChaos(req, resp)
+	addRateLimitHeaders(resp, decision)
+	hf.throttleResponse(req, resp, nil)
+
+	if fault != nil {
+		injectResponseFault(req, resp, fault)
+	}
+	return req, resp
+}
+
+// processRequestInMode - returns HTTP response based on proxy state
+func (hf *Hoverfly) processRequestInMode(req *http.Request) (*http.Request, *http.Response) {
 
 	mode := hf.Cfg.GetMode()
 
@@ -232,10 +345,7 @@ func (hf *Hoverfly) processRequest(req *http.Request) (*http.Request, *http.Resp
 			"destination": req.Host,
 		}).Info("synthetic response created successfuly")
 
-		respDelay := hf.ResponseDelays.GetDelay(req.URL.String(), req.Method)
-		if respDelay != nil {
-			respDelay.Execute()
-		}
+		hf.applyResponseDelay(req)
 
 		return req, response
 
@@ -255,13 +365,19 @@ func (hf *Hoverfly) processRequest(req *http.Request) (*http.Request, *http.Resp
 				http.StatusServiceUnavailable)
 		}
 
-		respDelay := hf.ResponseDelays.GetDelay(req.URL.String(), req.Method)
-		if respDelay != nil {
-			respDelay.Execute()
-		}
+		hf.applyResponseDelay(req)
 
 		// returning modified response
 		return req, response
+
+	} else if mode == SpyMode {
+		response, err := hf.spyRequest(req)
+
+		if err != nil {
+			return req, hoverflyError(req, err, "Could not forward request", http.StatusServiceUnavailable)
+		}
+
+		return req, response
 	}
 
 	newResponse := hf.getResponse(req)
@@ -272,9 +388,18 @@ func (hf *Hoverfly) processRequest(req *http.Request) (*http.Request, *http.Resp
 
 // AddHook - adds a hook to DBClient
 func (hf *Hoverfly) AddHook(hook Hook) {
+	hf.hooksMu.Lock()
+	defer hf.hooksMu.Unlock()
 	hf.Hooks.Add(hook)
 }
 
+// RemoveHook - removes a hook from DBClient
+func (hf *Hoverfly) RemoveHook(hook Hook) {
+	hf.hooksMu.Lock()
+	defer hf.hooksMu.Unlock()
+	hf.Hooks.Remove(hook)
+}
+
 // captureRequest saves request for later playback
 func (hf *Hoverfly) captureRequest(req *http.Request) (*http.Response, error) {
 
@@ -301,14 +426,22 @@ func (hf *Hoverfly) captureRequest(req *http.Request) (*http.Response, error) {
 	// forwarding request
 	req.Body = ioutil.NopCloser(bytes.NewBuffer(reqBody))
 
-	req, resp, err := hf.doRequest(req)
+	metadata := hf.newCaptureMetadata(req.RemoteAddr)
+	forwarded, resp, latency, err := hf.doRequest(req)
+	metadata.Latency = latency
 
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error": err.Error(),
 			"mode":  "capture",
 		}).Error("Got error when reading body after being modified by middleware")
+
+		if hf.Cfg.CaptureUpstreamErrors {
+			hf.saveUpstreamError(req, reqBody, err, metadata)
+		}
+		return nil, err
 	}
+	req = forwarded
 
 	reqBody, err = ioutil.ReadAll(req.Body)
 	req.Body = ioutil.NopCloser(bytes.NewBuffer(reqBody))
@@ -326,16 +459,28 @@ func (hf *Hoverfly) captureRequest(req *http.Request) (*http.Response, error) {
 			return resp, err
 		}
 
+		if !hf.GetCaptureFilters().Allows(req.Method, req.URL.Path, resp.StatusCode, resp.Header.Get("Content-Type")) {
+			log.WithFields(log.Fields{
+				"method": req.Method,
+				"path":   req.URL.Path,
+				"status": resp.StatusCode,
+				"mode":   "capture",
+			}).Debug("request was not captured because of capture filters")
+
+			return resp, nil
+		}
+
 		// saving response body with request/response meta to cache
-		hf.save(req, reqBody, resp, respBody)
+		hf.save(req, reqBody, resp, respBody, metadata)
 	}
 
 	// return new response or error here
 	return resp, err
 }
 
-// doRequest performs original request and returns response that should be returned to client and error (if there is one)
-func (hf *Hoverfly) doRequest(request *http.Request) (*http.Request, *http.Response, error) {
+// doRequest performs original request and returns response that should be returned to client, time upstream took
+// to respond, without time spent in middleware, and error (if there is one)
+func (hf *Hoverfly) doRequest(request *http.Request) (*http.Request, *http.Response, time.Duration, error) {
 
 	// We can't have this set. And it only contains "/pkg/net/http/" anyway
 	request.RequestURI = ""
@@ -346,12 +491,12 @@ func (hf *Hoverfly) doRequest(request *http.Request) (*http.Request, *http.Respo
 
 		rd, err := getRequestDetails(request)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, 0, err
 		}
 		payload.Request = rd
 
 		c := NewConstructor(request, payload)
-		err = c.ApplyMiddleware(hf.Cfg.Middleware)
+		err = applyMiddleware(request, c, hf.Cfg.Middleware)
 
 		if err != nil {
 			log.WithFields(log.Fields{
@@ -361,13 +506,13 @@ func (hf *Hoverfly) doRequest(request *http.Request) (*http.Request, *http.Respo
 				"method": request.Method,
 				"path":   request.URL.Path,
 			}).Error("could not forward request, middleware failed to modify request.")
-			return nil, nil, err
+			return nil, nil, 0, err
 		}
 
 		request, err = c.ReconstructRequest()
 
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, 0, err
 		}
 	}
 
@@ -375,7 +520,10 @@ func (hf *Hoverfly) doRequest(request *http.Request) (*http.Request, *http.Respo
 
 	request.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
 
+	start := time.Now()
 	resp, err := hf.HTTP.Do(request)
+	latency := time.Since(start)
+	hf.Counter.RecordUpstreamLatency(request.Host, latency)
 
 	request.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
 
@@ -387,7 +535,7 @@ func (hf *Hoverfly) doRequest(request *http.Request) (*http.Request, *http.Respo
 			"method": request.Method,
 			"path":   request.URL.Path,
 		}).Error("could not forward request, failed to do an HTTP request.")
-		return nil, nil, err
+		return nil, nil, latency, err
 	}
 
 	log.WithFields(log.Fields{
@@ -399,29 +547,74 @@ func (hf *Hoverfly) doRequest(request *http.Request) (*http.Request, *http.Respo
 
 	resp.Header.Set("hoverfly", "Was-Here")
 
-	return request, resp, nil
+	return request, resp, latency, nil
 
 }
 
 // getResponse returns stored response from cache
 func (hf *Hoverfly) getResponse(req *http.Request) *http.Response {
 
-	payload, matchErr := hf.RequestMatcher.GetPayload(req)
+	payload, matchedID, matchErr := hf.RequestMatcher.MatchPayload(req)
 	if matchErr != nil {
+		journalMissed(req, matchErr.Error())
 		return hoverflyError(req, matchErr, matchErr.Error(), matchErr.StatusCode)
 	}
+	journalMatched(req, matchedID)
+
+	return hf.simulatedResponse(req, payload)
+}
+
+// spyRequest returns stored response when there is one, otherwise request is forwarded to the
+// destination. Responses from the destination are not saved.
+func (hf *Hoverfly) spyRequest(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
+	}
+
+	reqBody, err := extractRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, matchedID, matchErr := hf.RequestMatcher.MatchPayload(req)
+	if matchErr == nil {
+		journalMatched(req, matchedID)
+		return hf.simulatedResponse(req, payload), nil
+	}
+	journalMissed(req, matchErr.Error())
+
+	log.WithFields(log.Fields{
+		"mode":        SpyMode,
+		"path":        req.URL.Path,
+		"rawQuery":    req.URL.RawQuery,
+		"method":      req.Method,
+		"destination": req.Host,
+	}).Info("no stored response found, forwarding request")
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	_, resp, _, err := hf.doRequest(req)
+	return resp, err
+}
+
+// simulatedResponse applies middleware and response delays to stored payload, recorded latency is
+// replayed when no response delay is configured for the request. Captured upstream errors are reproduced.
+func (hf *Hoverfly) simulatedResponse(req *http.Request, payload *models.Payload) *http.Response {
+	if payload.Error != nil {
+		return hf.replayUpstreamError(req, payload)
+	}
 
 	c := NewConstructor(req, *payload)
 	if hf.Cfg.Middleware != "" {
-		_ = c.ApplyMiddleware(hf.Cfg.Middleware)
+		_ = applyMiddleware(req, c, hf.Cfg.Middleware)
 	}
 
-	respDelay := hf.ResponseDelays.GetDelay(req.URL.String(), req.Method)
-	if respDelay != nil {
-		respDelay.Execute()
+	if !hf.applyResponseDelay(req) {
+		hf.replayLatency(req, payload)
 	}
 
-	return c.ReconstructResponse()
+	resp := c.ReconstructResponse()
+	hf.throttleResponse(req, resp, payload.Throttle)
+	return resp
 }
 
 // modifyRequestResponse modifies outgoing request and then modifies incoming response, neither request nor response
@@ -438,8 +631,9 @@ func (hf *Hoverfly) modifyRequestResponse(req *http.Request, middleware string)
 		return nil, err
 	}
 
-	// modifying request
-	req, resp, err := hf.doRequest(req)
+	// modifying request, reconstructed request doesn't carry journal entry of the original one
+	journalReq := req
+	req, resp, _, err := hf.doRequest(req)
 
 	if err != nil {
 		return nil, err
@@ -466,7 +660,7 @@ func (hf *Hoverfly) modifyRequestResponse(req *http.Request, middleware string)
 
 	c := NewConstructor(req, payload)
 	// applying middleware to modify response
-	err = c.ApplyMiddleware(middleware)
+	err = applyMiddleware(journalReq, c, middleware)
 
 	if err != nil {
 		return nil, err
@@ -493,7 +687,8 @@ func (hf *Hoverfly) modifyRequestResponse(req *http.Request, middleware string)
 }
 
 // save gets request fingerprint, extracts request body, status code and headers, then saves it to cache
-func (hf *Hoverfly) save(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) {
+// together with capture metadata
+func (hf *Hoverfly) save(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, metadata *models.CaptureMetadata) {
 
 	if resp == nil {
 		resp = emptyResp
@@ -504,49 +699,49 @@ func (hf *Hoverfly) save(req *http.Request, reqBody []byte, resp *http.Response,
 			Headers: resp.Header,
 		}
 
-		requestObj := models.RequestDetails{
-			Path:        req.URL.Path,
-			Method:      req.Method,
-			Destination: req.Host,
-			Scheme:      req.URL.Scheme,
-			Query:       req.URL.RawQuery,
-			Body:        string(reqBody),
-			Headers:     req.Header,
+		if metadata != nil {
+			metadata.TLS = models.NewTLSDetails(resp.TLS)
 		}
 
-		payload := models.Payload{
+		hf.capturePayload(&models.Payload{
 			Response: responseObj,
-			Request:  requestObj,
-		}
+			Request:  newCapturedRequestDetails(req, reqBody),
+			Metadata: metadata,
+		})
+	}
+}
 
-		err := hf.RequestMatcher.SavePayload(&payload)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"error": err.Error(),
-			}).Error("Failed to save payload")
-		}
+func newCapturedRequestDetails(req *http.Request, reqBody []byte) models.RequestDetails {
+	return models.RequestDetails{
+		Path:        req.URL.Path,
+		Method:      req.Method,
+		Destination: req.Host,
+		Scheme:      req.URL.Scheme,
+		Query:       req.URL.RawQuery,
+		Body:        string(reqBody),
+		Headers:     req.Header,
+	}
+}
 
-		bts, err := payload.Encode()
-		if err != nil {
-			log.WithFields(log.Fields{
-				"error": err.Error(),
-			}).Error("Failed to serialize payload")
-		} else {
-			// hook
-			var en Entry
-			en.ActionType = ActionTypeRequestCaptured
-			en.Message = "captured"
-			en.Time = time.Now()
-			en.Data = bts
-
-			if err := hf.Hooks.Fire(ActionTypeRequestCaptured, &en); err != nil {
-				log.WithFields(log.Fields{
-					"error":      err.Error(),
-					"message":    en.Message,
-					"actionType": ActionTypeRequestCaptured,
-				}).Error("failed to fire hook")
-			}
-		}
+// capturePayload - saves payload according to capture policy and fires hooks
+func (hf *Hoverfly) capturePayload(payload *models.Payload) {
+	// secrets are redacted before payload is saved, hooks get redacted payload as well
+	saved, err := hf.RequestMatcher.CapturePayload(payload)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to save payload")
+	}
+	if !saved {
+		return
+	}
 
+	bts, err := payload.Encode()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to serialize payload")
+	} else {
+		hf.fireHook(ActionTypeRequestCaptured, "captured", bts)
 	}
 }
@@ -144,6 +144,48 @@ func TestProcessModifyRequest(t *testing.T) {
 	Expect(newResp.StatusCode).To(Equal(http.StatusAccepted))
 }
 
+func TestProcessSpyRequest_ReturnsStoredResponse(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	r, err := http.NewRequest("GET", "http://somehost.com", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	dbClient.processRequest(r)
+
+	// changing what the destination returns, stored response should be used
+	server.Close()
+
+	dbClient.Cfg.SetMode(SpyMode)
+	_, resp := dbClient.processRequest(r)
+
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+}
+
+func TestProcessSpyRequest_ForwardsUnmatchedRequestWithoutCapturing(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	r, err := http.NewRequest("POST", "http://somehost.com", ioutil.NopCloser(bytes.NewBufferString("body")))
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SpyMode)
+	_, resp := dbClient.processRequest(r)
+
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+
+	count, err := dbClient.RequestCache.RecordsCount()
+	Expect(err).To(BeNil())
+	Expect(count).To(Equal(0))
+}
+
 func TestURLToStringWorksAsExpected(t *testing.T) {
 	RegisterTestingT(t)
 
@@ -343,3 +385,35 @@ func TestDelayNotAppliedToFailedModifyRequest(t *testing.T) {
 
 	Expect(stub.gotDelays).To(Equal(0))
 }
+
+func TestAppliedResponseDelaysAreRecordedInMetrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/delayed", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: "delayed"},
+	})
+	Expect(err).To(BeNil())
+	dbClient.UpdateResponseDelays(models.ResponseDelayList{{
+		UrlPattern:   "somehost.com",
+		Distribution: models.DelayDistributionUniform,
+		Min:          10,
+		Max:          20,
+	}})
+
+	r, err := http.NewRequest("GET", "http://somehost.com/delayed", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+	delays := dbClient.Counter.Flush().Histograms["delays"]
+	Expect(delays.Count).To(Equal(int64(1)))
+	Expect(delays.Min).To(BeNumerically(">=", 10))
+	Expect(delays.Max).To(BeNumerically("<=", 20))
+}
@@ -8,7 +8,6 @@ import (
 	"path"
 	"regexp"
 	"strings"
-	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/SpectoLabs/hoverfly/core/models"
@@ -96,7 +95,7 @@ func (hf *Hoverfly) ImportFromDisk(path string) error {
 		return fmt.Errorf("Got error while parsing payloads file, error %s", err.Error())
 	}
 
-	return hf.ImportPayloads(requests.Data)
+	return hf.ImportPayloadViewData(requests)
 }
 
 // ImportFromURL - takes one string value and tries connect to a remote server, then parse response body into
@@ -116,7 +115,7 @@ func (hf *Hoverfly) ImportFromURL(url string) error {
 		return fmt.Errorf("Got error while parsing payloads, error %s", err.Error())
 	}
 
-	return hf.ImportPayloads(requests.Data)
+	return hf.ImportPayloadViewData(requests)
 }
 
 func isJSON(s string) bool {
@@ -125,6 +124,24 @@ func isJSON(s string) bool {
 
 }
 
+// ImportPayloadViewData - applies fingerprint policy and redaction rules exported with the simulation,
+// if there are any, and saves given payloads into the database
+func (hf *Hoverfly) ImportPayloadViewData(data views.PayloadViewData) error {
+	if data.FingerprintPolicy != nil {
+		if err := hf.SetFingerprintPolicy(models.NewFingerprintPolicyFromView(*data.FingerprintPolicy)); err != nil {
+			return err
+		}
+	}
+	if data.RedactionRules != nil {
+		rules, err := models.NewRedactionRulesFromView(*data.RedactionRules)
+		if err != nil {
+			return err
+		}
+		hf.SetRedactionRules(rules)
+	}
+	return hf.ImportPayloads(data.Data)
+}
+
 // ImportPayloads - a function to save given payloads into the database.
 func (hf *Hoverfly) ImportPayloads(payloads []views.PayloadView) error {
 	if len(payloads) > 0 {
@@ -156,20 +173,7 @@ func (hf *Hoverfly) ImportPayloads(payloads []views.PayloadView) error {
 				}).Error("Failed to encode payload")
 				failed++
 			} else {
-				// hook
-				var en Entry
-				en.ActionType = ActionTypeRequestCaptured
-				en.Message = "imported"
-				en.Time = time.Now()
-				en.Data = bts
-
-				if err := hf.Hooks.Fire(ActionTypeRequestCaptured, &en); err != nil {
-					log.WithFields(log.Fields{
-						"error":      err.Error(),
-						"message":    en.Message,
-						"actionType": ActionTypeRequestCaptured,
-					}).Error("failed to fire hook")
-				}
+				hf.fireHook(ActionTypeRequestCaptured, "imported", bts)
 
 				err := hf.RequestMatcher.SavePayload(&pl)
 				if err != nil {
@@ -0,0 +1,135 @@
+package hoverfly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+)
+
+// journalEntryKey - context key of the journal entry of the request being processed
+type journalEntryKey struct{}
+
+// withJournalEntry - returns request carrying new journal entry, so what happens to the request can be
+// recorded while it is processed. Body is read into memory, so the request can be verified later.
+// Secrets are redacted the same way they are in captured payloads.
+func (hf *Hoverfly) withJournalEntry(req *http.Request) (*http.Request, *models.JournalEntry) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = extractRequestBody(req)
+	}
+
+	headers := make(map[string][]string)
+	for name, values := range req.Header {
+		headers[name] = append([]string(nil), values...)
+	}
+
+	request := models.RequestDetails{
+		Query:   req.URL.RawQuery,
+		Headers: headers,
+		Body:    string(body),
+	}
+	hf.GetRedactionRules().RedactRequest(&request)
+
+	entry := &models.JournalEntry{
+		Time:        time.Now(),
+		Mode:        hf.Cfg.GetMode(),
+		Method:      req.Method,
+		Scheme:      req.URL.Scheme,
+		Destination: req.Host,
+		Path:        req.URL.Path,
+		Query:       request.Query,
+		Headers:     request.Headers,
+		Body:        request.Body,
+	}
+	return req.WithContext(context.WithValue(req.Context(), journalEntryKey{}, entry)), entry
+}
+
+// journalEntry - returns journal entry of the request, entry that isn't journaled when request has none
+func journalEntry(req *http.Request) *models.JournalEntry {
+	if entry, ok := req.Context().Value(journalEntryKey{}).(*models.JournalEntry); ok {
+		return entry
+	}
+	return &models.JournalEntry{}
+}
+
+// journal - completes journal entry with the response and adds it to the journal
+func (hf *Hoverfly) journal(entry *models.JournalEntry, resp *http.Response) {
+	entry.Latency = time.Since(entry.Time)
+	if resp != nil {
+		entry.Status = resp.StatusCode
+	}
+	hf.Journal.Add(*entry)
+}
+
+// journalMatched - records ID of the stored response found for the request
+func journalMatched(req *http.Request, matchedID string) {
+	entry := journalEntry(req)
+	entry.Match = models.JournalMatched
+	entry.MatchedID = matchedID
+}
+
+// journalMissed - records why no stored response was found for the request
+func journalMissed(req *http.Request, reason string) {
+	entry := journalEntry(req)
+	entry.Match = models.JournalMissed
+	entry.MissReason = reason
+}
+
+// applyMiddleware - applies middleware to the payload of given constructor, time spent in middleware is
+// journaled for the request
+func applyMiddleware(req *http.Request, c *Constructor, middleware string) error {
+	start := time.Now()
+	err := c.ApplyMiddleware(middleware)
+	journalEntry(req).MiddlewareTime += time.Since(start)
+	return err
+}
+
+// NewJournalFilterFromQuery - creates journal filter from query parameters. Time range is given by 'from'
+// and 'to' in RFC3339 format, 'destination' and 'path' are regular expressions, 'status' is either a
+// single status or a range such as '500-599' and 'match' is either 'matched' or 'missed'.
+func NewJournalFilterFromQuery(query url.Values) (*models.JournalFilter, error) {
+	var from, to time.Time
+	var err error
+
+	if value := query.Get("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, fmt.Errorf("journal 'from' must be RFC3339 time, got '%s'", value)
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, fmt.Errorf("journal 'to' must be RFC3339 time, got '%s'", value)
+		}
+	}
+
+	statusFrom, statusTo := 0, 0
+	if value := query.Get("status"); value != "" {
+		bounds := strings.SplitN(value, "-", 2)
+		statusFrom, err = strconv.Atoi(bounds[0])
+		statusTo = statusFrom
+		if err == nil && len(bounds) == 2 {
+			statusTo, err = strconv.Atoi(bounds[1])
+		}
+		if err != nil || statusFrom <= 0 {
+			return nil, fmt.Errorf("journal 'status' must be a status or a range of statuses, got '%s'", value)
+		}
+	}
+
+	return models.NewJournalFilter(from, to, query.Get("destination"), query.Get("path"), statusFrom, statusTo, query.Get("match"))
+}
+
+// ClearJournal - removes all journal entries
+func (hf *Hoverfly) ClearJournal() error {
+	err := hf.Journal.Clear()
+	if err == nil {
+		log.Info("journal cleared")
+	}
+	return err
+}
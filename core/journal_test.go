@@ -0,0 +1,177 @@
+package hoverfly
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/matching"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestProcessRequest_JournalsMatchesAndMisses(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/found", Method: "GET", Destination: "somehost.com", Scheme: "http", Query: "q=1"},
+		Response: models.ResponseDetails{Status: 201, Body: "found"},
+		Metadata: &models.CaptureMetadata{ID: "capture-1"},
+	})
+	Expect(err).To(BeNil())
+	dbClient.UpdateResponseDelays(models.ResponseDelayList{{UrlPattern: "found", Delay: 10}})
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	for _, path := range []string{"/found", "/missing"} {
+		r, err := http.NewRequest("GET", "http://somehost.com"+path+"?q=1", nil)
+		Expect(err).To(BeNil())
+		dbClient.processRequest(r)
+	}
+
+	entries := dbClient.Journal.Entries(nil)
+	Expect(entries).To(HaveLen(2))
+
+	Expect(entries[0].Mode).To(Equal(SimulateMode))
+	Expect(entries[0].Destination).To(Equal("somehost.com"))
+	Expect(entries[0].Path).To(Equal("/found"))
+	Expect(entries[0].Query).To(Equal("q=1"))
+	Expect(entries[0].Match).To(Equal(models.JournalMatched))
+	Expect(entries[0].MatchedID).To(Equal("capture-1"))
+	Expect(entries[0].Status).To(Equal(201))
+	Expect(entries[0].Delay).To(Equal(10 * time.Millisecond))
+	Expect(entries[0].Latency >= 10*time.Millisecond).To(BeTrue())
+
+	Expect(entries[1].Match).To(Equal(models.JournalMissed))
+	Expect(entries[1].MissReason).ToNot(BeEmpty())
+	Expect(entries[1].Status).To(Equal(412))
+	Expect(entries[1].Delay).To(Equal(time.Duration(0)))
+}
+
+func TestProcessRequest_JournalsMatchedTemplate(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	other, path := "/other", "/template"
+	dbClient.RequestMatcher.TemplateStore = append(dbClient.RequestMatcher.TemplateStore,
+		matching.RequestTemplatePayload{RequestTemplate: matching.RequestTemplate{Path: &other}, Response: models.ResponseDetails{Status: 200}},
+		matching.RequestTemplatePayload{RequestTemplate: matching.RequestTemplate{Path: &path}, Response: models.ResponseDetails{Status: 200}},
+	)
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	r, err := http.NewRequest("GET", "http://somehost.com/template", nil)
+	Expect(err).To(BeNil())
+	dbClient.processRequest(r)
+
+	entries := dbClient.Journal.Entries(nil)
+	Expect(entries).To(HaveLen(1))
+	Expect(entries[0].MatchedID).To(Equal("template-1"))
+}
+
+func TestNewJournalFilterFromQuery(t *testing.T) {
+	RegisterTestingT(t)
+
+	filter, err := NewJournalFilterFromQuery(url.Values{
+		"from":   []string{"2016-12-01T10:00:00Z"},
+		"status": []string{"500-599"},
+		"match":  []string{"missed"},
+	})
+	Expect(err).To(BeNil())
+	Expect(filter.From.Equal(time.Date(2016, 12, 1, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+	Expect(filter.To.IsZero()).To(BeTrue())
+	Expect(filter.StatusFrom).To(Equal(500))
+	Expect(filter.StatusTo).To(Equal(599))
+	Expect(filter.Match).To(Equal(models.JournalMissed))
+
+	filter, err = NewJournalFilterFromQuery(url.Values{"status": []string{"404"}})
+	Expect(err).To(BeNil())
+	Expect(filter.StatusFrom).To(Equal(404))
+	Expect(filter.StatusTo).To(Equal(404))
+
+	_, err = NewJournalFilterFromQuery(url.Values{"to": []string{"yesterday"}})
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewJournalFilterFromQuery(url.Values{"status": []string{"5xx"}})
+	Expect(err).ToNot(BeNil())
+}
+
+func TestJournalHandlers(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	for _, host := range []string{"first.com", "second.com"} {
+		r, err := http.NewRequest("GET", "http://"+host+"/path", nil)
+		Expect(err).To(BeNil())
+		dbClient.processRequest(r)
+	}
+
+	req, err := http.NewRequest("GET", "/api/journal?destination=^second&status=201", nil)
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	body, err := ioutil.ReadAll(rec.Body)
+	Expect(err).To(BeNil())
+	var view views.JournalView
+	Expect(json.Unmarshal(body, &view)).To(BeNil())
+	Expect(view.Data).To(HaveLen(1))
+	Expect(view.Data[0].Destination).To(Equal("second.com"))
+	Expect(view.Data[0].Mode).To(Equal(CaptureMode))
+	Expect(view.Data[0].Match).To(BeEmpty())
+
+	req, err = http.NewRequest("GET", "/api/journal?match=sometimes", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+	req, err = http.NewRequest("DELETE", "/api/journal", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.Journal.Entries(nil)).To(BeEmpty())
+}
+
+func TestProcessRequest_JournalsRedactedRequest(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	rules, err := models.NewRedactionRules([]string{"Authorization"}, []string{"api_key"}, nil, []string{`"password":"([^"]*)"`}, "")
+	Expect(err).To(BeNil())
+	dbClient.SetRedactionRules(rules)
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	r, err := http.NewRequest("POST", "http://somehost.com/login?api_key=secret&page=1", strings.NewReader(`{"password":"hunter2"}`))
+	Expect(err).To(BeNil())
+	r.Header.Set("Authorization", "Bearer secret")
+	dbClient.processRequest(r)
+
+	entries := dbClient.Journal.Entries(nil)
+	Expect(entries).To(HaveLen(1))
+	Expect(entries[0].Headers["Authorization"]).To(Equal([]string{models.DefaultRedactionPlaceholder}))
+	Expect(entries[0].Query).ToNot(ContainSubstring("secret"))
+	Expect(entries[0].Query).To(ContainSubstring("page=1"))
+	Expect(entries[0].Body).ToNot(ContainSubstring("hunter2"))
+}
@@ -0,0 +1,94 @@
+package hoverfly
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// DefaultLatencyFactor - recorded latency is replayed as it was measured
+const DefaultLatencyFactor = 1.0
+
+// LatencyReplay - when enabled, simulated responses are returned after the upstream latency recorded
+// during capture multiplied by Factor. Response delays configured for the request take precedence.
+type LatencyReplay struct {
+	Enabled bool
+	Factor  float64
+}
+
+func NewLatencyReplayFromView(data views.LatencyReplayView) LatencyReplay {
+	factor := data.Factor
+	if factor == 0 {
+		factor = DefaultLatencyFactor
+	}
+	return LatencyReplay{Enabled: data.Enabled, Factor: factor}
+}
+
+func (l LatencyReplay) ConvertToLatencyReplayView() views.LatencyReplayView {
+	return views.LatencyReplayView{Enabled: l.Enabled, Factor: l.Factor}
+}
+
+// Validate - factor has to be positive
+func (l LatencyReplay) Validate() error {
+	if l.Factor <= 0 {
+		return fmt.Errorf("latency factor has to be greater than 0, got %v", l.Factor)
+	}
+	return nil
+}
+
+// Delay - returns how long response for given payload should be delayed, recorded latency doesn't include
+// time spent in middleware during capture
+func (l LatencyReplay) Delay(payload *models.Payload) time.Duration {
+	if !l.Enabled || payload.Metadata == nil {
+		return 0
+	}
+	return time.Duration(float64(payload.Metadata.Latency) * l.Factor)
+}
+
+// GetLatencyReplay - returns current latency replay settings
+func (hf *Hoverfly) GetLatencyReplay() LatencyReplay {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+
+	if hf.latencyReplay.Factor == 0 {
+		return LatencyReplay{Enabled: hf.latencyReplay.Enabled, Factor: DefaultLatencyFactor}
+	}
+	return hf.latencyReplay
+}
+
+// SetLatencyReplay - enables or disables replaying of recorded latency
+func (hf *Hoverfly) SetLatencyReplay(replay LatencyReplay) error {
+	if err := replay.Validate(); err != nil {
+		return err
+	}
+
+	hf.mu.Lock()
+	hf.latencyReplay = replay
+	hf.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"enabled": replay.Enabled,
+		"factor":  replay.Factor,
+	}).Info("latency replay updated")
+
+	return nil
+}
+
+// replayLatency - waits for recorded latency of given payload when latency replay is enabled, the wait
+// is journaled as delay of the request
+func (hf *Hoverfly) replayLatency(req *http.Request, payload *models.Payload) {
+	delay := hf.GetLatencyReplay().Delay(payload)
+	if delay <= 0 {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"delay": delay.String(),
+	}).Debug("Pausing before sending the response to replay recorded latency")
+	time.Sleep(delay)
+	journalEntry(req).Delay += delay
+}
@@ -0,0 +1,133 @@
+package hoverfly
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func saveWithLatency(dbClient *Hoverfly, latency time.Duration) {
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/slow", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: "slow"},
+		Metadata: &models.CaptureMetadata{Latency: latency},
+	})
+	Expect(err).To(BeNil())
+}
+
+func timeSimulatedRequest(dbClient *Hoverfly) time.Duration {
+	r, err := http.NewRequest("GET", "http://somehost.com/slow", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	start := time.Now()
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	return time.Since(start)
+}
+
+func TestLatencyReplay_Delay(t *testing.T) {
+	RegisterTestingT(t)
+
+	payload := &models.Payload{Metadata: &models.CaptureMetadata{Latency: 100 * time.Millisecond}}
+
+	Expect(LatencyReplay{Factor: 1}.Delay(payload)).To(Equal(time.Duration(0)))
+	Expect(LatencyReplay{Enabled: true, Factor: 1}.Delay(payload)).To(Equal(100 * time.Millisecond))
+	Expect(LatencyReplay{Enabled: true, Factor: 0.5}.Delay(payload)).To(Equal(50 * time.Millisecond))
+	Expect(LatencyReplay{Enabled: true, Factor: 1}.Delay(&models.Payload{})).To(Equal(time.Duration(0)))
+}
+
+func TestSimulateReplaysRecordedLatency(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	saveWithLatency(dbClient, 100*time.Millisecond)
+
+	Expect(timeSimulatedRequest(dbClient) < 100*time.Millisecond).To(BeTrue())
+
+	err := dbClient.SetLatencyReplay(LatencyReplay{Enabled: true, Factor: 2})
+	Expect(err).To(BeNil())
+	Expect(timeSimulatedRequest(dbClient) >= 200*time.Millisecond).To(BeTrue())
+}
+
+func TestRecordedLatencyExcludesMiddleware(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	middleware, err := ioutil.TempFile("", "slow_middleware")
+	Expect(err).To(BeNil())
+	defer os.Remove(middleware.Name())
+	_, err = middleware.WriteString("#!/bin/sh\nsleep 0.3\ncat\n")
+	Expect(err).To(BeNil())
+	Expect(middleware.Close()).To(BeNil())
+	Expect(os.Chmod(middleware.Name(), 0755)).To(BeNil())
+	dbClient.Cfg.Middleware = middleware.Name()
+
+	r, err := http.NewRequest("GET", "http://somehost.com/slow", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	dbClient.processRequest(r)
+
+	values, err := dbClient.RequestCache.GetAllValues()
+	Expect(err).To(BeNil())
+	Expect(values).To(HaveLen(1))
+	payload, err := models.NewPayloadFromBytes(values[0])
+	Expect(err).To(BeNil())
+	Expect(payload.Metadata.Latency < 300*time.Millisecond).To(BeTrue())
+
+	dbClient.Cfg.Middleware = ""
+	err = dbClient.SetLatencyReplay(LatencyReplay{Enabled: true, Factor: 1})
+	Expect(err).To(BeNil())
+	Expect(timeSimulatedRequest(dbClient) < 300*time.Millisecond).To(BeTrue())
+}
+
+func TestResponseDelaysTakePrecedenceOverRecordedLatency(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	saveWithLatency(dbClient, time.Second)
+	dbClient.UpdateResponseDelays(models.ResponseDelayList{{UrlPattern: "somehost.com", Delay: 10}})
+
+	err := dbClient.SetLatencyReplay(LatencyReplay{Enabled: true, Factor: 1})
+	Expect(err).To(BeNil())
+	Expect(timeSimulatedRequest(dbClient) < time.Second).To(BeTrue())
+}
+
+func TestUpdateLatencyReplayHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/latency-replay", bytes.NewBufferString(`{"enabled": true, "factor": -1}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+	Expect(dbClient.GetLatencyReplay().Enabled).To(BeFalse())
+
+	req, err = http.NewRequest("PUT", "/api/latency-replay", bytes.NewBufferString(`{"enabled": true}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetLatencyReplay()).To(Equal(LatencyReplay{Enabled: true, Factor: DefaultLatencyFactor}))
+}
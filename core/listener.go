@@ -12,6 +12,9 @@ import (
 type StoppableListener struct {
 	*net.TCPListener
 	stop chan int
+
+	// conns - when set, accepted connections are tracked so faults can be injected into them
+	conns *clientConns
 }
 
 // NewStoppableListener returns new StoppableListener listener
@@ -51,6 +54,10 @@ func (sl *StoppableListener) Accept() (net.Conn, error) {
 			}
 		}
 
+		if err == nil && sl.conns != nil {
+			newConn = sl.conns.track(newConn)
+		}
+
 		return newConn, err
 	}
 }
@@ -0,0 +1,115 @@
+package matching
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+)
+
+// CapturePayload redacts secrets in given payload and saves it according to capture policy. Returns
+// false when the payload was not saved because the request was already captured.
+func (this *RequestMatcher) CapturePayload(payload *models.Payload) (bool, error) {
+	this.Redaction.Redact(payload)
+	key := this.payloadKey(payload)
+
+	switch this.CapturePolicy {
+	case models.CapturePolicyKeepFirst:
+		if _, err := this.RequestCache.Get([]byte(key)); err == nil {
+			log.WithFields(log.Fields{
+				"hashKey": key,
+			}).Debug("Request was already captured, keeping the first response")
+			return false, nil
+		}
+
+	case models.CapturePolicyAppend:
+		capturedAt := time.Now()
+		if payload.Metadata != nil {
+			capturedAt = payload.Metadata.CapturedAt
+		}
+		if err := this.appendCaptureHistory(key, payload, capturedAt); err != nil {
+			return false, err
+		}
+	}
+
+	return true, this.savePayload(key, payload)
+}
+
+// appendCaptureHistory adds payload to history of given key. Payload captured before history was
+// kept becomes the first entry so it can be promoted back. Only the last MaxCaptureHistorySize payloads are kept.
+func (this *RequestMatcher) appendCaptureHistory(key string, payload *models.Payload, capturedAt time.Time) error {
+	if this.HistoryCache == nil {
+		return fmt.Errorf("capture history is not available")
+	}
+
+	history, err := this.GetCaptureHistory(key)
+	if err != nil {
+		history = models.CaptureHistory{}
+		if active, err := this.GetActivePayload(key); err == nil {
+			entry := models.CaptureHistoryEntry{Payload: *active}
+			if active.Metadata != nil {
+				entry.CapturedAt = active.Metadata.CapturedAt
+			}
+			history = append(history, entry)
+		}
+	}
+
+	history = append(history, models.CaptureHistoryEntry{
+		CapturedAt: capturedAt,
+		Payload:    *payload,
+	})
+	if len(history) > models.MaxCaptureHistorySize {
+		history = history[len(history)-models.MaxCaptureHistorySize:]
+	}
+
+	return this.SaveCaptureHistory(key, history)
+}
+
+// GetCaptureHistory returns every payload captured for given key
+func (this *RequestMatcher) GetCaptureHistory(key string) (models.CaptureHistory, error) {
+	if this.HistoryCache == nil {
+		return nil, fmt.Errorf("capture history is not available")
+	}
+
+	bts, err := this.HistoryCache.Get([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return models.NewCaptureHistoryFromBytes(bts)
+}
+
+// SaveCaptureHistory stores history of given key
+func (this *RequestMatcher) SaveCaptureHistory(key string, history models.CaptureHistory) error {
+	bts, err := history.Encode()
+	if err != nil {
+		return err
+	}
+	return this.HistoryCache.Set([]byte(key), bts)
+}
+
+// PromoteCapture makes history entry with given index the active payload of its key
+func (this *RequestMatcher) PromoteCapture(key string, index int) error {
+	history, err := this.GetCaptureHistory(key)
+	if err != nil {
+		return err
+	}
+
+	if index < 0 || index >= len(history) {
+		return &MatchingError{
+			StatusCode:  422,
+			Description: fmt.Sprintf("history of '%s' has no entry %d", key, index),
+		}
+	}
+
+	return this.savePayload(key, &history[index].Payload)
+}
+
+// GetActivePayload returns payload that is currently stored for given key
+func (this *RequestMatcher) GetActivePayload(key string) (*models.Payload, error) {
+	bts, err := this.RequestCache.Get([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPayloadFromBytes(bts)
+}
@@ -1,6 +1,7 @@
 package matching
 
 import (
+	"fmt"
 	"github.com/SpectoLabs/hoverfly/core/cache"
 	"net/http"
 	log "github.com/Sirupsen/logrus"
@@ -13,11 +14,24 @@ type RequestMatcher struct {
 	RequestCache	cache.Cache
 	TemplateStore	RequestTemplateStore
 	Webserver	*bool
+	FingerprintPolicy	*models.FingerprintPolicy
+	Redaction	*models.RedactionRules
+	HistoryCache	cache.Cache
+	CapturePolicy	string
+	Coverage	*models.Coverage
 
 }
 
 // getResponse returns stored response from cache
 func (this *RequestMatcher) GetPayload(req *http.Request) (*models.Payload, *MatchingError) {
+	payload, _, err := this.MatchPayload(req)
+	return payload, err
+}
+
+// MatchPayload returns stored response along with ID of what it was matched to. Captured payloads
+// are identified by their capture ID or by their key when they have none, templates by "template-"
+// followed by their index.
+func (this *RequestMatcher) MatchPayload(req *http.Request) (*models.Payload, string, *MatchingError) {
 
 	if req.Body == nil {
 		req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte("")))
@@ -31,7 +45,7 @@ func (this *RequestMatcher) GetPayload(req *http.Request) (*models.Payload, *Mat
 		}).Error("Got error when reading request body")
 	}
 
-	key := GetRequestFingerprint(req, reqBody, *this.Webserver)
+	key := this.fingerprint(req, reqBody)
 
 	payloadBts, err := this.RequestCache.Get([]byte(key))
 
@@ -45,7 +59,7 @@ func (this *RequestMatcher) GetPayload(req *http.Request) (*models.Payload, *Mat
 			"method":      req.Method,
 		}).Warn("Failed to retrieve response from cache")
 
-		payload, err := this.TemplateStore.GetPayload(req, reqBody, *this.Webserver)
+		payload, index, err := this.TemplateStore.MatchPayload(req, reqBody, *this.Webserver)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"key":         key,
@@ -56,7 +70,7 @@ func (this *RequestMatcher) GetPayload(req *http.Request) (*models.Payload, *Mat
 				"method":      req.Method,
 			}).Warn("Failed to find matching request template from template store")
 
-			return nil, &MatchingError{
+			return nil, "", &MatchingError{
 				StatusCode: 412,
 				Description: "Could not find recorded request, please record it first!",
 			}
@@ -68,7 +82,8 @@ func (this *RequestMatcher) GetPayload(req *http.Request) (*models.Payload, *Mat
 			"destination": req.Host,
 			"method":      req.Method,
 		}).Info("Found template matching request from template store")
-		return payload, nil
+		this.Coverage.HitTemplate(index)
+		return payload, fmt.Sprintf("template-%d", index), nil
 	}
 
 	// getting cache response
@@ -79,7 +94,7 @@ func (this *RequestMatcher) GetPayload(req *http.Request) (*models.Payload, *Mat
 			"value": string(payloadBts),
 			"key":   key,
 		}).Error("Failed to decode payload")
-		return nil, &MatchingError{
+		return nil, "", &MatchingError{
 			StatusCode: 500,
 			Description: "Failed to decode payload",
 		}
@@ -93,19 +108,27 @@ func (this *RequestMatcher) GetPayload(req *http.Request) (*models.Payload, *Mat
 		"destination": req.Host,
 		"status":      payload.Response.Status,
 	}).Info("Payload found from cache")
+	this.Coverage.HitRecord(key)
 
-	return payload, nil
+	if payload.Metadata != nil && payload.Metadata.ID != "" {
+		return payload, payload.Metadata.ID, nil
+	}
+	return payload, key, nil
 }
 
+// SavePayload redacts secrets in given payload and saves it to cache
 func (this *RequestMatcher) SavePayload(payload *models.Payload) (error) {
-	var key string
+	this.Redaction.Redact(payload)
 
-	if *this.Webserver {
-		key = payload.IdWithoutHost()
-	} else {
-		key = payload.Id()
-	}
+	return this.savePayload(this.payloadKey(payload), payload)
+}
+
+// payloadKey returns key under which given payload is stored
+func (this *RequestMatcher) payloadKey(payload *models.Payload) string {
+	return payload.Request.Fingerprint(this.FingerprintPolicy, !*this.Webserver)
+}
 
+func (this *RequestMatcher) savePayload(key string, payload *models.Payload) error {
 	log.WithFields(log.Fields{
 		"path":          payload.Request.Path,
 		"rawQuery":      payload.Request.Query,
@@ -135,11 +158,27 @@ func (this MatchingError) Error() (string) {
 	return this.Description
 }
 
+// fingerprint returns hash of the request, secrets are redacted first so the request has
+// the same hash as its stored, redacted version
+func (this *RequestMatcher) fingerprint(req *http.Request, requestBody []byte) string {
+	r := newRequestDetails(req, requestBody)
+	this.Redaction.RedactRequest(&r)
+	return r.Fingerprint(this.FingerprintPolicy, !*this.Webserver)
+}
+
 // getRequestFingerprint returns request hash
 func GetRequestFingerprint(req *http.Request, requestBody []byte, webserver bool) string {
-	var r models.RequestDetails
+	return GetRequestFingerprintWithPolicy(req, requestBody, webserver, nil)
+}
+
+// GetRequestFingerprintWithPolicy returns request hash built according to given fingerprint policy
+func GetRequestFingerprintWithPolicy(req *http.Request, requestBody []byte, webserver bool, policy *models.FingerprintPolicy) string {
+	r := newRequestDetails(req, requestBody)
+	return r.Fingerprint(policy, !webserver)
+}
 
-	r = models.RequestDetails{
+func newRequestDetails(req *http.Request, requestBody []byte) models.RequestDetails {
+	return models.RequestDetails{
 		Path:        req.URL.Path,
 		Method:      req.Method,
 		Destination: req.Host,
@@ -147,10 +186,4 @@ func GetRequestFingerprint(req *http.Request, requestBody []byte, webserver bool
 		Body:        string(requestBody),
 		Headers:     req.Header,
 	}
-
-	if webserver {
-		return r.HashWithoutHost()
-	}
-
-	return r.Hash()
 }
\ No newline at end of file
@@ -17,11 +17,13 @@ type RequestTemplateStore []RequestTemplatePayload
 type RequestTemplatePayload struct {
 	RequestTemplate RequestTemplate        `json:"requestTemplate"`
 	Response        models.ResponseDetails `json:"response"`
+	Throttle        *models.Throttle       `json:"throttle,omitempty"`
 }
 
 type RequestTemplatePayloadView struct {
 	RequestTemplate RequestTemplate        `json:"requestTemplate"`
 	Response        views.ResponseDetailsView `json:"response"`
+	Throttle        *models.Throttle       `json:"throttle,omitempty"`
 }
 
 type RequestTemplatePayloadJson struct {
@@ -39,37 +41,31 @@ type RequestTemplate struct {
 }
 
 func(this *RequestTemplateStore) GetPayload(req *http.Request, reqBody []byte, webserver bool) (*models.Payload, error) {
+	payload, _, err := this.MatchPayload(req, reqBody, webserver)
+	return payload, err
+}
+
+// MatchPayload - returns payload of the first template matching the request and index of the template
+func(this *RequestTemplateStore) MatchPayload(req *http.Request, reqBody []byte, webserver bool) (*models.Payload, int, error) {
+	request := models.RequestDetails{
+		Path:        req.URL.Path,
+		Method:      req.Method,
+		Destination: req.Host,
+		Scheme:      req.URL.Scheme,
+		Query:       req.URL.RawQuery,
+		Body:        string(reqBody),
+		Headers:     req.Header,
+	}
+
 	// iterate through the request templates, looking for template to match request
-	for _, entry := range *this {
-		// TODO: not matching by default on URL and body - need to enable this
+	for i, entry := range *this {
 		// TODO: need to enable regex matches
-		// TODO: enable matching on scheme
-
-		if entry.RequestTemplate.Body != nil && *entry.RequestTemplate.Body == string(reqBody) {
-			continue
-		}
-		if (!webserver) {
-			if entry.RequestTemplate.Destination != nil && *entry.RequestTemplate.Destination != req.Host {
-				continue
-			}
-		}
-		if entry.RequestTemplate.Path != nil && *entry.RequestTemplate.Path != req.URL.Path {
-			continue
-		}
-		if entry.RequestTemplate.Query != nil && *entry.RequestTemplate.Query != req.URL.RawQuery {
-			continue
+		if entry.RequestTemplate.Matches(request, webserver) {
+			// return the first template to match
+			return &models.Payload{Response: entry.Response, Throttle: entry.Throttle}, i, nil
 		}
-		if !headerMatch(entry.RequestTemplate.Headers, req.Header) {
-			continue
-		}
-		if entry.RequestTemplate.Method != nil && *entry.RequestTemplate.Method != req.Method {
-			continue
-		}
-
-		// return the first template to match
-		return &models.Payload{Response: entry.Response}, nil
 	}
-	return nil, errors.New("No match found")
+	return nil, -1, errors.New("No match found")
 }
 
 // ImportPayloads - a function to save given payloads into the database.
@@ -77,6 +73,13 @@ func (this *RequestTemplateStore) ImportPayloads(payloadsView RequestTemplatePay
 	if len(*payloadsView.Data) > 0 {
 		// Convert PayloadView back to Payload for internal storage
 		payloads := payloadsView.ConvertToRequestTemplateStore()
+		for _, pl := range payloads {
+			if pl.Throttle != nil {
+				if err := pl.Throttle.Validate(); err != nil {
+					return err
+				}
+			}
+		}
 		for _, pl := range payloads {
 
 			//TODO: add hooks for concsistency with request import
@@ -97,6 +100,31 @@ func (this *RequestTemplateStore) Wipe() {
 	*this = RequestTemplateStore{}
 }
 
+// Matches - returns whether given request has every field that is set in the template, headers of the
+// template must all be present in the request. Requests received in webserver mode aren't addressed to
+// a destination, so destination and scheme of the template are ignored then.
+func(this *RequestTemplate) Matches(request models.RequestDetails, webserver bool) (bool) {
+	if this.Path != nil && *this.Path != request.Path {
+		return false
+	}
+	if this.Method != nil && *this.Method != request.Method {
+		return false
+	}
+	if !webserver && this.Destination != nil && *this.Destination != request.Destination {
+		return false
+	}
+	if !webserver && this.Scheme != nil && *this.Scheme != request.Scheme {
+		return false
+	}
+	if this.Query != nil && *this.Query != request.Query {
+		return false
+	}
+	if this.Body != nil && *this.Body != request.Body {
+		return false
+	}
+	return headerMatch(this.Headers, request.Headers)
+}
+
 /**
 Check keys and corresponding values in template headers are also present in request headers
  */
@@ -130,6 +158,7 @@ func(this *RequestTemplatePayload) ConvertToRequestTemplatePayloadView() (Reques
 	return RequestTemplatePayloadView{
 		RequestTemplate: this.RequestTemplate,
 		Response: this.Response.ConvertToResponseDetailsView(),
+		Throttle: this.Throttle,
 	}
 }
 
@@ -145,6 +174,7 @@ func(this *RequestTemplatePayloadView) ConvertToPayload() (RequestTemplatePayloa
 	return RequestTemplatePayload{
 		RequestTemplate: this.RequestTemplate,
 		Response: models.NewResponseDetialsFromResponseDetailsView(this.Response),
+		Throttle: this.Throttle,
 	}
 }
 
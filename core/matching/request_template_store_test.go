@@ -301,4 +301,61 @@ func TestAbleToMatchAnEmptyPathInAReasonableWay(t *testing.T) {
 	result, _ = store.GetPayload(r, nil, false)
 
 	Expect(result).To(BeNil())
-}
\ No newline at end of file
+}
+
+func TestMatchPayloadAgreesWithRequestTemplateMatches(t *testing.T) {
+	RegisterTestingT(t)
+
+	destination, scheme, body := "testhost.com", "https", "paid"
+	store := RequestTemplateStore{RequestTemplatePayload{
+		RequestTemplate: RequestTemplate{Destination: &destination, Scheme: &scheme, Body: &body},
+		Response:        models.ResponseDetails{Body: "test-body"},
+	}}
+
+	r, _ := http.NewRequest("POST", "https://testhost.com/payments", nil)
+	result, _ := store.GetPayload(r, []byte("paid"), false)
+	Expect(result.Response.Body).To(Equal("test-body"))
+
+	result, _ = store.GetPayload(r, []byte("refunded"), false)
+	Expect(result).To(BeNil())
+
+	r, _ = http.NewRequest("POST", "http://testhost.com/payments", nil)
+	result, _ = store.GetPayload(r, []byte("paid"), false)
+	Expect(result).To(BeNil())
+
+	// webserver requests aren't addressed to a destination
+	r, _ = http.NewRequest("POST", "/payments", nil)
+	r.Host = "localhost:8500"
+	result, _ = store.GetPayload(r, []byte("paid"), true)
+	Expect(result.Response.Body).To(Equal("test-body"))
+}
+
+func TestRequestTemplate_MatchesRequestDetails(t *testing.T) {
+	RegisterTestingT(t)
+
+	method, path, body := "POST", "/payments", "paid"
+	template := RequestTemplate{
+		Method:  &method,
+		Path:    &path,
+		Body:    &body,
+		Headers: map[string][]string{"Content-Type": []string{"text/plain"}},
+	}
+
+	request := models.RequestDetails{
+		Method:      "POST",
+		Path:        "/payments",
+		Destination: "payments.com",
+		Body:        "paid",
+		Headers:     map[string][]string{"Content-Type": []string{"text/plain"}, "Accept": []string{"*/*"}},
+	}
+	Expect(template.Matches(request, false)).To(BeTrue())
+
+	request.Body = "refunded"
+	Expect(template.Matches(request, false)).To(BeFalse())
+
+	request.Body = "paid"
+	request.Headers = map[string][]string{}
+	Expect(template.Matches(request, false)).To(BeFalse())
+
+	Expect((&RequestTemplate{}).Matches(request, false)).To(BeTrue())
+}
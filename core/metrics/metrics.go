@@ -4,12 +4,30 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/rcrowley/go-metrics"
 
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
-// CounterByMode - container for mode counters, registry and flush interval
+// DelaysHistogram - name of the histogram of response delays applied in milliseconds
+const DelaysHistogram = "delays"
+
+// FaultsCounterPrefix - prefix of counters of injected faults and chaos responses, followed by mode
+const FaultsCounterPrefix = "faults."
+
+// CounterByMode - container for mode counters, counters of injected faults, histogram of applied delays,
+// registry and flush interval. Request, match, middleware, upstream latency and delay metrics are only
+// exposed in Prometheus text format.
 type CounterByMode struct {
 	Counters      map[string]metrics.Counter
+	Faults        map[string]metrics.Counter
+	Delays        metrics.Histogram
+	Requests      *LabeledCounter
+	Matches       *LabeledCounter
+	Middleware    *DurationHistogram
+	Upstream      *DurationHistogram
+	DelaySeconds  *LabeledCounter
 	registry      metrics.Registry
 	flushInterval time.Duration
 }
@@ -19,15 +37,30 @@ func NewModeCounter(modes []string) *CounterByMode {
 
 	registry := metrics.NewRegistry()
 	counters := make(map[string]metrics.Counter)
+	faults := make(map[string]metrics.Counter)
 
 	for _, v := range modes {
 		counter := metrics.NewCounter()
 		counters[v] = counter
 		registry.GetOrRegister(v, counter)
+
+		faultCounter := metrics.NewCounter()
+		faults[v] = faultCounter
+		registry.GetOrRegister(FaultsCounterPrefix+v, faultCounter)
 	}
 
+	delays := metrics.NewHistogram(metrics.NewUniformSample(1028))
+	registry.GetOrRegister(DelaysHistogram, delays)
+
 	c := &CounterByMode{
 		Counters:      counters,
+		Faults:        faults,
+		Delays:        delays,
+		Requests:      NewLabeledCounter("hoverfly_requests_total", "Requests processed by Hoverfly by destination and response status.", "destination", "status"),
+		Matches:       NewLabeledCounter("hoverfly_matches_total", "Lookups of stored responses by outcome, matched or missed.", "outcome"),
+		Middleware:    NewDurationHistogram("hoverfly_middleware_duration_seconds", "Time spent executing middleware per request.", DefaultDurationBuckets),
+		Upstream:      NewDurationHistogram("hoverfly_upstream_latency_seconds", "Latency of requests forwarded to upstream services by destination.", DefaultDurationBuckets, "destination"),
+		DelaySeconds:  NewLabeledCounter("hoverfly_applied_delay_seconds_total", "Total of response delays applied by Hoverfly."),
 		registry:      registry,
 		flushInterval: 5 * time.Second,
 	}
@@ -37,9 +70,87 @@ func NewModeCounter(modes []string) *CounterByMode {
 	return c
 }
 
-// Count - counts requests based on mode
+// Count - counts requests based on mode, requests in modes without a counter are ignored
 func (c *CounterByMode) Count(mode string) {
-	c.Counters[mode].Inc(1)
+	if counter, ok := c.Counters[mode]; ok {
+		counter.Inc(1)
+	}
+}
+
+// CountFault - counts faults and error responses injected in given mode
+func (c *CounterByMode) CountFault(mode string) {
+	if c == nil {
+		return
+	}
+	if counter, ok := c.Faults[mode]; ok {
+		counter.Inc(1)
+	}
+}
+
+// RecordDelay - records response delay that was applied
+func (c *CounterByMode) RecordDelay(delay time.Duration) {
+	if c == nil || c.Delays == nil {
+		return
+	}
+	c.Delays.Update(int64(delay / time.Millisecond))
+	if c.DelaySeconds != nil {
+		c.DelaySeconds.Add(delay.Seconds())
+	}
+}
+
+// CountRequest - counts processed request by its destination and response status
+func (c *CounterByMode) CountRequest(destination string, status int) {
+	if c == nil || c.Requests == nil {
+		return
+	}
+	c.Requests.Add(1, destination, strconv.Itoa(status))
+}
+
+// CountMatch - counts lookup of stored response by its outcome
+func (c *CounterByMode) CountMatch(outcome string) {
+	if c == nil || c.Matches == nil {
+		return
+	}
+	c.Matches.Add(1, outcome)
+}
+
+// RecordMiddleware - records time spent executing middleware for one request
+func (c *CounterByMode) RecordMiddleware(duration time.Duration) {
+	if c == nil || c.Middleware == nil {
+		return
+	}
+	c.Middleware.Observe(duration)
+}
+
+// RecordUpstreamLatency - records latency of request forwarded to given destination
+func (c *CounterByMode) RecordUpstreamLatency(destination string, latency time.Duration) {
+	if c == nil || c.Upstream == nil {
+		return
+	}
+	c.Upstream.Observe(latency, destination)
+}
+
+// WritePrometheus - writes mode counters, fault counters and request metrics in Prometheus text format
+func (c *CounterByMode) WritePrometheus(w io.Writer) {
+	writeModeCounters(w, "hoverfly_mode_requests_total", "Requests processed by Hoverfly by mode.", c.Counters)
+	writeModeCounters(w, "hoverfly_faults_total", "Faults and error responses injected by Hoverfly by mode.", c.Faults)
+
+	c.Requests.WritePrometheus(w)
+	c.Matches.WritePrometheus(w)
+	c.Middleware.WritePrometheus(w)
+	c.Upstream.WritePrometheus(w)
+	c.DelaySeconds.WritePrometheus(w)
+
+	writeHeader(w, "hoverfly_applied_delays_total", "Response delays applied by Hoverfly.", "counter")
+	fmt.Fprintf(w, "hoverfly_applied_delays_total %d\n", c.Delays.Count())
+}
+
+func writeModeCounters(w io.Writer, name, help string, counters map[string]metrics.Counter) {
+	counter := NewLabeledCounter(name, help, "mode")
+	for mode, modeCounter := range counters {
+		counter.Add(float64(modeCounter.Count()), mode)
+	}
+	counter.WritePrometheus(w)
 }
 
 // Init initializes logging
@@ -47,16 +158,28 @@ func (c *CounterByMode) Init() {
 	go func() {
 		for _ = range time.Tick(c.flushInterval) {
 			m := c.Flush()
-			log.WithFields(log.Fields{"counters": m.Counters}).Info("hoverfly metrics")
+			log.WithFields(log.Fields{"counters": m.Counters, "histograms": m.Histograms}).Info("hoverfly metrics")
 		}
 	}()
 }
 
 // Stats - holds information about various system metrics like requests counts
 type Stats struct {
-	Counters    map[string]int64   `json:"counters"`
-	Gauges      map[string]int64   `json:"gauges,omitempty"`
-	GaugesFloat map[string]float64 `json:"gaugesFloat,omitempty"`
+	Counters    map[string]int64          `json:"counters"`
+	Gauges      map[string]int64          `json:"gauges,omitempty"`
+	GaugesFloat map[string]float64        `json:"gaugesFloat,omitempty"`
+	Histograms  map[string]HistogramStats `json:"histograms,omitempty"`
+}
+
+// HistogramStats - summary of sampled values
+type HistogramStats struct {
+	Count int64   `json:"count"`
+	Min   int64   `json:"min"`
+	Max   int64   `json:"max"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
 }
 
 // Flush gets current metrics from stats registry
@@ -65,6 +188,7 @@ func (c *CounterByMode) Flush() (h Stats) {
 	counters := make(map[string]int64)
 	gauges := make(map[string]int64)
 	gaugesFloat := make(map[string]float64)
+	histograms := make(map[string]HistogramStats)
 
 	c.registry.Each(func(name string, i interface{}) {
 		switch metric := i.(type) {
@@ -74,11 +198,24 @@ func (c *CounterByMode) Flush() (h Stats) {
 			gauges[name] = metric.Value()
 		case metrics.GaugeFloat64:
 			gaugesFloat[name] = metric.Value()
+		case metrics.Histogram:
+			snapshot := metric.Snapshot()
+			percentiles := snapshot.Percentiles([]float64{0.5, 0.95, 0.99})
+			histograms[name] = HistogramStats{
+				Count: snapshot.Count(),
+				Min:   snapshot.Min(),
+				Max:   snapshot.Max(),
+				Mean:  snapshot.Mean(),
+				P50:   percentiles[0],
+				P95:   percentiles[1],
+				P99:   percentiles[2],
+			}
 		}
 	})
 
 	h.Counters = counters
 	h.Gauges = gauges
 	h.GaugesFloat = gaugesFloat
+	h.Histograms = histograms
 	return
 }
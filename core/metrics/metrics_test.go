@@ -3,6 +3,7 @@ package metrics
 import (
 	. "github.com/onsi/gomega"
 	"testing"
+	"time"
 )
 
 func TestSimulateInc(t *testing.T) {
@@ -28,3 +29,32 @@ func TestFlush(t *testing.T) {
 
 	Expect(count).To(Equal(int64(1)))
 }
+
+func TestRecordDelay(t *testing.T) {
+	RegisterTestingT(t)
+	counter := NewModeCounter([]string{"name"})
+
+	counter.RecordDelay(100 * time.Millisecond)
+	counter.RecordDelay(300 * time.Millisecond)
+
+	fl := counter.Flush()
+
+	delays := fl.Histograms[DelaysHistogram]
+	Expect(delays.Count).To(Equal(int64(2)))
+	Expect(delays.Min).To(Equal(int64(100)))
+	Expect(delays.Max).To(Equal(int64(300)))
+	Expect(delays.Mean).To(Equal(200.0))
+}
+
+func TestCountFault(t *testing.T) {
+	RegisterTestingT(t)
+	counter := NewModeCounter([]string{"name"})
+
+	counter.CountFault("name")
+	counter.CountFault("unknown")
+
+	fl := counter.Flush()
+
+	Expect(fl.Counters[FaultsCounterPrefix+"name"]).To(Equal(int64(1)))
+	Expect(fl.Counters["name"]).To(Equal(int64(0)))
+}
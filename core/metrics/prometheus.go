@@ -0,0 +1,218 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDurationBuckets - upper bounds, in seconds, of buckets of duration histograms
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// LabeledCounter - counter with a separate value for every combination of label values, written in
+// Prometheus text format
+type LabeledCounter struct {
+	Name   string
+	Help   string
+	Labels []string
+
+	series map[string]*counterSeries
+	mu     sync.Mutex
+}
+
+type counterSeries struct {
+	labels []string
+	value  float64
+}
+
+// NewLabeledCounter - returns counter with given name and label names
+func NewLabeledCounter(name, help string, labels ...string) *LabeledCounter {
+	return &LabeledCounter{
+		Name:   name,
+		Help:   help,
+		Labels: labels,
+		series: make(map[string]*counterSeries),
+	}
+}
+
+// Add - adds value to the counter of given label values, label values are given in the order of label names
+func (c *LabeledCounter) Add(value float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := seriesKey(labelValues)
+	series, ok := c.series[key]
+	if !ok {
+		series = &counterSeries{labels: labelValues}
+		c.series[key] = series
+	}
+	series.value += value
+}
+
+// Value - returns value of the counter of given label values
+func (c *LabeledCounter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if series, ok := c.series[seriesKey(labelValues)]; ok {
+		return series.value
+	}
+	return 0
+}
+
+// WritePrometheus - writes all values of the counter in Prometheus text format
+func (c *LabeledCounter) WritePrometheus(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.Name, c.Help, "counter")
+	for _, key := range sortedSeriesKeys(c.series) {
+		series := c.series[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.Name, formatLabels(c.Labels, series.labels), formatValue(series.value))
+	}
+}
+
+// DurationHistogram - cumulative histogram of durations in seconds with a separate series for every
+// combination of label values, written in Prometheus text format
+type DurationHistogram struct {
+	Name    string
+	Help    string
+	Labels  []string
+	Buckets []float64
+
+	series map[string]*histogramSeries
+	mu     sync.Mutex
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewDurationHistogram - returns histogram with given name, bucket upper bounds in seconds and label names
+func NewDurationHistogram(name, help string, buckets []float64, labels ...string) *DurationHistogram {
+	return &DurationHistogram{
+		Name:    name,
+		Help:    help,
+		Labels:  labels,
+		Buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+}
+
+// Observe - records duration in the series of given label values
+func (h *DurationHistogram) Observe(duration time.Duration, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := seriesKey(labelValues)
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{labels: labelValues, counts: make([]uint64, len(h.Buckets))}
+		h.series[key] = series
+	}
+
+	seconds := duration.Seconds()
+	for i, bound := range h.Buckets {
+		if seconds <= bound {
+			series.counts[i]++
+			break
+		}
+	}
+	series.count++
+	series.sum += seconds
+}
+
+// Count - returns number of durations recorded in the series of given label values
+func (h *DurationHistogram) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if series, ok := h.series[seriesKey(labelValues)]; ok {
+		return series.count
+	}
+	return 0
+}
+
+// WritePrometheus - writes buckets, sum and count of every series in Prometheus text format
+func (h *DurationHistogram) WritePrometheus(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.Name, h.Help, "histogram")
+	bucketLabels := append(append([]string(nil), h.Labels...), "le")
+	for _, key := range sortedSeriesKeys(h.series) {
+		series := h.series[key]
+
+		var cumulative uint64
+		for i, bound := range h.Buckets {
+			cumulative += series.counts[i]
+			labels := append(append([]string(nil), series.labels...), formatValue(bound))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.Name, formatLabels(bucketLabels, labels), cumulative)
+		}
+		labels := append(append([]string(nil), series.labels...), "+Inf")
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.Name, formatLabels(bucketLabels, labels), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.Name, formatLabels(h.Labels, series.labels), formatValue(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.Name, formatLabels(h.Labels, series.labels), series.count)
+	}
+}
+
+// WritePrometheusGauge - writes gauge without labels in Prometheus text format
+func WritePrometheusGauge(w io.Writer, name, help string, value float64) {
+	writeHeader(w, name, help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", name, formatValue(value))
+}
+
+func writeHeader(w io.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// seriesKey - joins label values with a byte that can't appear in valid UTF-8
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortedSeriesKeys(series interface{}) []string {
+	var keys []string
+	switch s := series.(type) {
+	case map[string]*counterSeries:
+		for key := range s {
+			keys = append(keys, key)
+		}
+	case map[string]*histogramSeries:
+		for key := range s {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		var value string
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = name + `="` + labelValueReplacer.Replace(value) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
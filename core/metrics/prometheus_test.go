@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLabeledCounter_WritePrometheus(t *testing.T) {
+	RegisterTestingT(t)
+	counter := NewLabeledCounter("requests_total", "Requests.", "destination", "status")
+
+	counter.Add(1, "b.com", "200")
+	counter.Add(2, `a"\.com`, "404")
+	counter.Add(1, "b.com", "200")
+
+	Expect(counter.Value("b.com", "200")).To(Equal(float64(2)))
+	Expect(counter.Value("c.com", "200")).To(Equal(float64(0)))
+
+	var buf bytes.Buffer
+	counter.WritePrometheus(&buf)
+	Expect(buf.String()).To(Equal(`# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{destination="a\"\\.com",status="404"} 2
+requests_total{destination="b.com",status="200"} 2
+`))
+}
+
+func TestDurationHistogram_WritePrometheus(t *testing.T) {
+	RegisterTestingT(t)
+	histogram := NewDurationHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "destination")
+
+	histogram.Observe(50*time.Millisecond, "a.com")
+	histogram.Observe(500*time.Millisecond, "a.com")
+	histogram.Observe(2*time.Second, "a.com")
+
+	Expect(histogram.Count("a.com")).To(Equal(uint64(3)))
+
+	var buf bytes.Buffer
+	histogram.WritePrometheus(&buf)
+	Expect(buf.String()).To(Equal(`# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{destination="a.com",le="0.1"} 1
+latency_seconds_bucket{destination="a.com",le="1"} 2
+latency_seconds_bucket{destination="a.com",le="+Inf"} 3
+latency_seconds_sum{destination="a.com"} 2.55
+latency_seconds_count{destination="a.com"} 3
+`))
+}
+
+func TestCounterByMode_WritePrometheus(t *testing.T) {
+	RegisterTestingT(t)
+	counter := NewModeCounter([]string{"simulate"})
+
+	counter.Count("simulate")
+	counter.CountRequest("a.com", 200)
+	counter.CountMatch("missed")
+	counter.RecordDelay(1500 * time.Millisecond)
+
+	var buf bytes.Buffer
+	counter.WritePrometheus(&buf)
+	Expect(buf.String()).To(ContainSubstring(`hoverfly_mode_requests_total{mode="simulate"} 1`))
+	Expect(buf.String()).To(ContainSubstring(`hoverfly_faults_total{mode="simulate"} 0`))
+	Expect(buf.String()).To(ContainSubstring(`hoverfly_requests_total{destination="a.com",status="200"} 1`))
+	Expect(buf.String()).To(ContainSubstring(`hoverfly_matches_total{outcome="missed"} 1`))
+	Expect(buf.String()).To(ContainSubstring("hoverfly_applied_delay_seconds_total 1.5"))
+	Expect(buf.String()).To(ContainSubstring("hoverfly_applied_delays_total 1"))
+}
@@ -103,6 +103,25 @@ const ActionTypeWipeDB = "wipeDatabase"
 // ActionTypeConfigurationChanged - default action name for identifying configuration changes
 const ActionTypeConfigurationChanged = "configurationChanged"
 
+// ActionTypeSimulateHit - action type for requests answered with a stored response in simulate or spy mode
+const ActionTypeSimulateHit = "simulateHit"
+
+// ActionTypeSimulateMiss - action type for requests without a stored response in simulate or spy mode
+const ActionTypeSimulateMiss = "simulateMiss"
+
+// ActionTypeModeChanged - action type for switching Hoverfly to another mode
+const ActionTypeModeChanged = "modeChanged"
+
+// ActionTypes - all action types fired by Hoverfly
+var ActionTypes = []ActionType{
+	ActionTypeRequestCaptured,
+	ActionTypeWipeDB,
+	ActionTypeConfigurationChanged,
+	ActionTypeSimulateHit,
+	ActionTypeSimulateMiss,
+	ActionTypeModeChanged,
+}
+
 // Entry - holds information about action, based on action type - other clients will be able to decode
 // the data field.
 type Entry struct {
@@ -134,13 +153,27 @@ func (hooks ActionTypeHooks) Add(hook Hook) {
 	}
 }
 
-// Fire all the hooks for the passed ActionType
+// Remove a hook
+func (hooks ActionTypeHooks) Remove(hook Hook) {
+	for _, ac := range hook.ActionTypes() {
+		var remaining []Hook
+		for _, h := range hooks[ac] {
+			if h != hook {
+				remaining = append(remaining, h)
+			}
+		}
+		hooks[ac] = remaining
+	}
+}
+
+// Fire all the hooks for the passed ActionType, a failing hook doesn't stop the others and its error is returned
 func (hooks ActionTypeHooks) Fire(ac ActionType, entry *Entry) error {
+	var err error
 	for _, hook := range hooks[ac] {
-		if err := hook.Fire(entry); err != nil {
-			return err
+		if hookErr := hook.Fire(entry); hookErr != nil && err == nil {
+			err = hookErr
 		}
 	}
 
-	return nil
+	return err
 }
@@ -0,0 +1,289 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// content types with canonical body representation
+const (
+	contentTypeJSON      = "application/json"
+	contentTypeXML       = "application/xml"
+	contentTypeForm      = "application/x-www-form-urlencoded"
+	contentTypeMultipart = "multipart/form-data"
+	otherType            = "otherType"
+)
+
+var (
+	rxJSON = regexp.MustCompile("[/+]json$")
+	rxXML  = regexp.MustCompile("[/+]xml$")
+
+	rxBodyPathSegment = regexp.MustCompile(`^([^\[\]]*)((?:\[(?:\d+|\*)\])*)$`)
+	rxBodyPathIndex   = regexp.MustCompile(`\[(\d+|\*)\]`)
+)
+
+// canonicalJSON - returns JSON document with sorted keys, normalised numbers and no insignificant
+// whitespace. Values at ignored paths are removed.
+func canonicalJSON(body string, ignoredPaths []string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return "", err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return "", fmt.Errorf("unexpected data after JSON document")
+	}
+
+	for _, path := range ignoredPaths {
+		segments, err := parseBodyPath(path)
+		if err != nil {
+			return "", err
+		}
+		document = removeBodyPath(document, segments)
+	}
+
+	// encoding/json writes object keys in sorted order
+	b, err := json.Marshal(normaliseJSONNumbers(document))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// normaliseJSONNumbers - integers that don't fit int64 keep their original text so distinct values
+// don't collapse into the same float
+func normaliseJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = normaliseJSONNumbers(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = normaliseJSONNumbers(child)
+		}
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+				return json.Number(strconv.FormatInt(i, 10))
+			}
+			return value
+		}
+		if f, err := strconv.ParseFloat(v.String(), 64); err == nil {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+	return value
+}
+
+// bodyPathSegment - object key or array index of a JSON path, index -1 matches all elements
+type bodyPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseBodyPath - parses JSON paths such as '$.meta.timestamp', 'items[*].id' or 'items[0].id'
+func parseBodyPath(path string) ([]bodyPathSegment, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if trimmed == "" {
+		return nil, fmt.Errorf("body path '%s' doesn't select anything", path)
+	}
+
+	var segments []bodyPathSegment
+	for _, part := range strings.Split(trimmed, ".") {
+		match := rxBodyPathSegment.FindStringSubmatch(part)
+		if match == nil || (match[1] == "" && match[2] == "") {
+			return nil, fmt.Errorf("body path '%s' is not valid", path)
+		}
+
+		if match[1] != "" {
+			segments = append(segments, bodyPathSegment{key: match[1]})
+		}
+
+		for _, index := range rxBodyPathIndex.FindAllStringSubmatch(match[2], -1) {
+			segment := bodyPathSegment{index: -1, isIndex: true}
+			if index[1] != "*" {
+				segment.index, _ = strconv.Atoi(index[1])
+			}
+			segments = append(segments, segment)
+		}
+	}
+	return segments, nil
+}
+
+// ValidateBodyPath - returns error when given JSON path can't be used to ignore parts of a body
+func ValidateBodyPath(path string) error {
+	_, err := parseBodyPath(path)
+	return err
+}
+
+func removeBodyPath(value interface{}, segments []bodyPathSegment) interface{} {
+	if len(segments) == 0 {
+		return value
+	}
+
+	segment, last := segments[0], len(segments) == 1
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if segment.isIndex {
+			return v
+		}
+		if child, ok := v[segment.key]; ok {
+			if last {
+				delete(v, segment.key)
+			} else {
+				v[segment.key] = removeBodyPath(child, segments[1:])
+			}
+		}
+		return v
+
+	case []interface{}:
+		if !segment.isIndex {
+			return v
+		}
+		var kept []interface{}
+		for i, child := range v {
+			selected := segment.index == -1 || segment.index == i
+			switch {
+			case selected && last:
+				continue
+			case selected:
+				kept = append(kept, removeBodyPath(child, segments[1:]))
+			default:
+				kept = append(kept, child)
+			}
+		}
+		if kept == nil {
+			kept = []interface{}{}
+		}
+		return kept
+	}
+
+	return value
+}
+
+// canonicalXML - returns representation of XML document that doesn't depend on whitespace between
+// elements, attribute order or namespace prefixes
+func canonicalXML(body string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+
+	var buffer bytes.Buffer
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			buffer.WriteString("<" + xmlName(t.Name))
+
+			var attributes []string
+			for _, attr := range t.Attr {
+				// namespace declarations only introduce prefixes
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				attributes = append(attributes, fmt.Sprintf(" %s=%q", xmlName(attr.Name), attr.Value))
+			}
+			sort.Strings(attributes)
+			buffer.WriteString(strings.Join(attributes, ""))
+			buffer.WriteString(">")
+
+		case xml.EndElement:
+			buffer.WriteString("</" + xmlName(t.Name) + ">")
+
+		case xml.CharData:
+			buffer.WriteString(strings.TrimSpace(string(t)))
+		}
+	}
+
+	return buffer.String(), nil
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+// canonicalForm - returns URL encoded form with fields sorted by name
+func canonicalForm(body string) (string, error) {
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+// canonicalMultipart - returns representation of multipart form that doesn't depend on the boundary
+// or the order of the parts
+func canonicalMultipart(body, boundary string) (string, error) {
+	if boundary == "" {
+		return "", fmt.Errorf("multipart boundary is missing")
+	}
+
+	reader := multipart.NewReader(strings.NewReader(body), boundary)
+
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return "", err
+		}
+
+		parts = append(parts, fmt.Sprintf("%q;%q;%q;%q", part.FormName(), part.FileName(), part.Header.Get("Content-Type"), content))
+	}
+
+	sort.Strings(parts)
+	return strings.Join(parts, "\n"), nil
+}
+
+// getMediaType - returns canonicalisable content type of the body and its parameters
+func getMediaType(contentTypes []string) (string, map[string]string) {
+	for _, v := range contentTypes {
+		mediaType, params, err := mime.ParseMediaType(v)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case rxJSON.MatchString(mediaType):
+			return contentTypeJSON, params
+		case rxXML.MatchString(mediaType):
+			return contentTypeXML, params
+		case mediaType == contentTypeForm:
+			return contentTypeForm, params
+		case mediaType == contentTypeMultipart:
+			return contentTypeMultipart, params
+		}
+	}
+	return otherType, nil
+}
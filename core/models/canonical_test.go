@@ -0,0 +1,139 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func fingerprintWithContentType(contentType, body string, policy *FingerprintPolicy) string {
+	r := RequestDetails{
+		Path:    "/items",
+		Method:  "POST",
+		Body:    body,
+		Headers: map[string][]string{"Content-Type": {contentType}},
+	}
+	return r.Fingerprint(policy, true)
+}
+
+func TestCanonicalJSON_SortsKeysAndNormalisesNumbers(t *testing.T) {
+	RegisterTestingT(t)
+
+	canonical, err := canonicalJSON(`{"b": 1.0, "a": {"d": 1e2, "c": [3, 2.50]}}`, nil)
+	Expect(err).To(BeNil())
+	Expect(canonical).To(Equal(`{"a":{"c":[3,2.5],"d":100},"b":1}`))
+}
+
+func TestCanonicalJSON_KeepsIntegersOutsideInt64(t *testing.T) {
+	RegisterTestingT(t)
+
+	one, err := canonicalJSON(`{"id": 12345678901234567890}`, nil)
+	Expect(err).To(BeNil())
+	Expect(one).To(Equal(`{"id":12345678901234567890}`))
+
+	other, err := canonicalJSON(`{"id": 12345678901234567891}`, nil)
+	Expect(err).To(BeNil())
+	Expect(other).ToNot(Equal(one))
+}
+
+func TestCanonicalJSON_RejectsTrailingData(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := canonicalJSON(`{"a": 1} {"b": 2}`, nil)
+	Expect(err).ToNot(BeNil())
+
+	_, err = canonicalJSON(`{"a": 1} garbage`, nil)
+	Expect(err).ToNot(BeNil())
+
+	canonical, err := canonicalJSON("{\"a\": 1}\n", nil)
+	Expect(err).To(BeNil())
+	Expect(canonical).To(Equal(`{"a":1}`))
+}
+
+func TestCanonicalJSON_RemovesIgnoredPaths(t *testing.T) {
+	RegisterTestingT(t)
+
+	body := `{"requestId": "abc", "meta": {"timestamp": 123, "source": "web"}, "items": [{"id": 1, "name": "one"}, {"id": 2, "name": "two"}]}`
+
+	canonical, err := canonicalJSON(body, []string{"$.requestId", "meta.timestamp", "$.items[*].id"})
+	Expect(err).To(BeNil())
+	Expect(canonical).To(Equal(`{"items":[{"name":"one"},{"name":"two"}],"meta":{"source":"web"}}`))
+
+	canonical, err = canonicalJSON(body, []string{"$.items[0]"})
+	Expect(err).To(BeNil())
+	Expect(canonical).To(ContainSubstring(`"items":[{"id":2,"name":"two"}]`))
+}
+
+func TestFingerprint_JSONBodiesWithDifferentKeyOrderMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	one := fingerprintWithContentType("application/json; charset=utf-8", `{"a": 1, "b": [1, 2]}`, nil)
+	two := fingerprintWithContentType("application/json", `{"b":[1,2],"a":1.0}`, nil)
+
+	Expect(one).To(Equal(two))
+}
+
+func TestFingerprint_IgnoredBodyPathsAreNotHashed(t *testing.T) {
+	RegisterTestingT(t)
+
+	policy := &FingerprintPolicy{IgnoredBodyPaths: []string{"$.timestamp"}}
+
+	one := fingerprintWithContentType("application/json", `{"name": "x", "timestamp": 1}`, policy)
+	two := fingerprintWithContentType("application/json", `{"name": "x", "timestamp": 2}`, policy)
+
+	Expect(one).To(Equal(two))
+	Expect(fingerprintWithContentType("application/json", `{"name": "x", "timestamp": 1}`, nil)).ToNot(Equal(
+		fingerprintWithContentType("application/json", `{"name": "x", "timestamp": 2}`, nil)))
+}
+
+func TestCanonicalXML_IgnoresAttributeOrderAndNamespacePrefixes(t *testing.T) {
+	RegisterTestingT(t)
+
+	one, err := canonicalXML(`<a:order xmlns:a="urn:orders" id="1" status="new">
+		<a:item>book</a:item>
+	</a:order>`)
+	Expect(err).To(BeNil())
+
+	two, err := canonicalXML(`<b:order xmlns:b="urn:orders" status="new" id="1"><b:item>book</b:item></b:order>`)
+	Expect(err).To(BeNil())
+
+	Expect(one).To(Equal(two))
+	Expect(one).To(Equal(`<{urn:orders}order id="1" status="new"><{urn:orders}item>book</{urn:orders}item></{urn:orders}order>`))
+}
+
+func TestFingerprint_FormBodiesWithDifferentFieldOrderMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	one := fingerprintWithContentType("application/x-www-form-urlencoded", "b=2&a=1&a=3", nil)
+	two := fingerprintWithContentType("application/x-www-form-urlencoded", "a=1&b=2&a=3", nil)
+
+	Expect(one).To(Equal(two))
+}
+
+func TestFingerprint_MultipartBodiesWithDifferentBoundariesAndPartOrderMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	one := fingerprintWithContentType("multipart/form-data; boundary=AAA",
+		"--AAA\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n"+
+			"--AAA\r\nContent-Disposition: form-data; name=\"file\"; filename=\"f.txt\"\r\nContent-Type: text/plain\r\n\r\ncontent\r\n"+
+			"--AAA--\r\n", nil)
+
+	two := fingerprintWithContentType("multipart/form-data; boundary=BBB",
+		"--BBB\r\nContent-Disposition: form-data; name=\"file\"; filename=\"f.txt\"\r\nContent-Type: text/plain\r\n\r\ncontent\r\n"+
+			"--BBB\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n"+
+			"--BBB--\r\n", nil)
+
+	other := fingerprintWithContentType("multipart/form-data; boundary=BBB",
+		"--BBB\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n2\r\n"+
+			"--BBB--\r\n", nil)
+
+	Expect(one).To(Equal(two))
+	Expect(one).ToNot(Equal(other))
+}
+
+func TestCanonicalBody_FallsBackToRawBodyWhenItCannotBeParsed(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := RequestDetails{Body: `{"broken"`, Headers: map[string][]string{"Content-Type": {"application/json"}}}
+	Expect(r.canonicalBody(nil)).To(Equal(`{"broken"`))
+}
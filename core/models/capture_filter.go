@@ -0,0 +1,242 @@
+package models
+
+import (
+	"fmt"
+	"mime"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// CaptureFilter - rule matching captured exchanges by request method, request path, response status
+// range and response content type. Empty fields match everything.
+type CaptureFilter struct {
+	Methods      []string
+	PathPattern  string
+	StatusFrom   int
+	StatusTo     int
+	ContentTypes []string
+
+	path *regexp.Regexp
+}
+
+// CaptureFilters - exchanges are captured when they match at least one include rule (or there are
+// none) and match no exclude rule
+type CaptureFilters struct {
+	Include []CaptureFilter
+	Exclude []CaptureFilter
+}
+
+// NewCaptureFilter - validates given rule and prepares it to be evaluated
+func NewCaptureFilter(methods []string, pathPattern string, statusFrom, statusTo int, contentTypes []string) (*CaptureFilter, error) {
+	f := &CaptureFilter{
+		Methods:      methods,
+		PathPattern:  pathPattern,
+		StatusFrom:   statusFrom,
+		StatusTo:     statusTo,
+		ContentTypes: contentTypes,
+	}
+
+	if pathPattern != "" {
+		rx, err := regexp.Compile(pathPattern)
+		if err != nil {
+			return nil, fmt.Errorf("capture filter path is not a valid regular expression string: %s", pathPattern)
+		}
+		f.path = rx
+	}
+
+	if statusFrom < 0 || statusTo < 0 || (statusTo != 0 && statusFrom > statusTo) {
+		return nil, fmt.Errorf("capture filter status range %d-%d is not valid", statusFrom, statusTo)
+	}
+
+	return f, nil
+}
+
+// ParseCaptureFilter - parses rules such as 'method=GET,POST;path=^/api/;status=200-299;content-type=application/json'
+func ParseCaptureFilter(rule string) (*CaptureFilter, error) {
+	var methods, contentTypes []string
+	var path string
+	var statusFrom, statusTo int
+
+	for _, field := range strings.Split(rule, ";") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("capture filter field '%s' is not in key=value form", field)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(kv[0])), strings.TrimSpace(kv[1])
+
+		switch key {
+		case "method":
+			methods = strings.Split(value, ",")
+		case "path":
+			path = value
+		case "status":
+			var err error
+			statusFrom, statusTo, err = parseStatusRange(value)
+			if err != nil {
+				return nil, err
+			}
+		case "content-type":
+			contentTypes = strings.Split(value, ",")
+		default:
+			return nil, fmt.Errorf("capture filter field '%s' is not supported, use method, path, status or content-type", key)
+		}
+	}
+
+	return NewCaptureFilter(methods, path, statusFrom, statusTo, contentTypes)
+}
+
+// parseStatusRange - parses single status such as '404' or range such as '500-599'
+func parseStatusRange(value string) (int, int, error) {
+	bounds := strings.SplitN(value, "-", 2)
+
+	from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("capture filter status '%s' is not valid", value)
+	}
+	if len(bounds) == 1 {
+		return from, from, nil
+	}
+
+	to, err := strconv.Atoi(strings.TrimSpace(bounds[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("capture filter status '%s' is not valid", value)
+	}
+	return from, to, nil
+}
+
+// Matches - returns whether exchange with given request method and path and response status and
+// content type matches the rule
+func (f *CaptureFilter) Matches(method, path string, status int, contentType string) bool {
+	if len(f.Methods) > 0 && !containsFold(f.Methods, method) {
+		return false
+	}
+
+	if f.path != nil && !f.path.MatchString(path) {
+		return false
+	}
+
+	if f.StatusFrom != 0 && status < f.StatusFrom {
+		return false
+	}
+	if f.StatusTo != 0 && status > f.StatusTo {
+		return false
+	}
+
+	if len(f.ContentTypes) > 0 && !matchesContentType(f.ContentTypes, contentType) {
+		return false
+	}
+
+	return true
+}
+
+// Allows - returns whether exchange should be captured
+func (f *CaptureFilters) Allows(method, path string, status int, contentType string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, filter := range f.Exclude {
+		if filter.Matches(method, path, status, contentType) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, filter := range f.Include {
+		if filter.Matches(method, path, status, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty - filters that allow every exchange
+func (f *CaptureFilters) IsEmpty() bool {
+	return f == nil || (len(f.Include) == 0 && len(f.Exclude) == 0)
+}
+
+func NewCaptureFiltersFromView(data views.CaptureFiltersView) (*CaptureFilters, error) {
+	filters := &CaptureFilters{}
+
+	for _, v := range data.Include {
+		f, err := NewCaptureFilter(v.Methods, v.Path, v.StatusFrom, v.StatusTo, v.ContentTypes)
+		if err != nil {
+			return nil, err
+		}
+		filters.Include = append(filters.Include, *f)
+	}
+
+	for _, v := range data.Exclude {
+		f, err := NewCaptureFilter(v.Methods, v.Path, v.StatusFrom, v.StatusTo, v.ContentTypes)
+		if err != nil {
+			return nil, err
+		}
+		filters.Exclude = append(filters.Exclude, *f)
+	}
+
+	return filters, nil
+}
+
+func (f *CaptureFilters) ConvertToCaptureFiltersView() *views.CaptureFiltersView {
+	view := &views.CaptureFiltersView{
+		Include: []views.CaptureFilterView{},
+		Exclude: []views.CaptureFilterView{},
+	}
+	if f != nil {
+		for _, filter := range f.Include {
+			view.Include = append(view.Include, filter.convertToCaptureFilterView())
+		}
+		for _, filter := range f.Exclude {
+			view.Exclude = append(view.Exclude, filter.convertToCaptureFilterView())
+		}
+	}
+	return view
+}
+
+func (f *CaptureFilter) convertToCaptureFilterView() views.CaptureFilterView {
+	return views.CaptureFilterView{
+		Methods:      f.Methods,
+		Path:         f.PathPattern,
+		StatusFrom:   f.StatusFrom,
+		StatusTo:     f.StatusTo,
+		ContentTypes: f.ContentTypes,
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesContentType - compares media types without parameters, 'text/*' matches any text type
+func matchesContentType(patterns []string, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == mediaType || pattern == "*/*" {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
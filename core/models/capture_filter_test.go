@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseCaptureFilter(t *testing.T) {
+	RegisterTestingT(t)
+
+	filter, err := ParseCaptureFilter("method=GET,POST; path=^/api/; status=200-299; content-type=application/json")
+	Expect(err).To(BeNil())
+	Expect(filter.Methods).To(Equal([]string{"GET", "POST"}))
+	Expect(filter.PathPattern).To(Equal("^/api/"))
+	Expect(filter.StatusFrom).To(Equal(200))
+	Expect(filter.StatusTo).To(Equal(299))
+	Expect(filter.ContentTypes).To(Equal([]string{"application/json"}))
+
+	filter, err = ParseCaptureFilter("status=404")
+	Expect(err).To(BeNil())
+	Expect(filter.StatusFrom).To(Equal(404))
+	Expect(filter.StatusTo).To(Equal(404))
+}
+
+func TestParseCaptureFilter_ReturnsErrorForInvalidRules(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, rule := range []string{"path=(unclosed", "status=abc", "status=500-400", "host=api.com", "method"} {
+		_, err := ParseCaptureFilter(rule)
+		Expect(err).ToNot(BeNil(), rule)
+	}
+}
+
+func TestCaptureFilter_Matches(t *testing.T) {
+	RegisterTestingT(t)
+
+	filter, err := NewCaptureFilter([]string{"get"}, "^/api/", 200, 299, []string{"application/json", "text/*"})
+	Expect(err).To(BeNil())
+
+	Expect(filter.Matches("GET", "/api/items", 200, "application/json; charset=utf-8")).To(BeTrue())
+	Expect(filter.Matches("GET", "/api/items", 201, "text/plain")).To(BeTrue())
+	Expect(filter.Matches("POST", "/api/items", 200, "application/json")).To(BeFalse())
+	Expect(filter.Matches("GET", "/health", 200, "application/json")).To(BeFalse())
+	Expect(filter.Matches("GET", "/api/items", 500, "application/json")).To(BeFalse())
+	Expect(filter.Matches("GET", "/api/items", 200, "image/png")).To(BeFalse())
+	Expect(filter.Matches("GET", "/api/items", 200, "")).To(BeFalse())
+}
+
+func TestCaptureFilters_ExcludeTakesPrecedenceOverInclude(t *testing.T) {
+	RegisterTestingT(t)
+
+	filters, err := NewCaptureFiltersFromView(views.CaptureFiltersView{
+		Include: []views.CaptureFilterView{{Path: "^/api/"}},
+		Exclude: []views.CaptureFilterView{{StatusFrom: 500, StatusTo: 599}},
+	})
+	Expect(err).To(BeNil())
+
+	Expect(filters.Allows("GET", "/api/items", 200, "")).To(BeTrue())
+	Expect(filters.Allows("GET", "/api/items", 503, "")).To(BeFalse())
+	Expect(filters.Allows("GET", "/beacon", 200, "")).To(BeFalse())
+}
+
+func TestCaptureFilters_NilFiltersAllowEverything(t *testing.T) {
+	RegisterTestingT(t)
+
+	var filters *CaptureFilters
+
+	Expect(filters.Allows("DELETE", "/anything", 500, "text/html")).To(BeTrue())
+	Expect(filters.IsEmpty()).To(BeTrue())
+	Expect(filters.ConvertToCaptureFiltersView().Include).To(BeEmpty())
+}
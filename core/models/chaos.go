@@ -0,0 +1,145 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// ChaosRule - replaces Percentage percent of responses to requests matching Destination and Path
+// regular expressions with one of Responses picked at random. Empty patterns match everything.
+type ChaosRule struct {
+	Destination string
+	Path        string
+	Percentage  float64
+	Responses   []ResponseDetails
+
+	requestPatterns
+}
+
+// NewChaosRule - validates given rule and compiles its patterns
+func NewChaosRule(destination, path string, percentage float64, responses []ResponseDetails) (*ChaosRule, error) {
+	rule := &ChaosRule{
+		Destination: destination,
+		Path:        path,
+		Percentage:  percentage,
+		Responses:   responses,
+	}
+
+	if err := rule.compile("chaos", destination, path); err != nil {
+		return nil, err
+	}
+
+	if percentage < 0 || percentage > 100 {
+		return nil, fmt.Errorf("chaos percentage must be between 0 and 100, got %v", percentage)
+	}
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("chaos rule for destination '%s' and path '%s' has no responses", destination, path)
+	}
+	for _, response := range responses {
+		if response.Status < 100 || response.Status > 599 {
+			return nil, fmt.Errorf("chaos response status %d is not a valid HTTP status", response.Status)
+		}
+	}
+
+	return rule, nil
+}
+
+// Chaos - rules deciding which responses are replaced with error responses. Random numbers are
+// generated from Seed, so runs with the same seed and the same sequence of requests inject the
+// same errors.
+type Chaos struct {
+	Seed  int64
+	Rules []ChaosRule
+
+	random *rand.Rand
+	mu     sync.Mutex
+}
+
+// NewChaos - returns chaos with given rules using random numbers generated from seed
+func NewChaos(seed int64, rules []ChaosRule) *Chaos {
+	return &Chaos{
+		Seed:   seed,
+		Rules:  rules,
+		random: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// NewChaosFromView - validates rules of given view, chaos is seeded with current time when view has no seed
+func NewChaosFromView(data views.ChaosView) (*Chaos, error) {
+	seed := time.Now().UnixNano()
+	if data.Seed != nil {
+		seed = *data.Seed
+	}
+
+	var rules []ChaosRule
+	for _, ruleView := range data.Rules {
+		var responses []ResponseDetails
+		for _, responseView := range ruleView.Responses {
+			responses = append(responses, NewResponseDetialsFromResponseDetailsView(responseView))
+		}
+
+		rule, err := NewChaosRule(ruleView.Destination, ruleView.Path, ruleView.Percentage, responses)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return NewChaos(seed, rules), nil
+}
+
+func (this *Chaos) ConvertToChaosView() *views.ChaosView {
+	view := &views.ChaosView{Rules: []views.ChaosRuleView{}}
+	if this == nil {
+		return view
+	}
+
+	seed := this.Seed
+	view.Seed = &seed
+	for _, rule := range this.Rules {
+		ruleView := views.ChaosRuleView{
+			Destination: rule.Destination,
+			Path:        rule.Path,
+			Percentage:  rule.Percentage,
+			Responses:   []views.ResponseDetailsView{},
+		}
+		for _, response := range rule.Responses {
+			ruleView.Responses = append(ruleView.Responses, response.ConvertToResponseDetailsView())
+		}
+		view.Rules = append(view.Rules, ruleView)
+	}
+	return view
+}
+
+// IsEmpty - chaos that never replaces responses
+func (this *Chaos) IsEmpty() bool {
+	return this == nil || len(this.Rules) == 0
+}
+
+// Pick - returns error response replacing response to request with given destination and path, nil
+// when response is kept. First matching rule decides.
+func (this *Chaos) Pick(destination, path string) *ResponseDetails {
+	if this.IsEmpty() {
+		return nil
+	}
+
+	for _, rule := range this.Rules {
+		if !rule.Matches(destination, path) {
+			continue
+		}
+
+		this.mu.Lock()
+		defer this.mu.Unlock()
+
+		if this.random.Float64()*100 >= rule.Percentage {
+			return nil
+		}
+		response := rule.Responses[this.random.Intn(len(rule.Responses))]
+		return &response
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func unavailable() []ResponseDetails {
+	return []ResponseDetails{{Status: 503, Headers: map[string][]string{"Retry-After": {"30"}}}}
+}
+
+func TestNewChaosRule_Validates(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewChaosRule("api.com", "^/users", 10, unavailable())
+	Expect(err).To(BeNil())
+
+	_, err = NewChaosRule("*", "", 10, unavailable())
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewChaosRule("", "", 101, unavailable())
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewChaosRule("", "", 10, nil)
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewChaosRule("", "", 10, []ResponseDetails{{Status: 42}})
+	Expect(err).ToNot(BeNil())
+}
+
+func TestChaosRule_MatchesDestinationAndPath(t *testing.T) {
+	RegisterTestingT(t)
+
+	rule, err := NewChaosRule("api.com", "^/users", 10, unavailable())
+	Expect(err).To(BeNil())
+
+	Expect(rule.Matches("api.com", "/users/1")).To(BeTrue())
+	Expect(rule.Matches("api.com", "/orders")).To(BeFalse())
+	Expect(rule.Matches("other.com", "/users")).To(BeFalse())
+
+	everything, err := NewChaosRule("", "", 10, unavailable())
+	Expect(err).To(BeNil())
+	Expect(everything.Matches("other.com", "/orders")).To(BeTrue())
+}
+
+func TestChaos_PickRespectsPercentage(t *testing.T) {
+	RegisterTestingT(t)
+
+	always, _ := NewChaosRule("always.com", "", 100, unavailable())
+	never, _ := NewChaosRule("never.com", "", 0, unavailable())
+	chaos := NewChaos(1, []ChaosRule{*always, *never})
+
+	for i := 0; i < 100; i++ {
+		Expect(chaos.Pick("always.com", "/").Status).To(Equal(503))
+		Expect(chaos.Pick("never.com", "/")).To(BeNil())
+		Expect(chaos.Pick("other.com", "/")).To(BeNil())
+	}
+}
+
+func TestChaos_SameSeedPicksSameResponses(t *testing.T) {
+	RegisterTestingT(t)
+
+	picks := func(seed int64) []int {
+		rule, err := NewChaosRule("", "", 50, []ResponseDetails{{Status: 503}, {Status: 429}})
+		Expect(err).To(BeNil())
+		chaos := NewChaos(seed, []ChaosRule{*rule})
+
+		var statuses []int
+		for i := 0; i < 50; i++ {
+			status := 200
+			if response := chaos.Pick("api.com", "/"); response != nil {
+				status = response.Status
+			}
+			statuses = append(statuses, status)
+		}
+		return statuses
+	}
+
+	first := picks(42)
+	Expect(picks(42)).To(Equal(first))
+	Expect(first).To(ContainElement(200))
+	Expect(first).To(ContainElement(503))
+	Expect(first).To(ContainElement(429))
+}
+
+func TestChaos_ConvertsFromAndToView(t *testing.T) {
+	RegisterTestingT(t)
+
+	seed := int64(7)
+	chaos, err := NewChaosFromView(views.ChaosView{
+		Seed: &seed,
+		Rules: []views.ChaosRuleView{{
+			Destination: "api.com",
+			Percentage:  25,
+			Responses:   []views.ResponseDetailsView{{Status: 429, Body: "slow down"}},
+		}},
+	})
+	Expect(err).To(BeNil())
+	Expect(chaos.Seed).To(Equal(int64(7)))
+
+	view := chaos.ConvertToChaosView()
+	Expect(*view.Seed).To(Equal(int64(7)))
+	Expect(view.Rules).To(HaveLen(1))
+	Expect(view.Rules[0].Destination).To(Equal("api.com"))
+	Expect(view.Rules[0].Responses[0].Status).To(Equal(429))
+	Expect(view.Rules[0].Responses[0].Body).To(Equal("slow down"))
+
+	var empty *Chaos
+	Expect(empty.ConvertToChaosView().Rules).To(BeEmpty())
+}
@@ -0,0 +1,100 @@
+package models
+
+import "sync"
+
+// Coverage - counts how many times each record, identified by its key, and each template, identified
+// by its index, was matched to a request
+type Coverage struct {
+	records   map[string]int
+	templates map[int]int
+	mu        sync.Mutex
+}
+
+// NewCoverage - returns coverage with no hits
+func NewCoverage() *Coverage {
+	return &Coverage{
+		records:   make(map[string]int),
+		templates: make(map[int]int),
+	}
+}
+
+// HitRecord - counts match of record stored under given key
+func (this *Coverage) HitRecord(key string) {
+	if this == nil {
+		return
+	}
+
+	this.mu.Lock()
+	this.records[key]++
+	this.mu.Unlock()
+}
+
+// HitTemplate - counts match of template with given index
+func (this *Coverage) HitTemplate(index int) {
+	if this == nil {
+		return
+	}
+
+	this.mu.Lock()
+	this.templates[index]++
+	this.mu.Unlock()
+}
+
+// RecordHits - returns how many times record stored under given key was matched
+func (this *Coverage) RecordHits(key string) int {
+	if this == nil {
+		return 0
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.records[key]
+}
+
+// TemplateHits - returns how many times template with given index was matched
+func (this *Coverage) TemplateHits(index int) int {
+	if this == nil {
+		return 0
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.templates[index]
+}
+
+// MoveRecord - moves hits of record to the key it is stored under now, used when records are re-keyed
+func (this *Coverage) MoveRecord(from, to string) {
+	if this == nil || from == to {
+		return
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if hits, ok := this.records[from]; ok {
+		this.records[to] += hits
+		delete(this.records, from)
+	}
+}
+
+// ResetRecords - forgets hits of all records
+func (this *Coverage) ResetRecords() {
+	if this == nil {
+		return
+	}
+
+	this.mu.Lock()
+	this.records = make(map[string]int)
+	this.mu.Unlock()
+}
+
+// ResetTemplates - forgets hits of all templates, templates are identified by index so hits must be
+// reset whenever templates are replaced
+func (this *Coverage) ResetTemplates() {
+	if this == nil {
+		return
+	}
+
+	this.mu.Lock()
+	this.templates = make(map[int]int)
+	this.mu.Unlock()
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCoverage_CountsAndResetsHits(t *testing.T) {
+	RegisterTestingT(t)
+
+	coverage := NewCoverage()
+	coverage.HitRecord("key")
+	coverage.HitRecord("key")
+	coverage.HitTemplate(1)
+
+	Expect(coverage.RecordHits("key")).To(Equal(2))
+	Expect(coverage.RecordHits("other")).To(Equal(0))
+	Expect(coverage.TemplateHits(1)).To(Equal(1))
+	Expect(coverage.TemplateHits(0)).To(Equal(0))
+
+	coverage.ResetTemplates()
+	Expect(coverage.TemplateHits(1)).To(Equal(0))
+	Expect(coverage.RecordHits("key")).To(Equal(2))
+
+	coverage.ResetRecords()
+	Expect(coverage.RecordHits("key")).To(Equal(0))
+}
+
+func TestCoverage_MovesRecordHits(t *testing.T) {
+	RegisterTestingT(t)
+
+	coverage := NewCoverage()
+	coverage.HitRecord("old")
+	coverage.HitRecord("old")
+	coverage.HitRecord("new")
+
+	coverage.MoveRecord("old", "new")
+	Expect(coverage.RecordHits("old")).To(Equal(0))
+	Expect(coverage.RecordHits("new")).To(Equal(3))
+
+	coverage.MoveRecord("new", "new")
+	Expect(coverage.RecordHits("new")).To(Equal(3))
+}
+
+func TestCoverage_NilCoverageCountsNothing(t *testing.T) {
+	RegisterTestingT(t)
+
+	var coverage *Coverage
+	coverage.HitRecord("key")
+	coverage.HitTemplate(0)
+
+	Expect(coverage.RecordHits("key")).To(Equal(0))
+	Expect(coverage.TemplateHits(0)).To(Equal(0))
+}
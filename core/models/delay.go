@@ -1,6 +1,8 @@
 package models
 
 import (
+	"math"
+	"math/rand"
 	"regexp"
 	log "github.com/Sirupsen/logrus"
 	"time"
@@ -10,10 +12,35 @@ import (
 	"encoding/json"
 )
 
+// delay distributions, fixed delay is used when distribution is not set
+const (
+	DelayDistributionFixed     = "fixed"
+	DelayDistributionUniform   = "uniform"
+	DelayDistributionNormal    = "normal"
+	DelayDistributionLognormal = "lognormal"
+)
+
+// z-scores of supported upper percentiles of the standard normal distribution
+const (
+	z95 = 1.6448536269514722
+	z99 = 2.3263478740408408
+)
+
+// ResponseDelay - delay in milliseconds applied to responses for requests matching UrlPattern and
+// HttpMethod. Fixed delay uses Delay, uniform distribution uses Min and Max, normal and lognormal
+// distributions are described by their median (P50) and either P95 or P99, Max caps sampled delays.
+// Delay is applied with given Probability, always when Probability is not set.
 type ResponseDelay struct {
-	UrlPattern string `json:"urlPattern"`
-	HttpMethod string `json:"httpMethod"`
-	Delay      int `json:"delay"`
+	UrlPattern   string   `json:"urlPattern"`
+	HttpMethod   string   `json:"httpMethod"`
+	Delay        int      `json:"delay"`
+	Distribution string   `json:"distribution,omitempty"`
+	Min          int      `json:"min,omitempty"`
+	Max          int      `json:"max,omitempty"`
+	P50          int      `json:"p50,omitempty"`
+	P95          int      `json:"p95,omitempty"`
+	P99          int      `json:"p99,omitempty"`
+	Probability  *float64 `json:"probability,omitempty"`
 }
 
 type ResponseDelayJson struct {
@@ -31,10 +58,13 @@ type ResponseDelays interface {
 func ValidateResponseDelayJson(j ResponseDelayJson) (err error) {
 	if j.Data != nil {
 		for _, delay := range *j.Data {
-			if delay.UrlPattern != "" && delay.Delay != 0 {
+			if delay.UrlPattern != "" && delay.hasDelay() {
 				if _, err := regexp.Compile(delay.UrlPattern); err != nil {
 					return errors.New(fmt.Sprintf("Response delay entry skipped due to invalid pattern : %s", delay.UrlPattern))
 				}
+				if err := delay.validateDistribution(); err != nil {
+					return err
+				}
 			} else {
 				return errors.New(fmt.Sprintf("Config error - Missing values found in: %v", delay))
 			}
@@ -43,11 +73,106 @@ func ValidateResponseDelayJson(j ResponseDelayJson) (err error) {
 	return nil
 }
 
-func (this *ResponseDelay) Execute() {
+// hasDelay - returns whether values describing the delay are set
+func (this *ResponseDelay) hasDelay() bool {
+	switch this.Distribution {
+	case "", DelayDistributionFixed:
+		return this.Delay != 0
+	case DelayDistributionUniform:
+		return this.Max != 0
+	}
+	return this.P50 != 0
+}
+
+func (this *ResponseDelay) validateDistribution() error {
+	switch this.Distribution {
+	case "", DelayDistributionFixed:
+		if this.Delay < 0 {
+			return fmt.Errorf("Config error - delay can't be negative in: %v", *this)
+		}
+	case DelayDistributionUniform:
+		if this.Min < 0 || this.Min > this.Max {
+			return fmt.Errorf("Config error - uniform delay requires 0 <= min <= max in: %v", *this)
+		}
+	case DelayDistributionNormal, DelayDistributionLognormal:
+		if (this.P95 == 0) == (this.P99 == 0) {
+			return fmt.Errorf("Config error - %s delay requires p50 and either p95 or p99 in: %v", this.Distribution, *this)
+		}
+		if this.P50 < 0 || this.upperPercentile() <= this.P50 {
+			return fmt.Errorf("Config error - %s delay requires 0 < p50 < p95/p99 in: %v", this.Distribution, *this)
+		}
+		if this.Max < 0 {
+			return fmt.Errorf("Config error - max delay can't be negative in: %v", *this)
+		}
+	default:
+		return fmt.Errorf("Config error - unknown delay distribution '%s', use %s, %s, %s or %s",
+			this.Distribution, DelayDistributionFixed, DelayDistributionUniform, DelayDistributionNormal, DelayDistributionLognormal)
+	}
+
+	if this.Probability != nil && (*this.Probability < 0 || *this.Probability > 1) {
+		return fmt.Errorf("Config error - probability has to be between 0 and 1 in: %v", *this)
+	}
+	return nil
+}
+
+// upperPercentile - returns P95 or P99, whichever is set
+func (this *ResponseDelay) upperPercentile() int {
+	if this.P95 != 0 {
+		return this.P95
+	}
+	return this.P99
+}
+
+func (this *ResponseDelay) upperZ() float64 {
+	if this.P95 != 0 {
+		return z95
+	}
+	return z99
+}
+
+// Sample - returns delay that should be applied to a single response, zero when the delay is skipped
+// because of its probability
+func (this *ResponseDelay) Sample() time.Duration {
+	if this.Probability != nil && rand.Float64() >= *this.Probability {
+		return 0
+	}
+
+	var ms float64
+	switch this.Distribution {
+	case DelayDistributionUniform:
+		ms = float64(this.Min) + rand.Float64()*float64(this.Max-this.Min)
+	case DelayDistributionNormal:
+		sigma := float64(this.upperPercentile()-this.P50) / this.upperZ()
+		ms = float64(this.P50) + rand.NormFloat64()*sigma
+	case DelayDistributionLognormal:
+		mu := math.Log(float64(this.P50))
+		sigma := (math.Log(float64(this.upperPercentile())) - mu) / this.upperZ()
+		ms = math.Exp(mu + rand.NormFloat64()*sigma)
+	default:
+		ms = float64(this.Delay)
+	}
+
+	if ms < 0 {
+		ms = 0
+	}
+	if this.Max > 0 && ms > float64(this.Max) {
+		ms = float64(this.Max)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// Execute - samples the delay and applies it, returns delay that was applied
+func (this *ResponseDelay) Execute() time.Duration {
 	// apply the delay - must be called from goroutine handling the request
-	log.Info("Pausing before sending the response to simulate delays")
-	time.Sleep(time.Duration(this.Delay) * time.Millisecond)
+	delay := this.Sample()
+	log.WithFields(log.Fields{
+		"urlPattern":   this.UrlPattern,
+		"distribution": this.Distribution,
+		"delay":        delay.String(),
+	}).Info("Pausing before sending the response to simulate delays")
+	time.Sleep(delay)
 	log.Info("Response delay completed")
+	return delay
 }
 
 func (this *ResponseDelayList) GetDelay(url, httpMethod string) (*ResponseDelay) {
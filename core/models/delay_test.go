@@ -4,6 +4,8 @@ import (
 	. "github.com/onsi/gomega"
 	"testing"
 	"encoding/json"
+	"sort"
+	"time"
 )
 
 func TestConvertJsonStringToResponseDelayConfig(t *testing.T) {
@@ -157,4 +159,85 @@ func TestIfDelayMethodBlankThenMatchesAnyMethod(t *testing.T) {
 
 	delayMatch := delays.GetDelay("delayexample.com", "method-dummy")
 	Expect(*delayMatch).To(Equal(delay))
-}
\ No newline at end of file
+}
+func TestValidateResponseDelayJson_Distributions(t *testing.T) {
+	RegisterTestingT(t)
+
+	valid := []string{
+		`{"data": [{"urlPattern": ".", "distribution": "uniform", "min": 10, "max": 50}]}`,
+		`{"data": [{"urlPattern": ".", "distribution": "normal", "p50": 100, "p99": 400}]}`,
+		`{"data": [{"urlPattern": ".", "distribution": "lognormal", "p50": 100, "p95": 300, "max": 1000}]}`,
+		`{"data": [{"urlPattern": ".", "delay": 100, "probability": 0.25}]}`,
+	}
+	for _, conf := range valid {
+		var responseDelayJson ResponseDelayJson
+		Expect(json.Unmarshal([]byte(conf), &responseDelayJson)).To(BeNil())
+		Expect(ValidateResponseDelayJson(responseDelayJson)).To(BeNil(), conf)
+	}
+
+	invalid := []string{
+		`{"data": [{"urlPattern": ".", "distribution": "uniform", "min": 50, "max": 10}]}`,
+		`{"data": [{"urlPattern": ".", "distribution": "normal", "p50": 100}]}`,
+		`{"data": [{"urlPattern": ".", "distribution": "normal", "p50": 100, "p95": 50}]}`,
+		`{"data": [{"urlPattern": ".", "distribution": "lognormal", "p50": 100, "p95": 200, "p99": 300}]}`,
+		`{"data": [{"urlPattern": ".", "distribution": "pareto", "p50": 100, "p99": 300}]}`,
+		`{"data": [{"urlPattern": ".", "delay": 100, "probability": 1.5}]}`,
+	}
+	for _, conf := range invalid {
+		var responseDelayJson ResponseDelayJson
+		Expect(json.Unmarshal([]byte(conf), &responseDelayJson)).To(BeNil())
+		Expect(ValidateResponseDelayJson(responseDelayJson)).ToNot(BeNil(), conf)
+	}
+}
+
+func TestResponseDelay_SampleFixedDelay(t *testing.T) {
+	RegisterTestingT(t)
+
+	delay := ResponseDelay{Delay: 100}
+	Expect(delay.Sample()).To(Equal(100 * time.Millisecond))
+}
+
+func TestResponseDelay_SampleUniformDelayIsWithinRange(t *testing.T) {
+	RegisterTestingT(t)
+
+	delay := ResponseDelay{Distribution: DelayDistributionUniform, Min: 10, Max: 20}
+	for i := 0; i < 1000; i++ {
+		sample := delay.Sample()
+		Expect(sample >= 10*time.Millisecond && sample <= 20*time.Millisecond).To(BeTrue())
+	}
+}
+
+func TestResponseDelay_SampleDistributionsMatchPercentiles(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, distribution := range []string{DelayDistributionNormal, DelayDistributionLognormal} {
+		delay := ResponseDelay{Distribution: distribution, P50: 100, P95: 300}
+
+		samples := make([]float64, 10000)
+		for i := range samples {
+			samples[i] = float64(delay.Sample()) / float64(time.Millisecond)
+		}
+		sort.Float64s(samples)
+
+		Expect(samples[5000]).To(BeNumerically("~", 100, 10), distribution)
+		Expect(samples[9500]).To(BeNumerically("~", 300, 30), distribution)
+	}
+}
+
+func TestResponseDelay_SampleIsCappedByMax(t *testing.T) {
+	RegisterTestingT(t)
+
+	delay := ResponseDelay{Distribution: DelayDistributionLognormal, P50: 100, P99: 10000, Max: 150}
+	for i := 0; i < 1000; i++ {
+		Expect(delay.Sample() <= 150*time.Millisecond).To(BeTrue())
+	}
+}
+
+func TestResponseDelay_SampleRespectsProbability(t *testing.T) {
+	RegisterTestingT(t)
+
+	never, always := 0.0, 1.0
+
+	Expect((&ResponseDelay{Delay: 100, Probability: &never}).Sample()).To(Equal(time.Duration(0)))
+	Expect((&ResponseDelay{Delay: 100, Probability: &always}).Sample()).To(Equal(100 * time.Millisecond))
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// fault types
+const (
+	// FaultReset - connection is reset without sending a response
+	FaultReset = "reset"
+	// FaultCloseAfterHeaders - status line and headers are sent, connection is closed before the body
+	FaultCloseAfterHeaders = "close-after-headers"
+	// FaultTruncate - connection is closed after TruncateAt bytes of the body
+	FaultTruncate = "truncate"
+	// FaultMalformed - bytes that are not a valid HTTP response are sent and connection is closed
+	FaultMalformed = "malformed"
+	// FaultHang - request is never answered, connection is closed after Duration milliseconds
+	FaultHang = "hang"
+)
+
+// DefaultFaultHangDuration - how long hung requests are held when fault has no duration
+const DefaultFaultHangDuration = 10 * time.Minute
+
+// Fault - transport level failure injected instead of the response to requests matching UrlPattern and
+// HttpMethod. Fault is injected with given Probability, always when Probability is not set.
+type Fault struct {
+	UrlPattern  string   `json:"urlPattern"`
+	HttpMethod  string   `json:"httpMethod,omitempty"`
+	Type        string   `json:"type"`
+	TruncateAt  int      `json:"truncateAt,omitempty"`
+	Duration    int      `json:"duration,omitempty"`
+	Probability *float64 `json:"probability,omitempty"`
+}
+
+type FaultJson struct {
+	Data *FaultList `json:"data"`
+}
+
+type FaultList []Fault
+
+type Faults interface {
+	Json() []byte
+	GetFault(url, httpMethod string) *Fault
+	Len() int
+}
+
+func ValidateFaultJson(j FaultJson) error {
+	if j.Data != nil {
+		for _, fault := range *j.Data {
+			if fault.UrlPattern == "" {
+				return errors.New(fmt.Sprintf("Config error - Missing url pattern in: %v", fault))
+			}
+			if _, err := regexp.Compile(fault.UrlPattern); err != nil {
+				return errors.New(fmt.Sprintf("Fault entry skipped due to invalid pattern : %s", fault.UrlPattern))
+			}
+			if err := fault.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate - checks that fault type is known and its parameters make sense for the type
+func (this *Fault) Validate() error {
+	switch this.Type {
+	case FaultReset, FaultCloseAfterHeaders, FaultMalformed:
+		if this.TruncateAt != 0 || this.Duration != 0 {
+			return errors.New(fmt.Sprintf("Config error - fault '%s' takes no parameters in: %v", this.Type, *this))
+		}
+	case FaultTruncate:
+		if this.TruncateAt <= 0 || this.Duration != 0 {
+			return errors.New(fmt.Sprintf("Config error - fault '%s' needs positive truncateAt in: %v", this.Type, *this))
+		}
+	case FaultHang:
+		if this.Duration < 0 || this.TruncateAt != 0 {
+			return errors.New(fmt.Sprintf("Config error - fault '%s' takes only non negative duration in: %v", this.Type, *this))
+		}
+	default:
+		return errors.New(fmt.Sprintf("Config error - unknown fault type '%s', expected one of %s, %s, %s, %s or %s",
+			this.Type, FaultReset, FaultCloseAfterHeaders, FaultTruncate, FaultMalformed, FaultHang))
+	}
+
+	if this.Probability != nil && (*this.Probability < 0 || *this.Probability > 1) {
+		return errors.New(fmt.Sprintf("Config error - probability must be between 0 and 1 in: %v", *this))
+	}
+	return nil
+}
+
+// IsConnectionFault - connection faults are injected instead of processing the request, other faults
+// break the response to the request
+func (this *Fault) IsConnectionFault() bool {
+	return this.Type == FaultReset || this.Type == FaultMalformed || this.Type == FaultHang
+}
+
+// Applies - decides whether the fault is injected this time
+func (this *Fault) Applies() bool {
+	return this.Probability == nil || rand.Float64() < *this.Probability
+}
+
+// GetDuration - returns for how long hung request is held
+func (this *Fault) GetDuration() time.Duration {
+	if this.Duration == 0 {
+		return DefaultFaultHangDuration
+	}
+	return time.Duration(this.Duration) * time.Millisecond
+}
+
+func (this *FaultList) GetFault(url, httpMethod string) *Fault {
+	for _, val := range *this {
+		match := regexp.MustCompile(val.UrlPattern).MatchString(url)
+		if match {
+			if val.HttpMethod == "" || strings.EqualFold(val.HttpMethod, httpMethod) {
+				return &val
+			}
+		}
+	}
+	return nil
+}
+
+func (this *FaultList) Json() []byte {
+	resp := FaultJson{
+		Data: this,
+	}
+	b, _ := json.Marshal(resp)
+	return b
+}
+
+func (this *FaultList) Len() int {
+	if this == nil {
+		return 0
+	}
+	return len(*this)
+}
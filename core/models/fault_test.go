@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValidateFaultJson(t *testing.T) {
+	RegisterTestingT(t)
+
+	valid := []string{
+		`{"data": [{"urlPattern": ".", "type": "reset"}]}`,
+		`{"data": [{"urlPattern": ".", "type": "close-after-headers", "httpMethod": "GET"}]}`,
+		`{"data": [{"urlPattern": ".", "type": "truncate", "truncateAt": 10}]}`,
+		`{"data": [{"urlPattern": ".", "type": "malformed", "probability": 0.5}]}`,
+		`{"data": [{"urlPattern": ".", "type": "hang"}]}`,
+		`{"data": [{"urlPattern": ".", "type": "hang", "duration": 1000}]}`,
+	}
+	for _, conf := range valid {
+		var faultJson FaultJson
+		Expect(json.Unmarshal([]byte(conf), &faultJson)).To(BeNil())
+		Expect(ValidateFaultJson(faultJson)).To(BeNil(), conf)
+	}
+
+	invalid := []string{
+		`{"data": [{"type": "reset"}]}`,
+		`{"data": [{"urlPattern": "*", "type": "reset"}]}`,
+		`{"data": [{"urlPattern": "."}]}`,
+		`{"data": [{"urlPattern": ".", "type": "explode"}]}`,
+		`{"data": [{"urlPattern": ".", "type": "truncate"}]}`,
+		`{"data": [{"urlPattern": ".", "type": "reset", "truncateAt": 10}]}`,
+		`{"data": [{"urlPattern": ".", "type": "hang", "duration": -1}]}`,
+		`{"data": [{"urlPattern": ".", "type": "reset", "probability": 1.5}]}`,
+	}
+	for _, conf := range invalid {
+		var faultJson FaultJson
+		Expect(json.Unmarshal([]byte(conf), &faultJson)).To(BeNil())
+		Expect(ValidateFaultJson(faultJson)).ToNot(BeNil(), conf)
+	}
+}
+
+func TestFault_AppliesWithProbability(t *testing.T) {
+	RegisterTestingT(t)
+
+	never, always := 0.0, 1.0
+
+	Expect((&Fault{Type: FaultReset}).Applies()).To(BeTrue())
+	Expect((&Fault{Type: FaultReset, Probability: &always}).Applies()).To(BeTrue())
+	Expect((&Fault{Type: FaultReset, Probability: &never}).Applies()).To(BeFalse())
+}
+
+func TestFault_GetDuration(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect((&Fault{Type: FaultHang}).GetDuration()).To(Equal(DefaultFaultHangDuration))
+	Expect((&Fault{Type: FaultHang, Duration: 250}).GetDuration()).To(Equal(250 * time.Millisecond))
+}
+
+func TestFaultList_GetFaultMatchesPatternAndMethod(t *testing.T) {
+	RegisterTestingT(t)
+
+	faults := FaultList{
+		{UrlPattern: "upload", HttpMethod: "POST", Type: FaultReset},
+		{UrlPattern: "api.com", Type: FaultMalformed},
+	}
+
+	Expect(faults.GetFault("http://api.com/upload", "post").Type).To(Equal(FaultReset))
+	Expect(faults.GetFault("http://api.com/upload", "GET").Type).To(Equal(FaultMalformed))
+	Expect(faults.GetFault("http://other.com/", "GET")).To(BeNil())
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// FingerprintPolicy - describes how requests are fingerprinted on top of destination, path, method,
+// query and body: which headers are included, which query parameters are dropped, whether
+// query parameter order matters and which parts of JSON bodies are ignored
+type FingerprintPolicy struct {
+	Headers            []string
+	IgnoredQueryParams []string
+	SortQuery          bool
+	IgnoredBodyPaths   []string
+}
+
+func NewFingerprintPolicyFromView(data views.FingerprintPolicyView) *FingerprintPolicy {
+	return &FingerprintPolicy{
+		Headers:            data.Headers,
+		IgnoredQueryParams: data.IgnoredQueryParams,
+		SortQuery:          data.SortQuery,
+		IgnoredBodyPaths:   data.IgnoredBodyPaths,
+	}
+}
+
+func (p *FingerprintPolicy) ConvertToFingerprintPolicyView() *views.FingerprintPolicyView {
+	view := &views.FingerprintPolicyView{Headers: []string{}, IgnoredQueryParams: []string{}, IgnoredBodyPaths: []string{}}
+	if p != nil {
+		view.Headers = append(view.Headers, p.Headers...)
+		view.IgnoredQueryParams = append(view.IgnoredQueryParams, p.IgnoredQueryParams...)
+		view.SortQuery = p.SortQuery
+		view.IgnoredBodyPaths = append(view.IgnoredBodyPaths, p.IgnoredBodyPaths...)
+	}
+	return view
+}
+
+// IsDefault - policy that doesn't change how requests are fingerprinted
+func (p *FingerprintPolicy) IsDefault() bool {
+	return p == nil || (len(p.Headers) == 0 && len(p.IgnoredQueryParams) == 0 && !p.SortQuery && len(p.IgnoredBodyPaths) == 0)
+}
+
+// Validate - checks that header names are not empty and ignored body paths can be parsed
+func (p *FingerprintPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	for _, header := range p.Headers {
+		if strings.TrimSpace(header) == "" {
+			return fmt.Errorf("fingerprint policy headers can't be empty")
+		}
+	}
+
+	for _, path := range p.IgnoredBodyPaths {
+		if err := ValidateBodyPath(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *FingerprintPolicy) ignoredBodyPaths() []string {
+	if p == nil {
+		return nil
+	}
+	return p.IgnoredBodyPaths
+}
+
+// query - returns raw query without ignored parameters, sorted if required. Parameters are kept
+// in their original encoding.
+func (p *FingerprintPolicy) query(rawQuery string) string {
+	if p == nil || rawQuery == "" || (len(p.IgnoredQueryParams) == 0 && !p.SortQuery) {
+		return rawQuery
+	}
+
+	ignored := make(map[string]bool)
+	for _, param := range p.IgnoredQueryParams {
+		ignored[param] = true
+	}
+
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		key := strings.SplitN(param, "=", 2)[0]
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if !ignored[key] {
+			params = append(params, param)
+		}
+	}
+
+	if p.SortQuery {
+		sort.Strings(params)
+	}
+
+	return strings.Join(params, "&")
+}
+
+// headers - returns values of headers included in fingerprint, header names are case insensitive
+func (p *FingerprintPolicy) headers(headers map[string][]string) string {
+	if p == nil || len(p.Headers) == 0 {
+		return ""
+	}
+
+	canonical := make(http.Header)
+	for k, v := range headers {
+		canonical[http.CanonicalHeaderKey(k)] = append(canonical[http.CanonicalHeaderKey(k)], v...)
+	}
+
+	names := make([]string, 0, len(p.Headers))
+	for _, name := range p.Headers {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	sort.Strings(names)
+
+	var buffer bytes.Buffer
+	for _, name := range names {
+		buffer.WriteString(name)
+		buffer.WriteString(":")
+		buffer.WriteString(strings.Join(canonical[name], ","))
+		buffer.WriteString(";")
+	}
+	return buffer.String()
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFingerprint_WithoutPolicyIsSameAsHash(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := RequestDetails{Path: "/items", Method: "GET", Destination: "api.service.com", Query: "b=2&a=1"}
+
+	Expect(r.Fingerprint(nil, true)).To(Equal(r.Hash()))
+	Expect(r.Fingerprint(nil, false)).To(Equal(r.HashWithoutHost()))
+	Expect(r.Fingerprint(&FingerprintPolicy{}, true)).To(Equal(r.Hash()))
+}
+
+func TestFingerprint_IncludesSelectedHeadersCaseInsensitively(t *testing.T) {
+	RegisterTestingT(t)
+
+	policy := &FingerprintPolicy{Headers: []string{"x-tenant"}}
+
+	one := RequestDetails{Path: "/items", Method: "GET", Headers: map[string][]string{"X-Tenant": {"one"}, "User-Agent": {"curl"}}}
+	oneAgain := RequestDetails{Path: "/items", Method: "GET", Headers: map[string][]string{"x-tenant": {"one"}, "User-Agent": {"wget"}}}
+	two := RequestDetails{Path: "/items", Method: "GET", Headers: map[string][]string{"X-Tenant": {"two"}}}
+
+	Expect(one.Fingerprint(policy, true)).To(Equal(oneAgain.Fingerprint(policy, true)))
+	Expect(one.Fingerprint(policy, true)).ToNot(Equal(two.Fingerprint(policy, true)))
+
+	// headers are ignored by default
+	Expect(one.Hash()).To(Equal(two.Hash()))
+}
+
+func TestFingerprint_DropsIgnoredQueryParams(t *testing.T) {
+	RegisterTestingT(t)
+
+	policy := &FingerprintPolicy{IgnoredQueryParams: []string{"_ts", "nonce"}}
+
+	one := RequestDetails{Path: "/items", Method: "GET", Query: "page=1&_ts=123&nonce=abc"}
+	two := RequestDetails{Path: "/items", Method: "GET", Query: "_ts=456&page=1"}
+	other := RequestDetails{Path: "/items", Method: "GET", Query: "page=2&_ts=123"}
+
+	Expect(one.Fingerprint(policy, true)).To(Equal(two.Fingerprint(policy, true)))
+	Expect(one.Fingerprint(policy, true)).ToNot(Equal(other.Fingerprint(policy, true)))
+}
+
+func TestFingerprint_SortsQueryParams(t *testing.T) {
+	RegisterTestingT(t)
+
+	one := RequestDetails{Path: "/items", Method: "GET", Query: "b=2&a=1"}
+	two := RequestDetails{Path: "/items", Method: "GET", Query: "a=1&b=2"}
+
+	Expect(one.Hash()).ToNot(Equal(two.Hash()))
+	Expect(one.Fingerprint(&FingerprintPolicy{SortQuery: true}, true)).To(Equal(two.Fingerprint(&FingerprintPolicy{SortQuery: true}, true)))
+}
+
+func TestFingerprintPolicy_ConvertsToViewAndBack(t *testing.T) {
+	RegisterTestingT(t)
+
+	policy := &FingerprintPolicy{Headers: []string{"Accept"}, IgnoredQueryParams: []string{"_ts"}, SortQuery: true, IgnoredBodyPaths: []string{"$.id"}}
+
+	Expect(NewFingerprintPolicyFromView(*policy.ConvertToFingerprintPolicyView())).To(Equal(policy))
+	Expect((*FingerprintPolicy)(nil).IsDefault()).To(BeTrue())
+	Expect(policy.IsDefault()).To(BeFalse())
+}
+
+func TestFingerprintPolicy_Validate(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect((*FingerprintPolicy)(nil).Validate()).To(BeNil())
+	Expect((&FingerprintPolicy{Headers: []string{"Accept"}, IgnoredBodyPaths: []string{"$.items[*].id"}}).Validate()).To(BeNil())
+	Expect((&FingerprintPolicy{Headers: []string{""}}).Validate()).ToNot(BeNil())
+	Expect((&FingerprintPolicy{IgnoredBodyPaths: []string{"$"}}).Validate()).ToNot(BeNil())
+	Expect((&FingerprintPolicy{IgnoredBodyPaths: []string{"items[x]"}}).Validate()).ToNot(BeNil())
+}
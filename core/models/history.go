@@ -0,0 +1,81 @@
+package models
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// capture policies decide what happens when a request that was already captured is captured again
+const (
+	// CapturePolicyOverwrite - new response replaces the stored one
+	CapturePolicyOverwrite = "overwrite"
+	// CapturePolicyKeepFirst - the first captured response is kept
+	CapturePolicyKeepFirst = "keep-first"
+	// CapturePolicyAppend - new response becomes active and every captured response is kept in history
+	CapturePolicyAppend = "append"
+)
+
+// MaxCaptureHistorySize - number of payloads kept in history of one request, oldest are dropped first
+const MaxCaptureHistorySize = 100
+
+// ValidateCapturePolicy - returns error for unknown capture policies
+func ValidateCapturePolicy(policy string) error {
+	switch policy {
+	case CapturePolicyOverwrite, CapturePolicyKeepFirst, CapturePolicyAppend:
+		return nil
+	}
+	return fmt.Errorf("capture policy '%s' is not supported, available policies: %s, %s, %s",
+		policy, CapturePolicyOverwrite, CapturePolicyKeepFirst, CapturePolicyAppend)
+}
+
+// CaptureHistoryEntry - payload captured at given time
+type CaptureHistoryEntry struct {
+	CapturedAt time.Time
+	Payload    Payload
+}
+
+// CaptureHistory - every payload captured for one request, oldest first
+type CaptureHistory []CaptureHistoryEntry
+
+// Encode method encodes all exported CaptureHistory fields to bytes
+func (h CaptureHistory) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := gob.NewEncoder(buf)
+	err := enc.Encode(h)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewCaptureHistoryFromBytes decodes supplied bytes into CaptureHistory structure
+func NewCaptureHistoryFromBytes(data []byte) (CaptureHistory, error) {
+	var h CaptureHistory
+	dec := gob.NewDecoder(bytes.NewBuffer(data))
+	if err := dec.Decode(&h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ConvertToCaptureHistoryView - entry equal to the active payload is marked as active
+func (h CaptureHistory) ConvertToCaptureHistoryView(key string, active *Payload) *views.CaptureHistoryView {
+	view := &views.CaptureHistoryView{
+		Key:     key,
+		Entries: []views.CaptureHistoryEntryView{},
+	}
+	for i, entry := range h {
+		view.Entries = append(view.Entries, views.CaptureHistoryEntryView{
+			Index:      i,
+			CapturedAt: entry.CapturedAt.Format(time.RFC3339Nano),
+			Active:     active != nil && reflect.DeepEqual(entry.Payload, *active),
+			Payload:    *entry.Payload.ConvertToPayloadView(),
+		})
+	}
+	return view
+}
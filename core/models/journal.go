@@ -0,0 +1,314 @@
+package models
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/cache"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// match outcomes of journal entries
+const (
+	// JournalMatched - stored response was found for the request
+	JournalMatched = "matched"
+	// JournalMissed - no stored response was found for the request
+	JournalMissed = "missed"
+)
+
+// DefaultJournalSize - how many entries journal keeps by default
+const DefaultJournalSize = 1000
+
+// JournalEntry - request proxied by Hoverfly and how it was answered. Match is only set in modes that
+// look up stored responses, MatchedID is the ID of the matched capture or the index of the matched
+// template.
+type JournalEntry struct {
+	Time           time.Time
+	Mode           string
+	Method         string
+	Scheme         string
+	Destination    string
+	Path           string
+	Query          string
+	Headers        map[string][]string
+	Body           string
+	Match          string
+	MatchedID      string
+	MissReason     string
+	Status         int
+	Delay          time.Duration
+	MiddlewareTime time.Duration
+	Latency        time.Duration
+}
+
+// Encode - encodes journal entry with gob
+func (this *JournalEntry) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(this); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewJournalEntryFromBytes - decodes supplied bytes into JournalEntry
+func NewJournalEntryFromBytes(data []byte) (*JournalEntry, error) {
+	var entry JournalEntry
+	if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (this *JournalEntry) ConvertToJournalEntryView() views.JournalEntryView {
+	return views.JournalEntryView{
+		Time:           this.Time.Format(time.RFC3339Nano),
+		Mode:           this.Mode,
+		Method:         this.Method,
+		Scheme:         this.Scheme,
+		Destination:    this.Destination,
+		Path:           this.Path,
+		Query:          this.Query,
+		Headers:        this.Headers,
+		Body:           this.Body,
+		Match:          this.Match,
+		MatchedID:      this.MatchedID,
+		MissReason:     this.MissReason,
+		Status:         this.Status,
+		Delay:          int64(this.Delay / time.Millisecond),
+		MiddlewareTime: int64(this.MiddlewareTime / time.Millisecond),
+		Latency:        int64(this.Latency / time.Millisecond),
+	}
+}
+
+// ConvertToRequestDetails - returns the journaled request
+func (this *JournalEntry) ConvertToRequestDetails() RequestDetails {
+	return RequestDetails{
+		Method:      this.Method,
+		Scheme:      this.Scheme,
+		Destination: this.Destination,
+		Path:        this.Path,
+		Query:       this.Query,
+		Headers:     this.Headers,
+		Body:        this.Body,
+	}
+}
+
+// JournalFilter - selects journal entries, zero fields match everything. Destination and Path are
+// regular expressions, status range is inclusive.
+type JournalFilter struct {
+	From        time.Time
+	To          time.Time
+	Destination string
+	Path        string
+	StatusFrom  int
+	StatusTo    int
+	Match       string
+
+	patterns requestPatterns
+}
+
+// NewJournalFilter - validates given filter and compiles its patterns
+func NewJournalFilter(from, to time.Time, destination, path string, statusFrom, statusTo int, match string) (*JournalFilter, error) {
+	filter := &JournalFilter{
+		From:        from,
+		To:          to,
+		Destination: destination,
+		Path:        path,
+		StatusFrom:  statusFrom,
+		StatusTo:    statusTo,
+		Match:       match,
+	}
+
+	if err := filter.patterns.compile("journal", destination, path); err != nil {
+		return nil, err
+	}
+
+	if statusTo != 0 && statusFrom > statusTo {
+		return nil, fmt.Errorf("journal status range %d-%d is empty", statusFrom, statusTo)
+	}
+	if match != "" && match != JournalMatched && match != JournalMissed {
+		return nil, fmt.Errorf("journal match must be '%s' or '%s', got '%s'", JournalMatched, JournalMissed, match)
+	}
+	return filter, nil
+}
+
+// Matches - returns whether given entry is selected by the filter
+func (this *JournalFilter) Matches(entry *JournalEntry) bool {
+	if this == nil {
+		return true
+	}
+	if !this.From.IsZero() && entry.Time.Before(this.From) {
+		return false
+	}
+	if !this.To.IsZero() && entry.Time.After(this.To) {
+		return false
+	}
+	if !this.patterns.Matches(entry.Destination, entry.Path) {
+		return false
+	}
+	if this.StatusFrom != 0 && entry.Status < this.StatusFrom {
+		return false
+	}
+	if this.StatusTo != 0 && entry.Status > this.StatusTo {
+		return false
+	}
+	if this.Match != "" && entry.Match != this.Match {
+		return false
+	}
+	return true
+}
+
+// Journal - keeps last Size entries, oldest entries are dropped first. Entries are persisted to the
+// store when journal has one, so they survive restarts.
+type Journal struct {
+	Size int
+
+	entries []JournalEntry
+	start   int
+	next    uint64
+	store   cache.Cache
+	mu      sync.Mutex
+}
+
+// NewJournal - returns journal keeping size entries, journal with size 0 keeps nothing. Entries
+// persisted in given store are loaded, store can be nil.
+func NewJournal(size int, store cache.Cache) *Journal {
+	if size < 0 {
+		size = 0
+	}
+
+	journal := &Journal{Size: size, store: store}
+	if store != nil {
+		journal.load()
+	}
+	return journal
+}
+
+// journalKey - entries are stored under their sequence number, padded so keys sort in order
+func journalKey(seq uint64) []byte {
+	return []byte(fmt.Sprintf("%020d", seq))
+}
+
+func (this *Journal) load() {
+	stored, err := this.store.GetAllEntries()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to load journal")
+		return
+	}
+
+	var keys []string
+	for key := range stored {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		if seq, err := strconv.ParseUint(key, 10, 64); err == nil && seq >= this.next {
+			this.next = seq + 1
+		}
+
+		if i < len(keys)-this.Size {
+			this.store.Delete([]byte(key))
+			continue
+		}
+
+		entry, err := NewJournalEntryFromBytes(stored[key])
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"key":   key,
+			}).Warn("Failed to decode journal entry")
+			continue
+		}
+		this.entries = append(this.entries, *entry)
+	}
+}
+
+// Add - adds entry to the journal, dropping the oldest entry when journal is full
+func (this *Journal) Add(entry JournalEntry) {
+	if this == nil || this.Size == 0 {
+		return
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if len(this.entries) < this.Size {
+		this.entries = append(this.entries, entry)
+	} else {
+		this.entries[this.start] = entry
+		this.start = (this.start + 1) % this.Size
+	}
+
+	seq := this.next
+	this.next++
+	if this.store == nil {
+		return
+	}
+
+	if seq >= uint64(this.Size) {
+		this.store.Delete(journalKey(seq - uint64(this.Size)))
+	}
+	bts, err := entry.Encode()
+	if err == nil {
+		err = this.store.Set(journalKey(seq), bts)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Failed to persist journal entry")
+	}
+}
+
+// Entries - returns entries selected by filter, oldest entry first
+func (this *Journal) Entries(filter *JournalFilter) []JournalEntry {
+	entries := []JournalEntry{}
+	if this == nil {
+		return entries
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	for i := range this.entries {
+		entry := &this.entries[(this.start+i)%len(this.entries)]
+		if filter.Matches(entry) {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// Clear - removes all entries from the journal and its store
+func (this *Journal) Clear() error {
+	if this == nil {
+		return nil
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	this.entries = nil
+	this.start = 0
+	if this.store != nil {
+		return this.store.DeleteData()
+	}
+	return nil
+}
+
+// ConvertToJournalView - returns entries selected by filter, oldest entry first
+func (this *Journal) ConvertToJournalView(filter *JournalFilter) *views.JournalView {
+	view := &views.JournalView{Data: []views.JournalEntryView{}}
+	for _, entry := range this.Entries(filter) {
+		view.Data = append(view.Data, entry.ConvertToJournalEntryView())
+	}
+	return view
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/cache"
+	. "github.com/onsi/gomega"
+)
+
+func journalEntryAt(seconds int64, destination string, status int) JournalEntry {
+	return JournalEntry{Time: time.Unix(seconds, 0), Destination: destination, Path: "/", Status: status}
+}
+
+func TestJournal_DropsOldestEntries(t *testing.T) {
+	RegisterTestingT(t)
+
+	journal := NewJournal(2, nil)
+	journal.Add(journalEntryAt(1, "a.com", 200))
+	journal.Add(journalEntryAt(2, "b.com", 200))
+	journal.Add(journalEntryAt(3, "c.com", 200))
+
+	entries := journal.Entries(nil)
+	Expect(entries).To(HaveLen(2))
+	Expect(entries[0].Destination).To(Equal("b.com"))
+	Expect(entries[1].Destination).To(Equal("c.com"))
+}
+
+func TestJournal_WithZeroSizeKeepsNothing(t *testing.T) {
+	RegisterTestingT(t)
+
+	journal := NewJournal(0, nil)
+	journal.Add(journalEntryAt(1, "a.com", 200))
+
+	Expect(journal.Entries(nil)).To(BeEmpty())
+}
+
+func TestJournal_IsPersistedInStore(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := cache.NewInMemoryCache()
+	journal := NewJournal(2, store)
+	journal.Add(journalEntryAt(1, "a.com", 200))
+	journal.Add(journalEntryAt(2, "b.com", 200))
+	journal.Add(journalEntryAt(3, "c.com", 200))
+
+	count, err := store.RecordsCount()
+	Expect(err).To(BeNil())
+	Expect(count).To(Equal(2))
+
+	loaded := NewJournal(1, store)
+	entries := loaded.Entries(nil)
+	Expect(entries).To(HaveLen(1))
+	Expect(entries[0].Destination).To(Equal("c.com"))
+	Expect(entries[0].Time.Equal(time.Unix(3, 0))).To(BeTrue())
+
+	loaded.Add(journalEntryAt(4, "d.com", 200))
+	Expect(NewJournal(10, store).Entries(nil)).To(HaveLen(1))
+
+	Expect(loaded.Clear()).To(BeNil())
+	Expect(loaded.Entries(nil)).To(BeEmpty())
+	Expect(NewJournal(10, store).Entries(nil)).To(BeEmpty())
+}
+
+func TestJournalFilter_Matches(t *testing.T) {
+	RegisterTestingT(t)
+
+	entry := journalEntryAt(100, "api.com", 404)
+	entry.Match = JournalMissed
+
+	filter, err := NewJournalFilter(time.Unix(50, 0), time.Unix(150, 0), "^api", "^/$", 400, 499, JournalMissed)
+	Expect(err).To(BeNil())
+	Expect(filter.Matches(&entry)).To(BeTrue())
+
+	filter, err = NewJournalFilter(time.Unix(101, 0), time.Time{}, "", "", 0, 0, "")
+	Expect(err).To(BeNil())
+	Expect(filter.Matches(&entry)).To(BeFalse())
+
+	filter, err = NewJournalFilter(time.Time{}, time.Time{}, "other", "", 0, 0, "")
+	Expect(err).To(BeNil())
+	Expect(filter.Matches(&entry)).To(BeFalse())
+
+	filter, err = NewJournalFilter(time.Time{}, time.Time{}, "", "", 500, 599, "")
+	Expect(err).To(BeNil())
+	Expect(filter.Matches(&entry)).To(BeFalse())
+
+	filter, err = NewJournalFilter(time.Time{}, time.Time{}, "", "", 0, 0, JournalMatched)
+	Expect(err).To(BeNil())
+	Expect(filter.Matches(&entry)).To(BeFalse())
+}
+
+func TestNewJournalFilter_Validates(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewJournalFilter(time.Time{}, time.Time{}, "*", "", 0, 0, "")
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewJournalFilter(time.Time{}, time.Time{}, "", "", 500, 400, "")
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewJournalFilter(time.Time{}, time.Time{}, "", "", 0, 0, "maybe")
+	Expect(err).ToNot(BeNil())
+}
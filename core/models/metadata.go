@@ -0,0 +1,112 @@
+package models
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// CaptureMetadata - describes when and how a payload was captured
+type CaptureMetadata struct {
+	// ID - stable identifier of the captured payload
+	ID         string
+	CapturedAt time.Time
+	// Latency - time between sending the request upstream and receiving response headers, middleware excluded
+	Latency    time.Duration
+	RemoteAddr string
+	TLS        *TLSDetails
+	Mode       string
+	Tags       []string
+}
+
+// TLSDetails - TLS connection to upstream that the response was received on
+type TLSDetails struct {
+	Version            string
+	CipherSuite        string
+	ServerName         string
+	NegotiatedProtocol string
+}
+
+// NewTLSDetails - returns nil for responses that were not received over TLS
+func NewTLSDetails(state *tls.ConnectionState) *TLSDetails {
+	if state == nil {
+		return nil
+	}
+
+	return &TLSDetails{
+		Version:            tls.VersionName(state.Version),
+		CipherSuite:        fmt.Sprintf("0x%04X", state.CipherSuite),
+		ServerName:         state.ServerName,
+		NegotiatedProtocol: state.NegotiatedProtocol,
+	}
+}
+
+// HasTag - returns whether payload was captured with given tag
+func (m *CaptureMetadata) HasTag(tag string) bool {
+	if m == nil {
+		return false
+	}
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CaptureMetadata) ConvertToCaptureMetadataView() *views.CaptureMetadataView {
+	if m == nil {
+		return nil
+	}
+
+	view := &views.CaptureMetadataView{
+		ID:         m.ID,
+		CapturedAt: m.CapturedAt.UTC().Format(time.RFC3339Nano),
+		Latency:    float64(m.Latency) / float64(time.Millisecond),
+		RemoteAddr: m.RemoteAddr,
+		Mode:       m.Mode,
+		Tags:       append([]string{}, m.Tags...),
+	}
+
+	if m.TLS != nil {
+		view.TLS = &views.TLSDetailsView{
+			Version:            m.TLS.Version,
+			CipherSuite:        m.TLS.CipherSuite,
+			ServerName:         m.TLS.ServerName,
+			NegotiatedProtocol: m.TLS.NegotiatedProtocol,
+		}
+	}
+
+	return view
+}
+
+// NewCaptureMetadataFromView - metadata of imported payloads, capture time that can't be parsed is left empty
+func NewCaptureMetadataFromView(data *views.CaptureMetadataView) *CaptureMetadata {
+	if data == nil {
+		return nil
+	}
+
+	capturedAt, _ := time.Parse(time.RFC3339Nano, data.CapturedAt)
+
+	m := &CaptureMetadata{
+		ID:         data.ID,
+		CapturedAt: capturedAt,
+		Latency:    time.Duration(data.Latency * float64(time.Millisecond)),
+		RemoteAddr: data.RemoteAddr,
+		Mode:       data.Mode,
+		Tags:       data.Tags,
+	}
+
+	if data.TLS != nil {
+		m.TLS = &TLSDetails{
+			Version:            data.TLS.Version,
+			CipherSuite:        data.TLS.CipherSuite,
+			ServerName:         data.TLS.ServerName,
+			NegotiatedProtocol: data.TLS.NegotiatedProtocol,
+		}
+	}
+
+	return m
+}
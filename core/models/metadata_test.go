@@ -0,0 +1,71 @@
+package models
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestNewTLSDetails(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(NewTLSDetails(nil)).To(BeNil())
+
+	details := NewTLSDetails(&tls.ConnectionState{
+		Version:            tls.VersionTLS12,
+		CipherSuite:        tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		ServerName:         "api.service.com",
+		NegotiatedProtocol: "h2",
+	})
+	Expect(details.Version).To(Equal("TLS 1.2"))
+	Expect(details.CipherSuite).To(Equal("0xC02F"))
+	Expect(details.ServerName).To(Equal("api.service.com"))
+	Expect(details.NegotiatedProtocol).To(Equal("h2"))
+
+	Expect(NewTLSDetails(&tls.ConnectionState{Version: tls.VersionTLS13}).Version).To(Equal("TLS 1.3"))
+	Expect(NewTLSDetails(&tls.ConnectionState{Version: 0x0305}).Version).To(Equal("0x0305"))
+}
+
+func TestCaptureMetadata_ConvertsToViewAndBack(t *testing.T) {
+	RegisterTestingT(t)
+
+	metadata := &CaptureMetadata{
+		ID:         "0d5f1bd4-3c58-4ef6-a3e7-2a0d1e4bd0a4",
+		CapturedAt: time.Date(2016, 5, 10, 12, 30, 0, 0, time.UTC),
+		Latency:    125500 * time.Microsecond,
+		RemoteAddr: "127.0.0.1:54321",
+		TLS:        &TLSDetails{Version: "TLS 1.2", CipherSuite: "0xC02F"},
+		Mode:       "capture",
+		Tags:       []string{"run-1"},
+	}
+
+	view := metadata.ConvertToCaptureMetadataView()
+	Expect(view.CapturedAt).To(Equal("2016-05-10T12:30:00Z"))
+	Expect(view.Latency).To(Equal(125.5))
+	Expect(view.TLS.Version).To(Equal("TLS 1.2"))
+
+	Expect(NewCaptureMetadataFromView(view)).To(Equal(metadata))
+}
+
+func TestPayload_ConvertToPayloadView_WithoutMetadata(t *testing.T) {
+	RegisterTestingT(t)
+
+	payload := Payload{Request: RequestDetails{Path: "/"}}
+
+	Expect(payload.ConvertToPayloadView().Metadata).To(BeNil())
+	Expect(NewPayloadFromPayloadView(views.PayloadView{}).Metadata).To(BeNil())
+}
+
+func TestCaptureMetadata_HasTag(t *testing.T) {
+	RegisterTestingT(t)
+
+	var missing *CaptureMetadata
+	Expect(missing.HasTag("run-1")).To(BeFalse())
+
+	metadata := &CaptureMetadata{Tags: []string{"run-1", "smoke"}}
+	Expect(metadata.HasTag("smoke")).To(BeTrue())
+	Expect(metadata.HasTag("run-2")).To(BeFalse())
+}
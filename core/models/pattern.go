@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// compileOptionalPattern - compiles regular expression of given kind of rule, empty pattern matches
+// everything and compiles to nil
+func compileOptionalPattern(kind, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s pattern is not a valid regular expression string: %s", kind, pattern)
+	}
+	return rx, nil
+}
+
+// requestPatterns - optional destination and path regular expressions selecting requests a rule applies to
+type requestPatterns struct {
+	destination *regexp.Regexp
+	path        *regexp.Regexp
+}
+
+// compile - compiles destination and path patterns of given kind of rule
+func (this *requestPatterns) compile(kind, destination, path string) error {
+	var err error
+	if this.destination, err = compileOptionalPattern(kind, destination); err != nil {
+		return err
+	}
+	this.path, err = compileOptionalPattern(kind, path)
+	return err
+}
+
+// Matches - returns whether request with given destination and path is selected by the patterns
+func (this *requestPatterns) Matches(destination, path string) bool {
+	if this.destination != nil && !this.destination.MatchString(destination) {
+		return false
+	}
+	if this.path != nil && !this.path.MatchString(path) {
+		return false
+	}
+	return true
+}
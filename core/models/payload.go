@@ -7,41 +7,26 @@ import (
 	"encoding/base64"
 	"encoding/gob"
 	"io"
-	"regexp"
 	log "github.com/Sirupsen/logrus"
 	"net/http"
 	"strings"
-	"github.com/tdewolff/minify"
-	"github.com/tdewolff/minify/json"
-	"github.com/tdewolff/minify/xml"
 	"github.com/SpectoLabs/hoverfly/core/views"
 )
 
-const (
-	contentTypeJSON = "application/json"
-	contentTypeXML = "application/xml"
-	otherType = "otherType"
-)
-
 var (
-	rxJSON = regexp.MustCompile("[/+]json$")
-	rxXML = regexp.MustCompile("[/+]xml$")
 	// mime types which will not be base 64 encoded when exporting as JSON
 	supportedMimeTypes = [...]string{"text", "plain", "css", "html", "json", "xml", "js", "javascript"}
-	minifiers * minify.M
 )
 
-func init() {
-	// GetNewMinifiers - sets minify.M with prepared xml/json minifiers
-	minifiers = minify.New()
-	minifiers.AddFuncRegexp(regexp.MustCompile("[/+]xml$"), xml.Minify)
-	minifiers.AddFuncRegexp(regexp.MustCompile("[/+]json$"), json.Minify)
-}
-
 // Payload structure holds request and response structure
 type Payload struct {
 	Response ResponseDetails `json:"response"`
 	Request  RequestDetails  `json:"request"`
+	Metadata *CaptureMetadata `json:"metadata,omitempty"`
+	// Error - set instead of the response when upstream couldn't be reached during capture
+	Error *UpstreamError `json:"error,omitempty"`
+	// Throttle - set on payloads of matching request templates, slows down delivery of the response
+	Throttle *Throttle `json:"-"`
 }
 
 func (p Payload) Id() string {
@@ -64,7 +49,12 @@ func (p *Payload) Encode() ([]byte, error) {
 }
 
 func (p *Payload) ConvertToPayloadView() (*views.PayloadView) {
-	return &views.PayloadView{Response: p.Response.ConvertToResponseDetailsView(), Request: p.Request.ConvertToRequestDetailsView()}
+	return &views.PayloadView{
+		Response: p.Response.ConvertToResponseDetailsView(),
+		Request: p.Request.ConvertToRequestDetailsView(),
+		Metadata: p.Metadata.ConvertToCaptureMetadataView(),
+		Error: p.Error.ConvertToUpstreamErrorView(),
+	}
 }
 
 // NewPayloadFromBytes decodes supplied bytes into Payload structure
@@ -83,6 +73,8 @@ func NewPayloadFromPayloadView(data views.PayloadView) (Payload) {
 	return Payload{
 		Response: NewResponseDetialsFromResponseDetailsView(data.Response),
 		Request: NewRequestDetailsFromRequestDetailsView(data.Request),
+		Metadata: NewCaptureMetadataFromView(data.Metadata),
+		Error: NewUpstreamErrorFromView(data.Error),
 	}
 }
 
@@ -121,7 +113,7 @@ func (r *RequestDetails) ConvertToRequestDetailsView() (views.RequestDetailsView
 	}
 }
 
-func (r *RequestDetails) concatenate(withHost bool) string {
+func (r *RequestDetails) concatenate(withHost bool, policy *FingerprintPolicy) string {
 	var buffer bytes.Buffer
 
 	if withHost {
@@ -130,61 +122,65 @@ func (r *RequestDetails) concatenate(withHost bool) string {
 
 	buffer.WriteString(r.Path)
 	buffer.WriteString(r.Method)
-	buffer.WriteString(r.Query)
+	buffer.WriteString(policy.query(r.Query))
+	buffer.WriteString(policy.headers(r.Headers))
 	if len(r.Body) > 0 {
-		ct := r.getContentType()
-
-		if ct == contentTypeJSON || ct == contentTypeXML {
-			buffer.WriteString(r.minifyBody(ct))
-		} else {
-			log.WithFields(log.Fields{
-				"content-type": r.Headers["Content-Type"],
-			}).Debug("unknown content type")
-
-			buffer.WriteString(r.Body)
-		}
+		buffer.WriteString(r.canonicalBody(policy))
 	}
 
 	return buffer.String()
 }
 
-func (r *RequestDetails) minifyBody(mediaType string) (minified string) {
+// canonicalBody - returns body in a form that doesn't depend on formatting, key or field order
+// for JSON, XML and form bodies. Other bodies are returned as they are.
+func (r *RequestDetails) canonicalBody(policy *FingerprintPolicy) string {
+	mediaType, params := getMediaType(r.Headers["Content-Type"])
+
+	var canonical string
 	var err error
-	minified, err = minifiers.String(mediaType, r.Body)
+
+	switch mediaType {
+	case contentTypeJSON:
+		canonical, err = canonicalJSON(r.Body, policy.ignoredBodyPaths())
+	case contentTypeXML:
+		canonical, err = canonicalXML(r.Body)
+	case contentTypeForm:
+		canonical, err = canonicalForm(r.Body)
+	case contentTypeMultipart:
+		canonical, err = canonicalMultipart(r.Body, params["boundary"])
+	default:
+		log.WithFields(log.Fields{
+			"content-type": r.Headers["Content-Type"],
+		}).Debug("unknown content type")
+		return r.Body
+	}
+
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error":       err.Error(),
 			"destination": r.Destination,
 			"path":        r.Path,
 			"method":      r.Method,
-		}).Errorf("failed to minify request body, media type given: %s. Request matching might fail", mediaType)
+		}).Errorf("failed to canonicalise request body, media type given: %s. Request matching might fail", mediaType)
 		return r.Body
 	}
-	log.Debugf("body minified, mediatype: %s", mediaType)
-	return minified
+	log.Debugf("body canonicalised, mediatype: %s", mediaType)
+	return canonical
 }
 
 func (r *RequestDetails) Hash() string {
-	h := md5.New()
-	io.WriteString(h, r.concatenate(true))
-	return fmt.Sprintf("%x", h.Sum(nil))
+	return r.Fingerprint(nil, true)
 }
 func (r *RequestDetails) HashWithoutHost() string {
-	h := md5.New()
-	io.WriteString(h, r.concatenate(false))
-	return fmt.Sprintf("%x", h.Sum(nil))
+	return r.Fingerprint(nil, false)
 }
 
-func (r *RequestDetails) getContentType() string {
-	for _, v := range r.Headers["Content-Type"] {
-		if rxJSON.MatchString(v) {
-			return contentTypeJSON
-		}
-		if rxXML.MatchString(v) {
-			return contentTypeXML
-		}
-	}
-	return otherType
+// Fingerprint - returns request hash built according to given policy, nil policy gives the same
+// hash as Hash and HashWithoutHost
+func (r *RequestDetails) Fingerprint(policy *FingerprintPolicy, withHost bool) string {
+	h := md5.New()
+	io.WriteString(h, r.concatenate(withHost, policy))
+	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
 // ResponseDetails structure hold response body from external service, body is not decoded and is supposed
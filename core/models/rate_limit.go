@@ -0,0 +1,225 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// RateLimit - token bucket allowing Limit requests per Period to destinations and paths matching given
+// regular expressions, empty patterns match everything. When KeyHeader is set, every value of the
+// header (i.e. API key) has its own bucket.
+type RateLimit struct {
+	Destination string
+	Path        string
+	KeyHeader   string
+	Limit       int
+	Period      time.Duration
+
+	requestPatterns
+}
+
+// NewRateLimit - validates given rate limit and compiles its patterns
+func NewRateLimit(destination, path, keyHeader string, limit int, period time.Duration) (*RateLimit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("rate limit must be positive, got %d", limit)
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("rate limit period must be positive, got %v", period)
+	}
+
+	rateLimit := &RateLimit{
+		Destination: destination,
+		Path:        path,
+		KeyHeader:   http.CanonicalHeaderKey(keyHeader),
+		Limit:       limit,
+		Period:      period,
+	}
+
+	if err := rateLimit.compile("rate limit", destination, path); err != nil {
+		return nil, err
+	}
+	return rateLimit, nil
+}
+
+// rate - tokens added to the bucket per second
+func (this *RateLimit) rate() float64 {
+	return float64(this.Limit) / this.Period.Seconds()
+}
+
+// RateLimitDecision - outcome of a request taking a token from its bucket
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+type rateLimitBucket struct {
+	limit   int
+	key     string
+	tokens  float64
+	updated time.Time
+}
+
+// refill - adds tokens earned since the bucket was last updated
+func (b *rateLimitBucket) refill(rateLimit *RateLimit, now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(rateLimit.Limit), b.tokens+elapsed*rateLimit.rate())
+		b.updated = now
+	}
+}
+
+// reset - time until bucket is full again
+func (b *rateLimitBucket) reset(rateLimit *RateLimit) time.Duration {
+	return secondsToDuration((float64(rateLimit.Limit) - b.tokens) / rateLimit.rate())
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// RateLimiter - rate limits and state of their buckets, first matching rate limit applies to a request
+type RateLimiter struct {
+	Limits []RateLimit
+
+	buckets map[string]*rateLimitBucket
+	mu      sync.Mutex
+}
+
+// NewRateLimiter - returns rate limiter with full buckets
+func NewRateLimiter(limits []RateLimit) *RateLimiter {
+	return &RateLimiter{
+		Limits:  limits,
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+func NewRateLimiterFromView(data views.RateLimitsView) (*RateLimiter, error) {
+	var limits []RateLimit
+	for _, view := range data.Data {
+		rateLimit, err := NewRateLimit(view.Destination, view.Path, view.KeyHeader, view.Limit, time.Duration(view.Period)*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, *rateLimit)
+	}
+	return NewRateLimiter(limits), nil
+}
+
+func (this *RateLimiter) ConvertToRateLimitsView() *views.RateLimitsView {
+	view := &views.RateLimitsView{Data: []views.RateLimitView{}}
+	if this == nil {
+		return view
+	}
+
+	for _, rateLimit := range this.Limits {
+		view.Data = append(view.Data, views.RateLimitView{
+			Destination: rateLimit.Destination,
+			Path:        rateLimit.Path,
+			KeyHeader:   rateLimit.KeyHeader,
+			Limit:       rateLimit.Limit,
+			Period:      int(rateLimit.Period / time.Second),
+		})
+	}
+	return view
+}
+
+// ConvertToRateLimitStateView - returns state of buckets that were used, sorted by rate limit and key
+func (this *RateLimiter) ConvertToRateLimitStateView(now time.Time) *views.RateLimitStateView {
+	view := &views.RateLimitStateView{Data: []views.RateLimitBucketView{}}
+	if this == nil {
+		return view
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	var ids []string
+	for id := range this.buckets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		bucket := this.buckets[id]
+		rateLimit := &this.Limits[bucket.limit]
+		bucket.refill(rateLimit, now)
+
+		view.Data = append(view.Data, views.RateLimitBucketView{
+			Destination: rateLimit.Destination,
+			Path:        rateLimit.Path,
+			KeyHeader:   rateLimit.KeyHeader,
+			Key:         bucket.key,
+			Limit:       rateLimit.Limit,
+			Remaining:   int(bucket.tokens),
+			Reset:       int64(math.Ceil(bucket.reset(rateLimit).Seconds())),
+		})
+	}
+	return view
+}
+
+// IsEmpty - rate limiter that doesn't limit anything
+func (this *RateLimiter) IsEmpty() bool {
+	return this == nil || len(this.Limits) == 0
+}
+
+// Reset - fills all buckets
+func (this *RateLimiter) Reset() {
+	if this == nil {
+		return
+	}
+
+	this.mu.Lock()
+	this.buckets = make(map[string]*rateLimitBucket)
+	this.mu.Unlock()
+}
+
+// Take - takes a token from bucket of the request, returns nil when request isn't rate limited
+func (this *RateLimiter) Take(destination, path string, headers http.Header, now time.Time) *RateLimitDecision {
+	if this.IsEmpty() {
+		return nil
+	}
+
+	for i := range this.Limits {
+		rateLimit := &this.Limits[i]
+		if !rateLimit.Matches(destination, path) {
+			continue
+		}
+
+		key := ""
+		if rateLimit.KeyHeader != "" {
+			key = headers.Get(rateLimit.KeyHeader)
+		}
+
+		this.mu.Lock()
+		defer this.mu.Unlock()
+
+		id := fmt.Sprintf("%04d:%s", i, key)
+		bucket, ok := this.buckets[id]
+		if !ok {
+			bucket = &rateLimitBucket{limit: i, key: key, tokens: float64(rateLimit.Limit), updated: now}
+			this.buckets[id] = bucket
+		}
+		bucket.refill(rateLimit, now)
+
+		decision := &RateLimitDecision{Limit: rateLimit.Limit}
+		if bucket.tokens >= 1 {
+			bucket.tokens--
+			decision.Allowed = true
+		} else {
+			decision.RetryAfter = secondsToDuration((1 - bucket.tokens) / rateLimit.rate())
+		}
+		decision.Remaining = int(bucket.tokens)
+		decision.Reset = bucket.reset(rateLimit)
+		return decision
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestNewRateLimit_Validates(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewRateLimit("api.com", "^/users", "X-Api-Key", 10, time.Minute)
+	Expect(err).To(BeNil())
+
+	_, err = NewRateLimit("*", "", "", 10, time.Minute)
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewRateLimit("", "", "", 0, time.Minute)
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewRateLimit("", "", "", 10, 0)
+	Expect(err).ToNot(BeNil())
+}
+
+func TestRateLimiter_TakeEmptiesAndRefillsBucket(t *testing.T) {
+	RegisterTestingT(t)
+
+	rateLimit, err := NewRateLimit("api.com", "", "", 2, 10*time.Second)
+	Expect(err).To(BeNil())
+	limiter := NewRateLimiter([]RateLimit{*rateLimit})
+
+	now := time.Unix(1000, 0)
+
+	decision := limiter.Take("api.com", "/", http.Header{}, now)
+	Expect(decision.Allowed).To(BeTrue())
+	Expect(decision.Remaining).To(Equal(1))
+
+	decision = limiter.Take("api.com", "/", http.Header{}, now)
+	Expect(decision.Allowed).To(BeTrue())
+	Expect(decision.Remaining).To(Equal(0))
+	Expect(decision.Reset).To(Equal(10 * time.Second))
+
+	decision = limiter.Take("api.com", "/", http.Header{}, now)
+	Expect(decision.Allowed).To(BeFalse())
+	Expect(decision.Limit).To(Equal(2))
+	Expect(decision.RetryAfter).To(Equal(5 * time.Second))
+
+	// one token is refilled every 5 seconds
+	decision = limiter.Take("api.com", "/", http.Header{}, now.Add(5*time.Second))
+	Expect(decision.Allowed).To(BeTrue())
+
+	Expect(limiter.Take("other.com", "/", http.Header{}, now)).To(BeNil())
+}
+
+func TestRateLimiter_KeyHeaderValuesHaveOwnBuckets(t *testing.T) {
+	RegisterTestingT(t)
+
+	rateLimit, err := NewRateLimit("", "", "x-api-key", 1, time.Minute)
+	Expect(err).To(BeNil())
+	limiter := NewRateLimiter([]RateLimit{*rateLimit})
+
+	now := time.Unix(1000, 0)
+	first := http.Header{"X-Api-Key": {"first"}}
+	second := http.Header{"X-Api-Key": {"second"}}
+
+	Expect(limiter.Take("api.com", "/", first, now).Allowed).To(BeTrue())
+	Expect(limiter.Take("api.com", "/", first, now).Allowed).To(BeFalse())
+	Expect(limiter.Take("api.com", "/", second, now).Allowed).To(BeTrue())
+
+	state := limiter.ConvertToRateLimitStateView(now)
+	Expect(state.Data).To(HaveLen(2))
+	Expect(state.Data[0].Key).To(Equal("first"))
+	Expect(state.Data[0].KeyHeader).To(Equal("X-Api-Key"))
+	Expect(state.Data[0].Remaining).To(Equal(0))
+	Expect(state.Data[0].Reset).To(Equal(int64(60)))
+	Expect(state.Data[1].Key).To(Equal("second"))
+}
+
+func TestRateLimiter_ResetFillsBuckets(t *testing.T) {
+	RegisterTestingT(t)
+
+	rateLimit, err := NewRateLimit("", "", "", 1, time.Minute)
+	Expect(err).To(BeNil())
+	limiter := NewRateLimiter([]RateLimit{*rateLimit})
+
+	now := time.Unix(1000, 0)
+	Expect(limiter.Take("api.com", "/", http.Header{}, now).Allowed).To(BeTrue())
+	Expect(limiter.Take("api.com", "/", http.Header{}, now).Allowed).To(BeFalse())
+
+	limiter.Reset()
+	Expect(limiter.ConvertToRateLimitStateView(now).Data).To(BeEmpty())
+	Expect(limiter.Take("api.com", "/", http.Header{}, now).Allowed).To(BeTrue())
+}
+
+func TestRateLimiter_ConvertsFromAndToView(t *testing.T) {
+	RegisterTestingT(t)
+
+	limiter, err := NewRateLimiterFromView(views.RateLimitsView{
+		Data: []views.RateLimitView{{Destination: "api.com", KeyHeader: "authorization", Limit: 100, Period: 60}},
+	})
+	Expect(err).To(BeNil())
+	Expect(limiter.Limits[0].Period).To(Equal(time.Minute))
+
+	view := limiter.ConvertToRateLimitsView()
+	Expect(view.Data).To(Equal([]views.RateLimitView{{Destination: "api.com", KeyHeader: "Authorization", Limit: 100, Period: 60}}))
+
+	_, err = NewRateLimiterFromView(views.RateLimitsView{Data: []views.RateLimitView{{Limit: 100}}})
+	Expect(err).ToNot(BeNil())
+
+	var empty *RateLimiter
+	Expect(empty.ConvertToRateLimitsView().Data).To(BeEmpty())
+}
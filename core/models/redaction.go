@@ -0,0 +1,274 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// DefaultRedactionPlaceholder - value that redacted secrets are replaced with
+const DefaultRedactionPlaceholder = "[REDACTED]"
+
+// RedactionRules - describes secrets that are replaced with a placeholder before payloads are stored:
+// header values, query parameter values, values at JSON paths and body parts matching regular
+// expressions. When a pattern has capturing groups, only the groups are replaced.
+type RedactionRules struct {
+	Headers      []string
+	QueryParams  []string
+	BodyPaths    []string
+	BodyPatterns []string
+	Placeholder  string
+
+	paths    [][]bodyPathSegment
+	patterns []*regexp.Regexp
+}
+
+// NewRedactionRules - validates given rules and prepares them to be applied
+func NewRedactionRules(headers, queryParams, bodyPaths, bodyPatterns []string, placeholder string) (*RedactionRules, error) {
+	if placeholder == "" {
+		placeholder = DefaultRedactionPlaceholder
+	}
+
+	r := &RedactionRules{
+		Headers:      headers,
+		QueryParams:  queryParams,
+		BodyPaths:    bodyPaths,
+		BodyPatterns: bodyPatterns,
+		Placeholder:  placeholder,
+	}
+
+	for _, path := range bodyPaths {
+		segments, err := parseBodyPath(path)
+		if err != nil {
+			return nil, err
+		}
+		r.paths = append(r.paths, segments)
+	}
+
+	for _, pattern := range bodyPatterns {
+		rx, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction body pattern is not a valid regular expression string: %s", pattern)
+		}
+		r.patterns = append(r.patterns, rx)
+	}
+
+	return r, nil
+}
+
+func NewRedactionRulesFromView(data views.RedactionRulesView) (*RedactionRules, error) {
+	return NewRedactionRules(data.Headers, data.QueryParams, data.BodyPaths, data.BodyPatterns, data.Placeholder)
+}
+
+func (r *RedactionRules) ConvertToRedactionRulesView() *views.RedactionRulesView {
+	view := &views.RedactionRulesView{
+		Headers:      []string{},
+		QueryParams:  []string{},
+		BodyPaths:    []string{},
+		BodyPatterns: []string{},
+		Placeholder:  DefaultRedactionPlaceholder,
+	}
+	if r != nil {
+		view.Headers = append(view.Headers, r.Headers...)
+		view.QueryParams = append(view.QueryParams, r.QueryParams...)
+		view.BodyPaths = append(view.BodyPaths, r.BodyPaths...)
+		view.BodyPatterns = append(view.BodyPatterns, r.BodyPatterns...)
+		view.Placeholder = r.Placeholder
+	}
+	return view
+}
+
+// IsEmpty - rules that don't redact anything
+func (r *RedactionRules) IsEmpty() bool {
+	return r == nil || (len(r.Headers) == 0 && len(r.QueryParams) == 0 && len(r.paths) == 0 && len(r.patterns) == 0)
+}
+
+// Redact - replaces secrets in both request and response of given payload
+func (r *RedactionRules) Redact(payload *Payload) {
+	r.RedactRequest(&payload.Request)
+	r.RedactResponse(&payload.Response)
+}
+
+// RedactRequest - replaces secrets in given request, applying rules to a request that was already
+// redacted doesn't change it, so incoming requests can be redacted before they are matched
+func (r *RedactionRules) RedactRequest(request *RequestDetails) {
+	if r.IsEmpty() {
+		return
+	}
+	request.Headers = r.redactHeaders(request.Headers)
+	request.Query = r.redactQuery(request.Query)
+	request.Body = r.redactBody(request.Body, request.Headers)
+}
+
+// RedactResponse - replaces secrets in given response
+func (r *RedactionRules) RedactResponse(response *ResponseDetails) {
+	if r.IsEmpty() {
+		return
+	}
+	response.Headers = r.redactHeaders(response.Headers)
+	response.Body = r.redactBody(response.Body, response.Headers)
+}
+
+func (r *RedactionRules) redactHeaders(headers map[string][]string) map[string][]string {
+	if len(r.Headers) == 0 || len(headers) == 0 {
+		return headers
+	}
+
+	redacted := make(map[string][]string, len(headers))
+	for k, values := range headers {
+		redacted[k] = values
+		for _, name := range r.Headers {
+			if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(k) {
+				redacted[k] = make([]string, len(values))
+				for i := range values {
+					redacted[k][i] = r.Placeholder
+				}
+				break
+			}
+		}
+	}
+	return redacted
+}
+
+func (r *RedactionRules) redactQuery(rawQuery string) string {
+	if len(r.QueryParams) == 0 || rawQuery == "" {
+		return rawQuery
+	}
+
+	redacted := make(map[string]bool)
+	for _, param := range r.QueryParams {
+		redacted[param] = true
+	}
+
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key := strings.SplitN(param, "=", 2)[0]
+		unescaped, err := url.QueryUnescape(key)
+		if err != nil {
+			unescaped = key
+		}
+		if redacted[unescaped] {
+			params[i] = key + "=" + url.QueryEscape(r.Placeholder)
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func (r *RedactionRules) redactBody(body string, headers map[string][]string) string {
+	if body == "" {
+		return body
+	}
+
+	if len(r.paths) > 0 {
+		if mediaType, _ := getMediaType(headers["Content-Type"]); mediaType == contentTypeJSON {
+			body = r.redactJSON(body)
+		}
+	}
+
+	for _, rx := range r.patterns {
+		body = rx.ReplaceAllStringFunc(body, func(match string) string {
+			return r.redactMatch(rx, match)
+		})
+	}
+
+	return body
+}
+
+// redactJSON - replaces values at body paths, body is re-encoded only when something was replaced
+func (r *RedactionRules) redactJSON(body string) string {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return body
+	}
+
+	replaced := false
+	for _, segments := range r.paths {
+		if replaceBodyPath(document, segments, r.Placeholder) {
+			replaced = true
+		}
+	}
+	if !replaced {
+		return body
+	}
+
+	b, err := json.Marshal(document)
+	if err != nil {
+		return body
+	}
+	return string(b)
+}
+
+// redactMatch - replaces capturing groups of the match, or the whole match when there are none
+func (r *RedactionRules) redactMatch(rx *regexp.Regexp, match string) string {
+	if rx.NumSubexp() == 0 {
+		return r.Placeholder
+	}
+
+	indexes := rx.FindStringSubmatchIndex(match)
+	if indexes == nil {
+		return match
+	}
+
+	var redacted string
+	last := 0
+	for group := 1; group <= rx.NumSubexp(); group++ {
+		start, end := indexes[2*group], indexes[2*group+1]
+		if start < last || start < 0 {
+			continue
+		}
+		redacted += match[last:start] + r.Placeholder
+		last = end
+	}
+	return redacted + match[last:]
+}
+
+// replaceBodyPath - replaces values at given path with placeholder, returns whether anything was replaced
+func replaceBodyPath(value interface{}, segments []bodyPathSegment, placeholder string) bool {
+	if len(segments) == 0 {
+		return false
+	}
+
+	segment, last := segments[0], len(segments) == 1
+	replaced := false
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if segment.isIndex {
+			return false
+		}
+		if child, ok := v[segment.key]; ok {
+			if last {
+				v[segment.key] = placeholder
+				replaced = true
+			} else {
+				replaced = replaceBodyPath(child, segments[1:], placeholder)
+			}
+		}
+
+	case []interface{}:
+		if !segment.isIndex {
+			return false
+		}
+		for i, child := range v {
+			if segment.index != -1 && segment.index != i {
+				continue
+			}
+			if last {
+				v[i] = placeholder
+				replaced = true
+			} else if replaceBodyPath(child, segments[1:], placeholder) {
+				replaced = true
+			}
+		}
+	}
+
+	return replaced
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewRedactionRules_ValidatesPathsAndPatterns(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewRedactionRules(nil, nil, []string{"$"}, nil, "")
+	Expect(err).ToNot(BeNil())
+
+	_, err = NewRedactionRules(nil, nil, nil, []string{"(unclosed"}, "")
+	Expect(err).ToNot(BeNil())
+
+	rules, err := NewRedactionRules([]string{"Authorization"}, nil, nil, nil, "")
+	Expect(err).To(BeNil())
+	Expect(rules.Placeholder).To(Equal(DefaultRedactionPlaceholder))
+}
+
+func TestRedactionRules_RedactsHeadersQueryAndBody(t *testing.T) {
+	RegisterTestingT(t)
+
+	rules, err := NewRedactionRules(
+		[]string{"authorization", "Set-Cookie"},
+		[]string{"api_key"},
+		[]string{"$.credentials.password"},
+		[]string{`token=(\w+)`},
+		"***")
+	Expect(err).To(BeNil())
+
+	payload := Payload{
+		Request: RequestDetails{
+			Query: "page=1&api_key=secret",
+			Body:  `{"credentials": {"user": "bob", "password": "hunter2"}}`,
+			Headers: map[string][]string{
+				"Authorization": {"Bearer abc"},
+				"Content-Type":  {"application/json"},
+			},
+		},
+		Response: ResponseDetails{
+			Body:    "token=abc123&expires=10",
+			Headers: map[string][]string{"Set-Cookie": {"a=1", "b=2"}},
+		},
+	}
+
+	rules.Redact(&payload)
+
+	Expect(payload.Request.Headers["Authorization"]).To(Equal([]string{"***"}))
+	Expect(payload.Request.Headers["Content-Type"]).To(Equal([]string{"application/json"}))
+	Expect(payload.Request.Query).To(Equal("page=1&api_key=%2A%2A%2A"))
+	Expect(payload.Request.Body).To(Equal(`{"credentials":{"password":"***","user":"bob"}}`))
+	Expect(payload.Response.Headers["Set-Cookie"]).To(Equal([]string{"***", "***"}))
+	Expect(payload.Response.Body).To(Equal("token=***&expires=10"))
+}
+
+func TestRedactionRules_RedactingTwiceGivesTheSameRequest(t *testing.T) {
+	RegisterTestingT(t)
+
+	rules, err := NewRedactionRules([]string{"Authorization"}, []string{"api_key"}, []string{"$.password"}, []string{`secret-\d+`}, "")
+	Expect(err).To(BeNil())
+
+	request := RequestDetails{
+		Query:   "api_key=abc",
+		Body:    `{"password": "x", "note": "secret-123"}`,
+		Headers: map[string][]string{"Authorization": {"Basic xyz"}, "Content-Type": {"application/json"}},
+	}
+
+	rules.RedactRequest(&request)
+	once := request
+	rules.RedactRequest(&request)
+
+	Expect(request).To(Equal(once))
+}
+
+func TestRedactionRules_DoesNotChangeBodyWithoutMatches(t *testing.T) {
+	RegisterTestingT(t)
+
+	rules, err := NewRedactionRules(nil, nil, []string{"$.password"}, nil, "")
+	Expect(err).To(BeNil())
+
+	request := RequestDetails{
+		Body:    `{ "user":   "bob" }`,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+	}
+	rules.RedactRequest(&request)
+
+	Expect(request.Body).To(Equal(`{ "user":   "bob" }`))
+}
+
+func TestRedactionRules_NilRulesDoNothing(t *testing.T) {
+	RegisterTestingT(t)
+
+	var rules *RedactionRules
+	payload := Payload{Request: RequestDetails{Query: "api_key=abc"}}
+
+	rules.Redact(&payload)
+
+	Expect(payload.Request.Query).To(Equal("api_key=abc"))
+	Expect(rules.IsEmpty()).To(BeTrue())
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Throttle - slows down requests matching UrlPattern and HttpMethod. Response body is delivered at
+// ResponseBytesPerSecond and, when ChunkSize is set, in chunks of ChunkSize bytes ChunkDelay
+// milliseconds apart with chunked transfer encoding. Request body is read at RequestBytesPerSecond.
+type Throttle struct {
+	UrlPattern             string `json:"urlPattern,omitempty"`
+	HttpMethod             string `json:"httpMethod,omitempty"`
+	ResponseBytesPerSecond int    `json:"responseBytesPerSecond,omitempty"`
+	ChunkSize              int    `json:"chunkSize,omitempty"`
+	ChunkDelay             int    `json:"chunkDelay,omitempty"`
+	RequestBytesPerSecond  int    `json:"requestBytesPerSecond,omitempty"`
+}
+
+type ThrottleJson struct {
+	Data *ThrottleList `json:"data"`
+}
+
+type ThrottleList []Throttle
+
+type Throttles interface {
+	Json() []byte
+	GetThrottle(url, httpMethod string) *Throttle
+	Len() int
+}
+
+func ValidateThrottleJson(j ThrottleJson) error {
+	if j.Data != nil {
+		for _, throttle := range *j.Data {
+			if throttle.UrlPattern == "" {
+				return errors.New(fmt.Sprintf("Config error - Missing url pattern in: %v", throttle))
+			}
+			if _, err := regexp.Compile(throttle.UrlPattern); err != nil {
+				return errors.New(fmt.Sprintf("Throttle entry skipped due to invalid pattern : %s", throttle.UrlPattern))
+			}
+			if err := throttle.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate - checks limits of the throttle, it is also used for throttles of request templates
+func (this *Throttle) Validate() error {
+	if this.ResponseBytesPerSecond < 0 || this.ChunkSize < 0 || this.ChunkDelay < 0 || this.RequestBytesPerSecond < 0 {
+		return errors.New(fmt.Sprintf("Config error - throttle limits can't be negative in: %v", *this))
+	}
+	if this.ResponseBytesPerSecond == 0 && this.ChunkSize == 0 && this.RequestBytesPerSecond == 0 {
+		return errors.New(fmt.Sprintf("Config error - Missing values found in: %v", *this))
+	}
+	if this.ChunkDelay != 0 && this.ChunkSize == 0 {
+		return errors.New(fmt.Sprintf("Config error - chunk delay requires chunk size in: %v", *this))
+	}
+	return nil
+}
+
+// ThrottlesResponse - returns whether response body delivery is slowed down
+func (this *Throttle) ThrottlesResponse() bool {
+	return this != nil && (this.ResponseBytesPerSecond > 0 || this.ChunkSize > 0)
+}
+
+// ThrottlesRequest - returns whether request body is read slowly
+func (this *Throttle) ThrottlesRequest() bool {
+	return this != nil && this.RequestBytesPerSecond > 0
+}
+
+// GetChunkDelay - returns pause between response body chunks
+func (this *Throttle) GetChunkDelay() time.Duration {
+	return time.Duration(this.ChunkDelay) * time.Millisecond
+}
+
+func (this *ThrottleList) GetThrottle(url, httpMethod string) *Throttle {
+	for _, val := range *this {
+		match := regexp.MustCompile(val.UrlPattern).MatchString(url)
+		if match {
+			if val.HttpMethod == "" || strings.EqualFold(val.HttpMethod, httpMethod) {
+				return &val
+			}
+		}
+	}
+	return nil
+}
+
+func (this *ThrottleList) Json() []byte {
+	resp := ThrottleJson{
+		Data: this,
+	}
+	b, _ := json.Marshal(resp)
+	return b
+}
+
+func (this *ThrottleList) Len() int {
+	if this == nil {
+		return 0
+	}
+	return len(*this)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValidateThrottleJson(t *testing.T) {
+	RegisterTestingT(t)
+
+	valid := []string{
+		`{"data": [{"urlPattern": ".", "responseBytesPerSecond": 1024}]}`,
+		`{"data": [{"urlPattern": "api", "httpMethod": "POST", "requestBytesPerSecond": 512}]}`,
+		`{"data": [{"urlPattern": ".", "chunkSize": 16, "chunkDelay": 100}]}`,
+	}
+	for _, conf := range valid {
+		var throttleJson ThrottleJson
+		Expect(json.Unmarshal([]byte(conf), &throttleJson)).To(BeNil())
+		Expect(ValidateThrottleJson(throttleJson)).To(BeNil(), conf)
+	}
+
+	invalid := []string{
+		`{"data": [{"responseBytesPerSecond": 1024}]}`,
+		`{"data": [{"urlPattern": "*", "responseBytesPerSecond": 1024}]}`,
+		`{"data": [{"urlPattern": "."}]}`,
+		`{"data": [{"urlPattern": ".", "responseBytesPerSecond": -1}]}`,
+		`{"data": [{"urlPattern": ".", "responseBytesPerSecond": 1024, "chunkDelay": 100}]}`,
+	}
+	for _, conf := range invalid {
+		var throttleJson ThrottleJson
+		Expect(json.Unmarshal([]byte(conf), &throttleJson)).To(BeNil())
+		Expect(ValidateThrottleJson(throttleJson)).ToNot(BeNil(), conf)
+	}
+}
+
+func TestThrottleList_GetThrottleMatchesPatternAndMethod(t *testing.T) {
+	RegisterTestingT(t)
+
+	throttles := ThrottleList{
+		{UrlPattern: "upload", HttpMethod: "POST", RequestBytesPerSecond: 10},
+		{UrlPattern: "api.com", ResponseBytesPerSecond: 100},
+	}
+
+	Expect(throttles.GetThrottle("http://api.com/upload", "post").RequestBytesPerSecond).To(Equal(10))
+	Expect(throttles.GetThrottle("http://api.com/upload", "GET").ResponseBytesPerSecond).To(Equal(100))
+	Expect(throttles.GetThrottle("http://other.com/", "GET")).To(BeNil())
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// types of transport errors received from upstream
+const (
+	UpstreamErrorDNS               = "dns"
+	UpstreamErrorConnectionRefused = "connection-refused"
+	UpstreamErrorConnectionReset   = "connection-reset"
+	UpstreamErrorTimeout           = "timeout"
+	UpstreamErrorTLS               = "tls"
+)
+
+// UpstreamError - transport error that was received from upstream instead of a response during capture
+type UpstreamError struct {
+	Type    string
+	Message string
+}
+
+func (e *UpstreamError) ConvertToUpstreamErrorView() *views.UpstreamErrorView {
+	if e == nil {
+		return nil
+	}
+	return &views.UpstreamErrorView{
+		Type:    e.Type,
+		Message: e.Message,
+	}
+}
+
+func NewUpstreamErrorFromView(data *views.UpstreamErrorView) *UpstreamError {
+	if data == nil {
+		return nil
+	}
+	return &UpstreamError{
+		Type:    data.Type,
+		Message: data.Message,
+	}
+}
@@ -119,7 +119,7 @@ func TestMatchOnRequestBody(t *testing.T) {
 		c := NewConstructor(request, payload)
 		response := c.ReconstructResponse()
 
-		dbClient.save(request, requestBody, response, []byte(resp.Body))
+		dbClient.save(request, requestBody, response, []byte(resp.Body), nil)
 	}
 
 	// now getting responses
@@ -350,7 +350,7 @@ func TestDoRequestWFailedMiddleware(t *testing.T) {
 	req, err := http.NewRequest("POST", "http://capture_body.com", body)
 	Expect(err).To(BeNil())
 
-	_, _, err = dbClient.doRequest(req)
+	_, _, _, err = dbClient.doRequest(req)
 	Expect(err).ToNot(BeNil())
 }
 
@@ -368,7 +368,7 @@ func TestDoRequestFailedHTTP(t *testing.T) {
 	req, err := http.NewRequest("POST", "http://capture_body.com", body)
 	Expect(err).To(BeNil())
 
-	_, _, err = dbClient.doRequest(req)
+	_, _, _, err = dbClient.doRequest(req)
 	Expect(err).ToNot(BeNil())
 
 }
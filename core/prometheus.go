@@ -0,0 +1,33 @@
+package hoverfly
+
+import (
+	"io"
+
+	"github.com/SpectoLabs/hoverfly/core/metrics"
+	"github.com/SpectoLabs/hoverfly/core/models"
+)
+
+// recordRequestMetrics - counts processed request, its match outcome and time spent in its middleware
+func (hf *Hoverfly) recordRequestMetrics(entry *models.JournalEntry) {
+	hf.Counter.CountRequest(entry.Destination, entry.Status)
+	if entry.Match != "" {
+		hf.Counter.CountMatch(entry.Match)
+	}
+	if entry.MiddlewareTime > 0 {
+		hf.Counter.RecordMiddleware(entry.MiddlewareTime)
+	}
+}
+
+// WritePrometheusMetrics - writes request metrics and numbers of stored records and templates in
+// Prometheus text format
+func (hf *Hoverfly) WritePrometheusMetrics(w io.Writer) error {
+	count, err := hf.RequestCache.RecordsCount()
+	if err != nil {
+		return err
+	}
+
+	hf.Counter.WritePrometheus(w)
+	metrics.WritePrometheusGauge(w, "hoverfly_records", "Records stored in the cache.", float64(count))
+	metrics.WritePrometheusGauge(w, "hoverfly_templates", "Request templates stored by Hoverfly.", float64(len(hf.RequestMatcher.TemplateStore)))
+	return nil
+}
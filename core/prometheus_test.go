@@ -0,0 +1,49 @@
+package hoverfly
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func TestPrometheusMetricsHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/found", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: "found"},
+	})
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	for _, path := range []string{"/found", "/missing"} {
+		r, err := http.NewRequest("GET", "http://somehost.com"+path, nil)
+		Expect(err).To(BeNil())
+		dbClient.processRequest(r)
+	}
+
+	req, err := http.NewRequest("GET", "/metrics", nil)
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(rec.Header().Get("Content-Type")).To(HavePrefix("text/plain"))
+
+	body, err := ioutil.ReadAll(rec.Body)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(ContainSubstring(`hoverfly_requests_total{destination="somehost.com",status="200"} 1`))
+	Expect(string(body)).To(ContainSubstring(`hoverfly_requests_total{destination="somehost.com",status="412"} 1`))
+	Expect(string(body)).To(ContainSubstring(`hoverfly_matches_total{outcome="matched"} 1`))
+	Expect(string(body)).To(ContainSubstring(`hoverfly_matches_total{outcome="missed"} 1`))
+	Expect(string(body)).To(ContainSubstring("hoverfly_records 1"))
+	Expect(string(body)).To(ContainSubstring("hoverfly_templates 0"))
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	log "github.com/Sirupsen/logrus"
 	"github.com/rusenask/goproxy"
+	"io"
 	"net"
 	"net/http"
 	"regexp"
@@ -89,20 +90,42 @@ func NewProxy(hoverfly *Hoverfly) *goproxy.ProxyHttpServer {
 func NewWebserverProxy(hoverfly *Hoverfly) *goproxy.ProxyHttpServer {
 	// creating proxy
 	proxy := goproxy.NewProxyHttpServer()
+
+	// upstream is validated when proxy is started
+	rp, _ := newReverseProxy(hoverfly.Cfg.WebserverUpstream)
+
 	proxy.NonproxyHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Warn("NonproxyHandler")
+
+		clientHost := r.Host
+		clientScheme := "http"
+		if r.TLS != nil {
+			clientScheme = "https"
+		}
+
+		if destination, ok := hoverfly.Cfg.virtualHostDestination(r.Host); ok {
+			r.Host = destination
+		}
+
+		if rp != nil {
+			rp.rewriteRequest(r)
+		}
+
 		req, resp := hoverfly.processRequest(r)
-		body, err := extractBody(resp)
 
-		if err != nil {
-			log.Error("Error reading response body")
-			w.WriteHeader(500)
-			return
+		if rp != nil {
+			rp.rewriteResponse(resp, clientScheme, clientHost)
+		}
+
+		hoverfly.Counter.Count(hoverfly.Cfg.GetMode())
+
+		if err := writeWebserverResponse(w, resp); err != nil {
+			log.WithFields(log.Fields{
+				"error":       err.Error(),
+				"path":        req.URL.Path,
+				"destination": req.Host,
+			}).Error("Failed to write response to client")
 		}
-		w.Header().Set("Req", req.RequestURI)
-		w.Header().Set("Resp", resp.Header.Get("Content-Length"))
-		w.WriteHeader(resp.StatusCode)
-		w.Write(body)
 	})
 
 	if hoverfly.Cfg.Verbose {
@@ -121,9 +144,61 @@ func NewWebserverProxy(hoverfly *Hoverfly) *goproxy.ProxyHttpServer {
 
 	log.WithFields(log.Fields{
 		"Destination":   hoverfly.Cfg.Destination,
+		"Upstream":      hoverfly.Cfg.WebserverUpstream,
 		"WebserverPort": hoverfly.Cfg.ProxyPort,
 		"Mode":          hoverfly.Cfg.GetMode(),
 	}).Info("Webserver prepared...")
 
 	return proxy
 }
+
+// writeWebserverResponse - writes response to the client the same way the proxy does: all header
+// values are kept, bodies of unknown length are flushed as they are read so streamed responses
+// reach client straight away and trailers are sent once body is written
+func writeWebserverResponse(w http.ResponseWriter, resp *http.Response) error {
+	header := w.Header()
+	for k, values := range resp.Header {
+		header[k] = append([]string(nil), values...)
+	}
+	for k := range resp.Trailer {
+		header.Add("Trailer", k)
+	}
+
+	w.WriteHeader(resp.StatusCode)
+
+	if resp.Body == nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	var flusher http.Flusher
+	if _, throttled := resp.Body.(*throttledBody); throttled || resp.ContentLength < 0 {
+		flusher, _ = w.(http.Flusher)
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
+				return writeErr
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// trailer values are only known after the body has been read
+	for k, values := range resp.Trailer {
+		header[k] = append([]string(nil), values...)
+	}
+
+	return nil
+}
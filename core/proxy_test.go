@@ -0,0 +1,117 @@
+package hoverfly
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func TestWebserverProxy_WritesRecordedResponseHeaders(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.SetMode(SimulateMode)
+
+	payload := models.Payload{
+		Request: models.RequestDetails{
+			Path:        "/users",
+			Method:      "GET",
+			Destination: "api.service.com",
+		},
+		Response: models.ResponseDetails{
+			Status: 201,
+			Body:   `{"users":[]}`,
+			Headers: map[string][]string{
+				"Content-Type":  {"application/json"},
+				"Cache-Control": {"no-cache"},
+				"Set-Cookie":    {"a=1", "b=2"},
+			},
+		},
+	}
+	Expect(dbClient.RequestMatcher.SavePayload(&payload)).To(BeNil())
+
+	req, err := http.NewRequest("GET", "/users", nil)
+	Expect(err).To(BeNil())
+	req.Host = "localhost:8500"
+
+	rec := httptest.NewRecorder()
+	NewWebserverProxy(dbClient).ServeHTTP(rec, req)
+
+	Expect(rec.Code).To(Equal(201))
+	Expect(rec.Body.String()).To(Equal(`{"users":[]}`))
+	Expect(rec.HeaderMap.Get("Content-Type")).To(Equal("application/json"))
+	Expect(rec.HeaderMap.Get("Cache-Control")).To(Equal("no-cache"))
+	Expect(rec.HeaderMap["Set-Cookie"]).To(Equal([]string{"a=1", "b=2"}))
+	Expect(rec.HeaderMap).ToNot(HaveKey("Req"))
+	Expect(rec.HeaderMap).ToNot(HaveKey("Resp"))
+
+	Expect(dbClient.Counter.Counters[SimulateMode].Count()).To(Equal(int64(1)))
+}
+
+func TestWriteWebserverResponse_SendsTrailers(t *testing.T) {
+	RegisterTestingT(t)
+
+	resp := &http.Response{
+		StatusCode:    200,
+		Header:        http.Header{"Content-Type": {"text/plain"}},
+		Trailer:       http.Header{"Checksum": {"abc"}},
+		Body:          ioutil.NopCloser(strings.NewReader("body")),
+		ContentLength: -1,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeWebserverResponse(w, resp)
+	}))
+	defer server.Close()
+
+	clientResp, err := http.Get(server.URL)
+	Expect(err).To(BeNil())
+
+	body, err := ioutil.ReadAll(clientResp.Body)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("body"))
+	Expect(clientResp.Trailer.Get("Checksum")).To(Equal("abc"))
+}
+
+func TestWriteWebserverResponse_FlushesStreamedBody(t *testing.T) {
+	RegisterTestingT(t)
+
+	pr, pw := io.Pipe()
+	resp := &http.Response{
+		StatusCode:    200,
+		Header:        http.Header{},
+		Body:          pr,
+		ContentLength: -1,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeWebserverResponse(w, resp)
+	}))
+	defer server.Close()
+
+	go pw.Write([]byte("first chunk"))
+
+	clientResp, err := http.Get(server.URL)
+	Expect(err).To(BeNil())
+
+	// first chunk is received while upstream body is still open
+	buf := make([]byte, len("first chunk"))
+	_, err = io.ReadFull(clientResp.Body, buf)
+	Expect(err).To(BeNil())
+	Expect(string(buf)).To(Equal("first chunk"))
+
+	pw.Close()
+	rest, err := ioutil.ReadAll(clientResp.Body)
+	Expect(err).To(BeNil())
+	Expect(rest).To(BeEmpty())
+}
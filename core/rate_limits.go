@@ -0,0 +1,75 @@
+package hoverfly
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/rusenask/goproxy"
+)
+
+// GetRateLimiter - returns rate limits with state of their buckets, nil when nothing is rate limited
+func (hf *Hoverfly) GetRateLimiter() *models.RateLimiter {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	return hf.rateLimiter
+}
+
+// SetRateLimiter - sets rate limits applied to requests, buckets of previous limits are discarded
+func (hf *Hoverfly) SetRateLimiter(rateLimiter *models.RateLimiter) {
+	if rateLimiter.IsEmpty() {
+		rateLimiter = nil
+	}
+
+	hf.mu.Lock()
+	hf.rateLimiter = rateLimiter
+	hf.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"rateLimits": rateLimiter.ConvertToRateLimitsView().Data,
+	}).Info("rate limits updated")
+}
+
+// rateLimit - takes a token from bucket of the request. Returns 429 response when bucket is empty and
+// decision used to add quota headers to response when request is allowed.
+func (hf *Hoverfly) rateLimit(req *http.Request) (*http.Response, *models.RateLimitDecision) {
+	decision := hf.GetRateLimiter().Take(req.Host, req.URL.Path, req.Header, time.Now())
+	if decision == nil || decision.Allowed {
+		return nil, decision
+	}
+
+	log.WithFields(log.Fields{
+		"retryAfter":  decision.RetryAfter.String(),
+		"mode":        hf.Cfg.GetMode(),
+		"path":        req.URL.Path,
+		"destination": req.Host,
+	}).Warn("rate limit exceeded")
+
+	resp := goproxy.NewResponse(req, goproxy.ContentTypeText, http.StatusTooManyRequests,
+		fmt.Sprintf("Hoverfly Error! Rate limit of %d requests exceeded, retry in %d seconds \n", decision.Limit, ceilSeconds(decision.RetryAfter)))
+	resp.Header.Set("Retry-After", strconv.FormatInt(ceilSeconds(decision.RetryAfter), 10))
+	addRateLimitHeaders(resp, decision)
+	return resp, decision
+}
+
+// addRateLimitHeaders - adds quota headers commonly used by rate limited APIs
+func addRateLimitHeaders(resp *http.Response, decision *models.RateLimitDecision) {
+	if resp == nil || decision == nil {
+		return
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	resp.Header.Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+	resp.Header.Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(ceilSeconds(decision.Reset), 10))
+}
+
+// ceilSeconds - whole seconds clients have to wait, at least one when there is anything to wait for
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,108 @@
+package hoverfly
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func TestProcessRequest_RateLimitExceededReturns429(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	rateLimit, err := models.NewRateLimit("somehost.com", "^/users", "", 2, time.Minute)
+	Expect(err).To(BeNil())
+	dbClient.SetRateLimiter(models.NewRateLimiter([]models.RateLimit{*rateLimit}))
+
+	dbClient.Cfg.SetMode(CaptureMode)
+
+	for remaining := 1; remaining >= 0; remaining-- {
+		r, err := http.NewRequest("GET", "http://somehost.com/users", nil)
+		Expect(err).To(BeNil())
+
+		_, resp := dbClient.processRequest(r)
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		Expect(resp.Header.Get("X-RateLimit-Limit")).To(Equal("2"))
+		Expect(resp.Header.Get("X-RateLimit-Remaining")).To(Equal(strconv.Itoa(remaining)))
+	}
+
+	r, err := http.NewRequest("GET", "http://somehost.com/users", nil)
+	Expect(err).To(BeNil())
+
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+	Expect(resp.Header.Get("Retry-After")).To(Equal("30"))
+	Expect(resp.Header.Get("X-RateLimit-Remaining")).To(Equal("0"))
+	Expect(resp.Header.Get("X-RateLimit-Reset")).To(Equal("60"))
+
+	r, err = http.NewRequest("GET", "http://somehost.com/orders", nil)
+	Expect(err).To(BeNil())
+
+	_, resp = dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	Expect(resp.Header.Get("X-RateLimit-Limit")).To(BeEmpty())
+}
+
+func TestRateLimitHandlers(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/rate-limits", bytes.NewBufferString(`{"data": [{"limit": 1}]}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+
+	req, err = http.NewRequest("PUT", "/api/rate-limits", bytes.NewBufferString(`{"data": [{"keyHeader": "X-Api-Key", "limit": 1, "period": 60}]}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	r, err := http.NewRequest("GET", "http://somehost.com/users", nil)
+	Expect(err).To(BeNil())
+	r.Header.Set("X-Api-Key", "secret")
+	dbClient.processRequest(r)
+
+	req, err = http.NewRequest("GET", "/api/rate-limits/state", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(rec.Body.String()).To(ContainSubstring(`"key":"secret"`))
+	Expect(rec.Body.String()).To(ContainSubstring(`"remaining":0`))
+
+	req, err = http.NewRequest("DELETE", "/api/rate-limits/state", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetRateLimiter().ConvertToRateLimitStateView(time.Now()).Data).To(BeEmpty())
+
+	req, err = http.NewRequest("GET", "/api/rate-limits", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Body.String()).To(ContainSubstring(`"keyHeader":"X-Api-Key"`))
+
+	req, err = http.NewRequest("DELETE", "/api/rate-limits", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetRateLimiter()).To(BeNil())
+}
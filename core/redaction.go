@@ -0,0 +1,113 @@
+package hoverfly
+
+import (
+	"encoding/json"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/cache"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// redactionRulesKey - metadata key under which redaction rules are stored together with the simulation,
+// stored records are redacted before they are hashed so they only match requests redacted the same way
+const redactionRulesKey = "hoverfly.redactionRules"
+
+// GetRedactionRules - returns rules used to redact secrets from captured payloads, nil when nothing is redacted
+func (hf *Hoverfly) GetRedactionRules() *models.RedactionRules {
+	return hf.RequestMatcher.Redaction
+}
+
+// SetRedactionRules - sets rules used to redact secrets from captured payloads. Rules are also applied
+// to payloads that are already stored so they keep matching redacted requests, and stored with the simulation.
+func (hf *Hoverfly) SetRedactionRules(rules *models.RedactionRules) {
+	if rules.IsEmpty() {
+		rules = nil
+	}
+	hf.RequestMatcher.Redaction = rules
+
+	if rules != nil {
+		if b, err := json.Marshal(rules.ConvertToRedactionRulesView()); err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Failed to encode redaction rules, they were not stored with the simulation")
+		} else if err := hf.MetadataCache.Set([]byte(redactionRulesKey), b); err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("Failed to store redaction rules with the simulation")
+		}
+	} else {
+		hf.MetadataCache.Delete([]byte(redactionRulesKey))
+	}
+
+	if rules != nil {
+		// redacted payloads may be stored under new keys, so their hits can't be kept
+		redactStoredPayloads(hf)
+		hf.RequestMatcher.Coverage.ResetRecords()
+		rebuildCaptureHistory(hf)
+	}
+
+	log.WithFields(log.Fields{
+		"rules": rules.ConvertToRedactionRulesView(),
+	}).Info("redaction rules updated")
+}
+
+// redactStoredPayloads - redacts every stored payload, payloads are re-keyed when redaction changes their hash
+func redactStoredPayloads(hf *Hoverfly) {
+	entries, err := hf.RequestCache.GetAllEntries()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Unable to read stored payloads, they were not redacted")
+		return
+	}
+
+	for key, bts := range entries {
+		payload, err := models.NewPayloadFromBytes(bts)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"key":   key,
+			}).Error("Failed to decode payload")
+			continue
+		}
+
+		hf.RequestCache.Delete([]byte(key))
+		if err := hf.RequestMatcher.SavePayload(payload); err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"key":   key,
+			}).Error("Failed to save redacted payload")
+		}
+	}
+}
+
+// loadRedactionRules - reads redaction rules stored with the simulation
+func loadRedactionRules(metadataCache cache.Cache) *models.RedactionRules {
+	b, err := metadataCache.Get([]byte(redactionRulesKey))
+	if err != nil {
+		return nil
+	}
+
+	var view views.RedactionRulesView
+	if err := json.Unmarshal(b, &view); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+			"value": string(b),
+		}).Error("Failed to decode stored redaction rules, nothing will be redacted")
+		return nil
+	}
+
+	rules, err := models.NewRedactionRulesFromView(view)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+			"value": string(b),
+		}).Error("Stored redaction rules are not valid, nothing will be redacted")
+		return nil
+	}
+	if rules.IsEmpty() {
+		return nil
+	}
+	return rules
+}
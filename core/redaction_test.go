@@ -0,0 +1,166 @@
+package hoverfly
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestCapturedPayloadsAreRedactedAndStillMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	rules, err := models.NewRedactionRules([]string{"Authorization"}, []string{"api_key"}, nil, nil, "")
+	Expect(err).To(BeNil())
+	dbClient.SetRedactionRules(rules)
+	dbClient.RequestMatcher.FingerprintPolicy = &models.FingerprintPolicy{Headers: []string{"Authorization"}}
+
+	r, err := http.NewRequest("GET", "http://somehost.com/items?api_key=first-key", nil)
+	Expect(err).To(BeNil())
+	r.Header.Set("Authorization", "Bearer first-token")
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	dbClient.processRequest(r)
+
+	values, err := dbClient.RequestCache.GetAllValues()
+	Expect(err).To(BeNil())
+	Expect(values).To(HaveLen(1))
+
+	payload, err := models.NewPayloadFromBytes(values[0])
+	Expect(err).To(BeNil())
+	Expect(payload.Request.Query).To(Equal("api_key=%5BREDACTED%5D"))
+	Expect(payload.Request.Headers["Authorization"]).To(Equal([]string{"[REDACTED]"}))
+
+	// request with other secrets is matched with redacted payload
+	other, err := http.NewRequest("GET", "http://somehost.com/items?api_key=second-key", nil)
+	Expect(err).To(BeNil())
+	other.Header.Set("Authorization", "Bearer second-token")
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(other)
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+}
+
+func TestSetRedactionRules_RedactsStoredPayloads(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	r, err := http.NewRequest("GET", "http://somehost.com/items?api_key=first-key", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	dbClient.processRequest(r)
+
+	rules, err := models.NewRedactionRules(nil, []string{"api_key"}, nil, nil, "")
+	Expect(err).To(BeNil())
+	dbClient.SetRedactionRules(rules)
+
+	values, err := dbClient.RequestCache.GetAllValues()
+	Expect(err).To(BeNil())
+	Expect(values).To(HaveLen(1))
+
+	payload, err := models.NewPayloadFromBytes(values[0])
+	Expect(err).To(BeNil())
+	Expect(payload.Request.Query).To(Equal("api_key=%5BREDACTED%5D"))
+
+	other, err := http.NewRequest("GET", "http://somehost.com/items?api_key=second-key", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(other)
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+}
+
+func TestSetRedactionRules_StoresRulesWithSimulation(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	rules, err := models.NewRedactionRules([]string{"Authorization"}, []string{"api_key"}, nil, nil, "")
+	Expect(err).To(BeNil())
+	dbClient.SetRedactionRules(rules)
+
+	Expect(loadRedactionRules(dbClient.MetadataCache).ConvertToRedactionRulesView()).To(Equal(rules.ConvertToRedactionRulesView()))
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/records", nil)
+	Expect(err).To(BeNil())
+	getBoneRouter(dbClient).ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	var data views.PayloadViewData
+	Expect(json.Unmarshal(rec.Body.Bytes(), &data)).To(BeNil())
+	Expect(data.RedactionRules).To(Equal(rules.ConvertToRedactionRulesView()))
+
+	dbClient.SetRedactionRules(nil)
+	Expect(loadRedactionRules(dbClient.MetadataCache)).To(BeNil())
+}
+
+func TestImportRecordsAppliesExportedRedactionRules(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	body := `{"data": [{"request": {"path": "/items", "method": "GET", "destination": "somehost.com", "query": "api_key=%5BREDACTED%5D"}, "response": {"status": 201, "body": "ok"}}],
+		"redactionRules": {"queryParams": ["api_key"]}}`
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/api/records", bytes.NewBufferString(body))
+	Expect(err).To(BeNil())
+	getBoneRouter(dbClient).ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	r, err := http.NewRequest("GET", "http://somehost.com/items?api_key=secret", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+}
+
+func TestUpdateRedactionRulesHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/redaction", bytes.NewBufferString(`{"bodyPatterns": ["(unclosed"]}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+	Expect(dbClient.GetRedactionRules()).To(BeNil())
+
+	req, err = http.NewRequest("PUT", "/api/redaction", bytes.NewBufferString(`{"headers": ["Authorization"], "placeholder": "xxx"}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetRedactionRules().Headers).To(Equal([]string{"Authorization"}))
+	Expect(dbClient.GetRedactionRules().Placeholder).To(Equal("xxx"))
+
+	req, err = http.NewRequest("DELETE", "/api/redaction", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.GetRedactionRules()).To(BeNil())
+}
@@ -0,0 +1,109 @@
+package hoverfly
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	rxCookieDomain = regexp.MustCompile(`(?i)(;\s*domain=)\.?([^;]+)`)
+	rxCookiePath   = regexp.MustCompile(`(?i)(;\s*path=)([^;]*)`)
+)
+
+// reverseProxy - rewrites requests received by the webserver so they are addressed to configured
+// upstream, exactly as if they went through the forward proxy, and rewrites upstream references in
+// responses back to the address client used
+type reverseProxy struct {
+	upstream *url.URL
+}
+
+// newReverseProxy - returns reverse proxy for given upstream base URL, nil if upstream is not set
+func newReverseProxy(upstream string) (*reverseProxy, error) {
+	if upstream == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("upstream is not a valid URL: %s", err.Error())
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("upstream should be an absolute URL (i.e. 'https://api.service.com'), got: %s", upstream)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	return &reverseProxy{upstream: u}, nil
+}
+
+// rewriteRequest - points request to upstream, prefixing path with upstream base path
+func (rp *reverseProxy) rewriteRequest(req *http.Request) {
+	req.URL.Scheme = rp.upstream.Scheme
+	req.URL.Host = rp.upstream.Host
+	req.URL.Path = rp.upstream.Path + req.URL.Path
+	req.Host = rp.upstream.Host
+}
+
+// trimBasePath - strips upstream base path from given path, returns false for paths outside of the base
+// path as they can't be reached through the webserver
+func (rp *reverseProxy) trimBasePath(path string) (string, bool) {
+	base := rp.upstream.Path
+	if path == base {
+		return "/", true
+	}
+	if base == "" || strings.HasPrefix(path, base+"/") {
+		return strings.TrimPrefix(path, base), true
+	}
+	return path, false
+}
+
+// rewriteResponse - rewrites Location header, cookie domains and cookie paths referring to upstream so
+// client keeps talking to the webserver
+func (rp *reverseProxy) rewriteResponse(resp *http.Response, clientScheme, clientHost string) {
+	if resp == nil || resp.Header == nil {
+		return
+	}
+
+	if location := resp.Header.Get("Location"); location != "" {
+		if u, err := url.Parse(location); err == nil && u.Host == rp.upstream.Host {
+			if path, ok := rp.trimBasePath(u.Path); ok {
+				u.Scheme = clientScheme
+				u.Host = clientHost
+				u.Path = path
+				u.RawPath = ""
+				resp.Header.Set("Location", u.String())
+			}
+		}
+	}
+
+	clientHostname := hostWithoutPort(clientHost)
+	upstreamHostname := rp.upstream.Hostname()
+
+	cookies := resp.Header["Set-Cookie"]
+	for i, cookie := range cookies {
+		upstreamCookie := true
+		cookie = rxCookieDomain.ReplaceAllStringFunc(cookie, func(attribute string) string {
+			parts := rxCookieDomain.FindStringSubmatch(attribute)
+			domain := strings.TrimSpace(parts[2])
+			if strings.EqualFold(domain, upstreamHostname) || strings.HasSuffix(strings.ToLower(upstreamHostname), "."+strings.ToLower(domain)) {
+				return parts[1] + clientHostname
+			}
+			upstreamCookie = false
+			return attribute
+		})
+
+		// cookies of other domains keep their paths
+		if upstreamCookie {
+			cookie = rxCookiePath.ReplaceAllStringFunc(cookie, func(attribute string) string {
+				parts := rxCookiePath.FindStringSubmatch(attribute)
+				if path, ok := rp.trimBasePath(strings.TrimSpace(parts[2])); ok {
+					return parts[1] + path
+				}
+				return attribute
+			})
+		}
+		cookies[i] = cookie
+	}
+}
@@ -0,0 +1,127 @@
+package hoverfly
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewReverseProxy_ReturnsNilWithoutUpstream(t *testing.T) {
+	RegisterTestingT(t)
+
+	rp, err := newReverseProxy("")
+	Expect(err).To(BeNil())
+	Expect(rp).To(BeNil())
+}
+
+func TestNewReverseProxy_ErrorsOnRelativeUpstream(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := newReverseProxy("api.service.com")
+	Expect(err).ToNot(BeNil())
+}
+
+func TestReverseProxy_RewritesRequestToUpstream(t *testing.T) {
+	RegisterTestingT(t)
+
+	rp, err := newReverseProxy("https://api.service.com/v1/")
+	Expect(err).To(BeNil())
+
+	req, err := http.NewRequest("GET", "/users?page=2", nil)
+	Expect(err).To(BeNil())
+	req.Host = "localhost:8500"
+
+	rp.rewriteRequest(req)
+
+	Expect(req.URL.String()).To(Equal("https://api.service.com/v1/users?page=2"))
+	Expect(req.Host).To(Equal("api.service.com"))
+}
+
+func TestReverseProxy_RewritesLocationPointingToUpstream(t *testing.T) {
+	RegisterTestingT(t)
+
+	rp, err := newReverseProxy("https://api.service.com/v1")
+	Expect(err).To(BeNil())
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Location", "https://api.service.com/v1/users/1?expand=true")
+
+	rp.rewriteResponse(resp, "http", "localhost:8500")
+
+	Expect(resp.Header.Get("Location")).To(Equal("http://localhost:8500/users/1?expand=true"))
+}
+
+func TestReverseProxy_DoesNotRewriteLocationOutsideOfUpstreamBasePath(t *testing.T) {
+	RegisterTestingT(t)
+
+	rp, err := newReverseProxy("https://api.service.com/v1")
+	Expect(err).To(BeNil())
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Location", "https://api.service.com/v10/users")
+
+	rp.rewriteResponse(resp, "http", "localhost:8500")
+	Expect(resp.Header.Get("Location")).To(Equal("https://api.service.com/v10/users"))
+
+	resp.Header.Set("Location", "https://api.service.com/v1")
+
+	rp.rewriteResponse(resp, "http", "localhost:8500")
+	Expect(resp.Header.Get("Location")).To(Equal("http://localhost:8500/"))
+}
+
+func TestReverseProxy_DoesNotRewriteLocationPointingElsewhere(t *testing.T) {
+	RegisterTestingT(t)
+
+	rp, err := newReverseProxy("https://api.service.com")
+	Expect(err).To(BeNil())
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Location", "https://login.service.com/authorize")
+
+	rp.rewriteResponse(resp, "http", "localhost:8500")
+
+	Expect(resp.Header.Get("Location")).To(Equal("https://login.service.com/authorize"))
+}
+
+func TestReverseProxy_RewritesCookieDomains(t *testing.T) {
+	RegisterTestingT(t)
+
+	rp, err := newReverseProxy("https://api.service.com")
+	Expect(err).To(BeNil())
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Add("Set-Cookie", "session=abc; Domain=api.service.com; Path=/")
+	resp.Header.Add("Set-Cookie", "tracking=xyz; domain=.service.com")
+	resp.Header.Add("Set-Cookie", "other=1; Domain=other.com")
+
+	rp.rewriteResponse(resp, "http", "localhost:8500")
+
+	Expect(resp.Header["Set-Cookie"]).To(Equal([]string{
+		"session=abc; Domain=localhost; Path=/",
+		"tracking=xyz; domain=localhost",
+		"other=1; Domain=other.com",
+	}))
+}
+
+func TestReverseProxy_StripsUpstreamBasePathFromCookiePaths(t *testing.T) {
+	RegisterTestingT(t)
+
+	rp, err := newReverseProxy("https://api.service.com/v1")
+	Expect(err).To(BeNil())
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Add("Set-Cookie", "session=abc; Path=/v1/account; HttpOnly")
+	resp.Header.Add("Set-Cookie", "root=1; Domain=api.service.com; path=/v1")
+	resp.Header.Add("Set-Cookie", "legacy=1; Path=/v10")
+	resp.Header.Add("Set-Cookie", "other=1; Domain=other.com; Path=/v1/account")
+
+	rp.rewriteResponse(resp, "http", "localhost:8500")
+
+	Expect(resp.Header["Set-Cookie"]).To(Equal([]string{
+		"session=abc; Path=/account; HttpOnly",
+		"root=1; Domain=localhost; path=/",
+		"legacy=1; Path=/v10",
+		"other=1; Domain=other.com; Path=/v1/account",
+	}))
+}
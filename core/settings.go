@@ -18,8 +18,24 @@ type Configuration struct {
 	DatabasePath string
 	Webserver    bool
 
+	TransparentPort string
+
+	WebserverTLSCertificate string
+	WebserverTLSKey         string
+	WebserverTLSHosts       []string
+	WebserverClientCA       string
+	WebserverUpstream       string
+	VirtualHosts            []VirtualHost
+
 	TLSVerification bool
 
+	// CaptureUpstreamErrors - transport errors received from upstream in capture mode are stored, so
+	// simulate mode can reproduce them
+	CaptureUpstreamErrors bool
+
+	UpstreamCABundle   string
+	ClientCertificates []ClientCertificate
+
 	Verbose     bool
 	Development bool
 
@@ -69,10 +85,13 @@ const (
 	HoverflyAdminPortEV = "AdminPort"
 	HoverflyProxyPortEV = "ProxyPort"
 
+	HoverflyTransparentPortEV = "TransparentPort"
+
 	HoverflyDBEV         = "HoverflyDB"
 	HoverflyMiddlewareEV = "HoverflyMiddleware"
 
 	HoverflyTLSVerification = "HoverflyTlsVerification"
+	HoverflyUpstreamCAEV    = "HoverflyUpstreamCA"
 
 	HoverflyAdminUsernameEV = "HoverflyAdmin"
 	HoverflyAdminPasswordEV = "HoverflyAdminPass"
@@ -99,6 +118,9 @@ func InitSettings() *Configuration {
 		appConfig.ProxyPort = DefaultPort
 	}
 
+	// transparent proxy is only started when port is supplied
+	appConfig.TransparentPort = os.Getenv(HoverflyTransparentPortEV)
+
 	databasePath := os.Getenv(HoverflyDBEV)
 	if databasePath == "" {
 		appConfig.DatabasePath = DefaultDatabasePath
@@ -143,5 +165,7 @@ func InitSettings() *Configuration {
 		appConfig.TLSVerification = true
 	}
 
+	appConfig.UpstreamCABundle = os.Getenv(HoverflyUpstreamCAEV)
+
 	return &appConfig
 }
@@ -53,7 +53,7 @@ func SynthesizeResponse(req *http.Request, middleware string) (*http.Response, e
 	c := NewConstructor(req, payload)
 
 	if middleware != "" {
-		err := c.ApplyMiddleware(middleware)
+		err := applyMiddleware(req, c, middleware)
 		if err != nil {
 			return nil, fmt.Errorf("Synthesize failed, middleware error - %s", err.Error())
 		}
@@ -62,6 +62,8 @@ func testTools(code int, body string) (*httptest.Server, *Hoverfly) {
 		RequestCache:  requestCache,
 		TemplateStore: matching.RequestTemplateStore{},
 		Webserver:     &cfg.Webserver,
+		HistoryCache:  cache.NewBoltDBCache(TestDB, GetRandomName(10)),
+		Coverage:      models.NewCoverage(),
 	}
 
 	// preparing client
@@ -69,11 +71,17 @@ func testTools(code int, body string) (*httptest.Server, *Hoverfly) {
 		HTTP:           &http.Client{Transport: tr},
 		RequestCache:   requestCache,
 		Cfg:            cfg,
-		Counter:        metrics.NewModeCounter([]string{SimulateMode, SynthesizeMode, ModifyMode, CaptureMode}),
+		Counter:        metrics.NewModeCounter([]string{SimulateMode, SynthesizeMode, ModifyMode, CaptureMode, SpyMode}),
 		MetadataCache:  metaCache,
 		ResponseDelays: &models.ResponseDelayList{},
+		Throttles:      &models.ThrottleList{},
+		Faults:         &models.FaultList{},
+		Journal:        models.NewJournal(models.DefaultJournalSize, nil),
+		Events:         NewEventStream(),
+		Hooks:          make(ActionTypeHooks),
 		RequestMatcher: requestMatcher,
 	}
+	dbClient.AddHook(dbClient.Events)
 	return server, dbClient
 }
 
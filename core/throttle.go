@@ -0,0 +1,154 @@
+package hoverfly
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+)
+
+// throttleInterval - bandwidth limited bodies are delivered in parts covering this much time
+const throttleInterval = 100 * time.Millisecond
+
+// throttledBody - body that is read at limited rate, optionally in chunks with pauses in between
+type throttledBody struct {
+	body           io.ReadCloser
+	bytesPerSecond int
+	chunkSize      int
+	chunkDelay     time.Duration
+}
+
+func newThrottledBody(body io.ReadCloser, bytesPerSecond, chunkSize int, chunkDelay time.Duration) *throttledBody {
+	return &throttledBody{
+		body:           body,
+		bytesPerSecond: bytesPerSecond,
+		chunkSize:      chunkSize,
+		chunkDelay:     chunkDelay,
+	}
+}
+
+// partSize - bytes returned by a single read
+func (b *throttledBody) partSize(max int) int {
+	size := max
+	if b.chunkSize > 0 && size > b.chunkSize {
+		size = b.chunkSize
+	}
+	if b.bytesPerSecond > 0 {
+		limit := int(int64(b.bytesPerSecond) * int64(throttleInterval) / int64(time.Second))
+		if limit < 1 {
+			limit = 1
+		}
+		if size > limit {
+			size = limit
+		}
+	}
+	return size
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	n, err := b.body.Read(p[:b.partSize(len(p))])
+	if n > 0 {
+		b.wait(n)
+	}
+	return n, err
+}
+
+// wait - pauses for as long as sending n bytes takes
+func (b *throttledBody) wait(n int) {
+	pause := b.chunkDelay
+	if b.bytesPerSecond > 0 {
+		pause += time.Duration(int64(n) * int64(time.Second) / int64(b.bytesPerSecond))
+	}
+	time.Sleep(pause)
+}
+
+// WriteTo - writes the body flushing every part, so clients receive it as it is trickled. It is used
+// by io.Copy when response is written to the client.
+func (b *throttledBody) WriteTo(w io.Writer) (int64, error) {
+	flusher, _ := w.(http.Flusher)
+
+	var written int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := b.Read(buf)
+		if n > 0 {
+			m, writeErr := w.Write(buf[:n])
+			written += int64(m)
+			if writeErr != nil {
+				return written, writeErr
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+func (b *throttledBody) Close() error {
+	return b.body.Close()
+}
+
+// UpdateThrottles - sets bandwidth limits applied to requests and responses
+func (hf *Hoverfly) UpdateThrottles(throttles models.ThrottleList) {
+	hf.Throttles = &throttles
+	log.Info("Throttle config updated on hoverfly")
+}
+
+// throttleRequest - request body is read at limited rate when there is a throttle for the request
+func (hf *Hoverfly) throttleRequest(req *http.Request) {
+	throttle := hf.Throttles.GetThrottle(req.URL.String(), req.Method)
+	if !throttle.ThrottlesRequest() || req.Body == nil {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"bytesPerSecond": throttle.RequestBytesPerSecond,
+		"path":           req.URL.Path,
+	}).Debug("throttling request body")
+
+	req.Body = newThrottledBody(req.Body, throttle.RequestBytesPerSecond, 0, 0)
+}
+
+// throttleResponse - response body is trickled when there is a throttle for the request, throttle of
+// matching request template takes precedence
+func (hf *Hoverfly) throttleResponse(req *http.Request, resp *http.Response, throttle *models.Throttle) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	if _, throttled := resp.Body.(*throttledBody); throttled {
+		return
+	}
+
+	if throttle == nil {
+		throttle = hf.Throttles.GetThrottle(req.URL.String(), req.Method)
+	}
+	if !throttle.ThrottlesResponse() {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"bytesPerSecond": throttle.ResponseBytesPerSecond,
+		"chunkSize":      throttle.ChunkSize,
+		"chunkDelay":     throttle.ChunkDelay,
+		"path":           req.URL.Path,
+	}).Debug("throttling response body")
+
+	if throttle.ChunkSize > 0 {
+		// length is not announced so the body is sent with chunked transfer encoding
+		resp.ContentLength = -1
+		resp.Header.Del("Content-Length")
+	}
+	resp.Body = newThrottledBody(resp.Body, throttle.ResponseBytesPerSecond, throttle.ChunkSize, throttle.GetChunkDelay())
+}
@@ -0,0 +1,173 @@
+package hoverfly
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/matching"
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+// recordingWriter - remembers every write and flush
+type recordingWriter struct {
+	writes  []string
+	flushes int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *recordingWriter) Flush() {
+	w.flushes++
+}
+
+func TestThrottledBody_ReadsAtLimitedRate(t *testing.T) {
+	RegisterTestingT(t)
+
+	body := newThrottledBody(ioutil.NopCloser(strings.NewReader(strings.Repeat("a", 200))), 1000, 0, 0)
+
+	start := time.Now()
+	b, err := ioutil.ReadAll(body)
+	Expect(err).To(BeNil())
+	Expect(b).To(HaveLen(200))
+	Expect(time.Since(start)).To(BeNumerically(">=", 200*time.Millisecond))
+}
+
+func TestThrottledBody_WritesAndFlushesChunks(t *testing.T) {
+	RegisterTestingT(t)
+
+	body := newThrottledBody(ioutil.NopCloser(strings.NewReader("0123456789abcdefghij")), 0, 5, 20*time.Millisecond)
+	w := &recordingWriter{}
+
+	start := time.Now()
+	n, err := io.Copy(w, body)
+	Expect(err).To(BeNil())
+	Expect(n).To(Equal(int64(20)))
+	Expect(w.writes).To(Equal([]string{"01234", "56789", "abcde", "fghij"}))
+	Expect(w.flushes).To(Equal(4))
+	Expect(time.Since(start)).To(BeNumerically(">=", 80*time.Millisecond))
+}
+
+func TestProcessRequest_ThrottlesResponseInChunks(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request:  models.RequestDetails{Path: "/slow", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Response: models.ResponseDetails{Status: 200, Body: "0123456789", Headers: map[string][]string{"Content-Length": {"10"}}},
+	})
+	Expect(err).To(BeNil())
+	dbClient.UpdateThrottles(models.ThrottleList{{UrlPattern: "somehost.com/slow", ChunkSize: 2, ChunkDelay: 10}})
+
+	r, err := http.NewRequest("GET", "http://somehost.com/slow", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.ContentLength).To(Equal(int64(-1)))
+	Expect(resp.Header.Get("Content-Length")).To(BeEmpty())
+
+	start := time.Now()
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("0123456789"))
+	Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+}
+
+func TestProcessRequest_ThrottlesRequestBody(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.UpdateThrottles(models.ThrottleList{{UrlPattern: "upload", HttpMethod: "POST", RequestBytesPerSecond: 1000}})
+
+	r, err := http.NewRequest("POST", "http://somehost.com/upload", bytes.NewBufferString(strings.Repeat("a", 150)))
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	start := time.Now()
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	Expect(time.Since(start)).To(BeNumerically(">=", 150*time.Millisecond))
+}
+
+func TestRequestTemplateThrottleTakesPrecedence(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+
+	path := "/template"
+	err := dbClient.RequestMatcher.TemplateStore.ImportPayloads(matching.RequestTemplatePayloadJson{
+		Data: &[]matching.RequestTemplatePayloadView{{
+			RequestTemplate: matching.RequestTemplate{Path: &path},
+			Throttle:        &models.Throttle{ChunkSize: 1},
+		}},
+	})
+	Expect(err).To(BeNil())
+	dbClient.UpdateThrottles(models.ThrottleList{{UrlPattern: ".", ResponseBytesPerSecond: 1}})
+
+	r, err := http.NewRequest("GET", "http://somehost.com/template", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(r)
+
+	throttled, ok := resp.Body.(*throttledBody)
+	Expect(ok).To(BeTrue())
+	Expect(throttled.chunkSize).To(Equal(1))
+	Expect(throttled.bytesPerSecond).To(Equal(0))
+}
+
+func TestImportRequestTemplates_ValidatesThrottle(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := matching.RequestTemplateStore{}
+	err := store.ImportPayloads(matching.RequestTemplatePayloadJson{
+		Data: &[]matching.RequestTemplatePayloadView{{Throttle: &models.Throttle{ChunkDelay: 10}}},
+	})
+	Expect(err).ToNot(BeNil())
+	Expect(store).To(BeEmpty())
+}
+
+func TestUpdateThrottlesHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	m := getBoneRouter(dbClient)
+
+	req, err := http.NewRequest("PUT", "/api/throttles", bytes.NewBufferString(`{"data": [{"urlPattern": "."}]}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(422))
+
+	req, err = http.NewRequest("PUT", "/api/throttles", bytes.NewBufferString(`{"data": [{"urlPattern": ".", "responseBytesPerSecond": 2048}]}`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusCreated))
+	Expect(dbClient.Throttles.Len()).To(Equal(1))
+
+	req, err = http.NewRequest("DELETE", "/api/throttles", nil)
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(dbClient.Throttles.Len()).To(Equal(0))
+}
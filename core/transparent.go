@@ -0,0 +1,246 @@
+package hoverfly
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/certs"
+	"github.com/rusenask/goproxy"
+)
+
+// tlsRecordTypeHandshake - first byte of a TLS ClientHello record
+const tlsRecordTypeHandshake = 0x16
+
+// errNoCertificateHost - client sent no SNI and original destination is unknown, so there is no host
+// to issue certificate for
+var errNoCertificateHost = fmt.Errorf("no SNI or original destination to issue certificate for")
+
+// StartTransparentProxy - starts listener that accepts connections redirected to Hoverfly
+// by iptables (i.e. '-j REDIRECT --to-ports <port>') from clients that are not proxy aware.
+// Requests are processed exactly as they would be by the forward proxy. Traffic generated
+// by Hoverfly itself must be excluded from the redirect rule. This method is non blocking.
+func (hf *Hoverfly) StartTransparentProxy() error {
+	if hf.Cfg.TransparentPort == "" {
+		return fmt.Errorf("Transparent proxy port is not set!")
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", hf.Cfg.TransparentPort))
+	if err != nil {
+		return err
+	}
+
+	sl, err := NewStoppableListener(listener)
+	if err != nil {
+		return err
+	}
+	sl.conns = &hf.conns
+	hf.TransparentSL = sl
+
+	tp := &transparentProxy{
+		hoverfly:     hf,
+		certificates: make(map[string]*tls.Certificate),
+	}
+
+	log.WithFields(log.Fields{
+		"port":        hf.Cfg.TransparentPort,
+		"destination": hf.Cfg.Destination,
+	}).Info("serving transparent proxy")
+
+	go func() {
+		for {
+			conn, err := sl.Accept()
+			if err != nil {
+				log.Warn(err)
+				return
+			}
+			go tp.serve(conn)
+		}
+	}()
+
+	return nil
+}
+
+// StopTransparentProxy - stops transparent proxy listener
+func (hf *Hoverfly) StopTransparentProxy() {
+	if hf.TransparentSL != nil {
+		hf.TransparentSL.Stop()
+		hf.TransparentSL = nil
+	}
+}
+
+type transparentProxy struct {
+	hoverfly *Hoverfly
+
+	certificates map[string]*tls.Certificate
+	mu           sync.Mutex
+}
+
+// serve - reads requests from redirected connection, recovering original destination from
+// Host header, TLS SNI or the socket itself (in that order)
+func (tp *transparentProxy) serve(conn net.Conn) {
+	defer conn.Close()
+
+	originalDst, err := originalDestination(conn)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":      err.Error(),
+			"remoteAddr": conn.RemoteAddr().String(),
+		}).Debug("could not recover original destination from socket")
+	}
+
+	reader := bufio.NewReader(conn)
+	first, err := reader.Peek(1)
+	if err != nil {
+		return
+	}
+
+	scheme := "http"
+	serverName := ""
+	var clientConn net.Conn = &peekedConn{Conn: conn, reader: reader}
+
+	if first[0] == tlsRecordTypeHandshake {
+		scheme = "https"
+		tlsConn := tls.Server(clientConn, &tls.Config{
+			GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+				serverName = hello.ServerName
+				if serverName == "" {
+					serverName = hostWithoutPort(originalDst)
+				}
+				if serverName == "" {
+					log.WithFields(log.Fields{
+						"remoteAddr": conn.RemoteAddr().String(),
+					}).Warn("transparent proxy can't issue certificate, client sent no SNI and original destination is unknown")
+					return nil, errNoCertificateHost
+				}
+				return tp.certificateFor(serverName)
+			},
+		})
+		if err := tlsConn.Handshake(); err != nil {
+			log.WithFields(log.Fields{
+				"error":      err.Error(),
+				"remoteAddr": conn.RemoteAddr().String(),
+			}).Warn("transparent proxy failed TLS handshake with client")
+			return
+		}
+		clientConn = tlsConn
+		reader = bufio.NewReader(tlsConn)
+	}
+
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			if err != io.EOF {
+				log.WithFields(log.Fields{
+					"error": err.Error(),
+				}).Debug("transparent proxy could not read request")
+			}
+			return
+		}
+
+		host := req.Host
+		if host == "" {
+			host = serverName
+		}
+		if host == "" {
+			host = originalDst
+		}
+		if host == "" {
+			writeResponse(clientConn, hoverflyError(req, fmt.Errorf("no Host header, SNI or original destination"), "Could not determine request destination", http.StatusBadGateway))
+			return
+		}
+
+		req.Host = host
+		req.URL.Scheme = scheme
+		req.URL.Host = host
+		req.RemoteAddr = conn.RemoteAddr().String()
+
+		resp := tp.handle(req)
+		if err := writeResponse(clientConn, resp); err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Warn("transparent proxy could not write response")
+			return
+		}
+
+		if req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+// handle - passes requests matching destination to Hoverfly, other requests are forwarded untouched
+func (tp *transparentProxy) handle(req *http.Request) *http.Response {
+	hf := tp.hoverfly
+
+	matches, err := regexp.MatchString(hf.Cfg.Destination, req.Host)
+	if err == nil && matches {
+		_, resp := hf.processRequest(req)
+		hf.Counter.Count(hf.Cfg.GetMode())
+		return resp
+	}
+
+	req.RequestURI = ""
+	resp, err := hf.HTTP.Transport.RoundTrip(req)
+	if err != nil {
+		return hoverflyError(req, err, "Could not reach destination", http.StatusBadGateway)
+	}
+	return resp
+}
+
+func (tp *transparentProxy) certificateFor(host string) (*tls.Certificate, error) {
+	if host == "" {
+		return nil, errNoCertificateHost
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if certificate, ok := tp.certificates[host]; ok {
+		return certificate, nil
+	}
+
+	certificate, err := certs.NewHostCertificate(goproxy.GoproxyCa, []string{host}, DefaultWebserverCertificateValidity)
+	if err != nil {
+		return nil, err
+	}
+	tp.certificates[host] = certificate
+	return certificate, nil
+}
+
+// writeResponse - writes response to connection as HTTP/1.1
+func writeResponse(w io.Writer, resp *http.Response) error {
+	resp.ProtoMajor, resp.ProtoMinor = 1, 1
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	err := resp.Write(w)
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+func hostWithoutPort(hostport string) string {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport
+	}
+	return host
+}
+
+// peekedConn - connection that reads through buffered reader used to sniff the protocol
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,49 @@
+package hoverfly
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// soOriginalDst - getsockopt option name for destination of connections redirected by netfilter
+const soOriginalDst = 80
+
+// errNotTCPConnection - original destination can only be recovered from TCP sockets
+var errNotTCPConnection = fmt.Errorf("not a TCP connection")
+
+// originalDestination - returns address the client connected to before the connection was
+// redirected by iptables, connections tracked for fault injection are unwrapped
+func originalDestination(conn net.Conn) (string, error) {
+	if tracked, ok := conn.(*trackedConn); ok {
+		conn = tracked.Conn
+	}
+
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return "", errNotTCPConnection
+	}
+
+	rawConn, err := tcpConn.SyscallConn()
+	if err != nil {
+		return "", err
+	}
+
+	var addr *syscall.IPv6Mreq
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		addr, sockErr = syscall.GetsockoptIPv6Mreq(int(fd), syscall.IPPROTO_IP, soOriginalDst)
+	})
+	if err != nil {
+		return "", err
+	}
+	if sockErr != nil {
+		return "", sockErr
+	}
+
+	// sockaddr_in: family (2 bytes), port (2 bytes, big endian), address (4 bytes)
+	port := int(addr.Multiaddr[2])<<8 | int(addr.Multiaddr[3])
+	ip := net.IPv4(addr.Multiaddr[4], addr.Multiaddr[5], addr.Multiaddr[6], addr.Multiaddr[7])
+
+	return net.JoinHostPort(ip.String(), fmt.Sprintf("%d", port)), nil
+}
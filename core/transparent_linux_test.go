@@ -0,0 +1,37 @@
+package hoverfly
+
+import (
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOriginalDestination_UnwrapsTrackedConnection(t *testing.T) {
+	RegisterTestingT(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).To(BeNil())
+	defer listener.Close()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	Expect(err).To(BeNil())
+	defer client.Close()
+
+	conn, err := listener.Accept()
+	Expect(err).To(BeNil())
+
+	var conns clientConns
+	tracked := conns.track(conn)
+	defer tracked.Close()
+
+	// connection wasn't redirected, so the lookup itself fails, but the socket has to be reached
+	_, err = originalDestination(tracked)
+	Expect(err).ToNot(Equal(errNotTCPConnection))
+
+	server, pipe := net.Pipe()
+	defer server.Close()
+	defer pipe.Close()
+	_, err = originalDestination(conns.track(pipe))
+	Expect(err).To(Equal(errNotTCPConnection))
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package hoverfly
+
+import (
+	"fmt"
+	"net"
+)
+
+// originalDestination - recovering original destination from the socket is only supported on Linux,
+// requests are routed using Host header or TLS SNI instead
+func originalDestination(conn net.Conn) (string, error) {
+	return "", fmt.Errorf("original destination lookup is not supported on this platform")
+}
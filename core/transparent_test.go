@@ -0,0 +1,121 @@
+package hoverfly
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func TestStartTransparentProxyWOPort(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+
+	dbClient.Cfg.TransparentPort = ""
+
+	err := dbClient.StartTransparentProxy()
+	Expect(err).ToNot(BeNil())
+}
+
+func TestTransparentProxyCapturesUsingHostHeader(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.Cfg.TransparentPort = "9790"
+	dbClient.Cfg.Destination = "."
+	dbClient.Cfg.SetMode(CaptureMode)
+
+	err := dbClient.StartTransparentProxy()
+	Expect(err).To(BeNil())
+	defer dbClient.StopTransparentProxy()
+
+	// client is not proxy aware, it connects to what it thinks is the service
+	req, err := http.NewRequest("GET", fmt.Sprintf("http://localhost:%s/transparent", dbClient.Cfg.TransparentPort), nil)
+	Expect(err).To(BeNil())
+	req.Host = "transparent.com"
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+
+	values, err := dbClient.RequestCache.GetAllValues()
+	Expect(err).To(BeNil())
+	Expect(values).To(HaveLen(1))
+
+	payload, err := models.NewPayloadFromBytes(values[0])
+	Expect(err).To(BeNil())
+	Expect(payload.Request.Destination).To(Equal("transparent.com"))
+	Expect(payload.Request.Path).To(Equal("/transparent"))
+}
+
+func TestTransparentProxySimulatesTLSConnectionsUsingSNI(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	payload := models.Payload{
+		Request: models.RequestDetails{
+			Method:      "GET",
+			Destination: "secure.com",
+			Path:        "/secret",
+		},
+		Response: models.ResponseDetails{
+			Status: 200,
+			Body:   "simulated",
+		},
+	}
+	Expect(dbClient.RequestMatcher.SavePayload(&payload)).To(BeNil())
+
+	dbClient.Cfg.TransparentPort = "9791"
+	dbClient.Cfg.Destination = "."
+	dbClient.Cfg.SetMode(SimulateMode)
+
+	err := dbClient.StartTransparentProxy()
+	Expect(err).To(BeNil())
+	defer dbClient.StopTransparentProxy()
+
+	conn, err := tls.Dial("tcp", net.JoinHostPort("localhost", dbClient.Cfg.TransparentPort), &tls.Config{
+		ServerName: "secure.com",
+		RootCAs:    hoverflyCAPool(),
+	})
+	Expect(err).To(BeNil())
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /secret HTTP/1.1\r\nHost: secure.com\r\n\r\n"))
+	Expect(err).To(BeNil())
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).To(BeNil())
+	Expect(string(body)).To(Equal("simulated"))
+}
+
+func TestTransparentProxyDoesNotIssueCertificateWithoutHost(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := &transparentProxy{certificates: make(map[string]*tls.Certificate)}
+
+	_, err := tp.certificateFor("")
+	Expect(err).To(Equal(errNoCertificateHost))
+	Expect(tp.certificates).To(BeEmpty())
+
+	certificate, err := tp.certificateFor("secure.com")
+	Expect(err).To(BeNil())
+	Expect(certificate).ToNot(BeNil())
+}
@@ -0,0 +1,125 @@
+package hoverfly
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// ClientCertificate - client certificate and private key that Hoverfly presents to upstream
+// hosts matching DestinationPattern
+type ClientCertificate struct {
+	DestinationPattern string
+	CertificatePath    string
+	KeyPath            string
+}
+
+// UpstreamTransport - http.RoundTripper that picks TLS client configuration for outgoing requests
+// based on request host. Requests that don't match any client certificate destination pattern
+// are sent through the default transport.
+type UpstreamTransport struct {
+	Default      *http.Transport
+	destinations []upstreamDestination
+}
+
+type upstreamDestination struct {
+	pattern   *regexp.Regexp
+	transport *http.Transport
+}
+
+// NewUpstreamTransport - returns transport configured with TLS verification settings, CA bundle
+// and client certificates from given configuration
+func NewUpstreamTransport(cfg *Configuration) (*UpstreamTransport, error) {
+	rootCAs, err := loadCABundle(cfg.UpstreamCABundle)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &UpstreamTransport{
+		Default: newUpstreamHTTPTransport(cfg.TLSVerification, rootCAs, nil),
+	}
+
+	for _, cc := range cfg.ClientCertificates {
+		pattern, err := regexp.Compile(cc.DestinationPattern)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate destination is not a valid regular expression string: %s", cc.DestinationPattern)
+		}
+
+		certificate, err := tls.LoadX509KeyPair(cc.CertificatePath, cc.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate and key pair for '%s', got error: %s", cc.DestinationPattern, err.Error())
+		}
+
+		t.destinations = append(t.destinations, upstreamDestination{
+			pattern:   pattern,
+			transport: newUpstreamHTTPTransport(cfg.TLSVerification, rootCAs, []tls.Certificate{certificate}),
+		})
+
+		log.WithFields(log.Fields{
+			"destination": cc.DestinationPattern,
+			"certificate": cc.CertificatePath,
+		}).Info("client certificate loaded for upstream destination")
+	}
+
+	return t, nil
+}
+
+// RoundTrip - implements http.RoundTripper, sending request through the transport
+// configured for request host
+func (t *UpstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transportFor(req.URL.Host).RoundTrip(req)
+}
+
+func (t *UpstreamTransport) transportFor(host string) *http.Transport {
+	for _, d := range t.destinations {
+		if d.pattern.MatchString(host) {
+			return d.transport
+		}
+	}
+	return t.Default
+}
+
+func newUpstreamHTTPTransport(verify bool, rootCAs *x509.CertPool, certificates []tls.Certificate) *http.Transport {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: !verify,
+			RootCAs:            rootCAs,
+			Certificates:       certificates,
+		},
+	}
+}
+
+// loadCABundle - reads PEM encoded certificates from given file, returns nil pool (system roots)
+// when no bundle is configured
+func loadCABundle(path string) (*x509.CertPool, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	bundle, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle, got error: %s", err.Error())
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no valid certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// ConfigureUpstreamTLS - rebuilds HTTP client used to forward requests, applying current
+// TLS verification, CA bundle and client certificate configuration
+func (hf *Hoverfly) ConfigureUpstreamTLS() error {
+	transport, err := NewUpstreamTransport(hf.Cfg)
+	if err != nil {
+		return err
+	}
+	hf.HTTP = &http.Client{Transport: transport}
+	return nil
+}
@@ -0,0 +1,138 @@
+package hoverfly
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/models"
+)
+
+// newUpstreamError - returns description of transport error received from upstream, nil for errors
+// that are not caused by the network (i.e. failing middleware)
+func newUpstreamError(err error) *models.UpstreamError {
+	errorType := classifyUpstreamError(err)
+	if errorType == "" {
+		return nil
+	}
+	return &models.UpstreamError{
+		Type:    errorType,
+		Message: err.Error(),
+	}
+}
+
+// classifyUpstreamError - unwraps errors returned by HTTP client until their cause is known
+func classifyUpstreamError(err error) string {
+	for err != nil {
+		switch e := err.(type) {
+		case *url.Error:
+			if e.Timeout() {
+				return models.UpstreamErrorTimeout
+			}
+			err = e.Err
+		case *net.OpError:
+			if e.Timeout() {
+				return models.UpstreamErrorTimeout
+			}
+			err = e.Err
+		case *os.SyscallError:
+			err = e.Err
+		case *net.DNSError:
+			return models.UpstreamErrorDNS
+		case syscall.Errno:
+			switch e {
+			case syscall.ECONNREFUSED:
+				return models.UpstreamErrorConnectionRefused
+			case syscall.ECONNRESET, syscall.EPIPE:
+				return models.UpstreamErrorConnectionReset
+			}
+			return ""
+		case x509.UnknownAuthorityError, x509.CertificateInvalidError, x509.HostnameError, tls.RecordHeaderError:
+			return models.UpstreamErrorTLS
+		default:
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return models.UpstreamErrorConnectionReset
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return models.UpstreamErrorTimeout
+			}
+			// handshake failures and alerts are not exported as types
+			if strings.Contains(err.Error(), "tls: ") {
+				return models.UpstreamErrorTLS
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// saveUpstreamError - stores transport error received instead of a response, errors that are not
+// caused by the network are not stored
+func (hf *Hoverfly) saveUpstreamError(req *http.Request, reqBody []byte, err error, metadata *models.CaptureMetadata) {
+	upstreamError := newUpstreamError(err)
+	if upstreamError == nil {
+		return
+	}
+
+	if !hf.GetCaptureFilters().Allows(req.Method, req.URL.Path, 0, "") {
+		log.WithFields(log.Fields{
+			"method": req.Method,
+			"path":   req.URL.Path,
+			"mode":   "capture",
+		}).Debug("upstream error was not captured because of capture filters")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"type":        upstreamError.Type,
+		"path":        req.URL.Path,
+		"destination": req.Host,
+		"mode":        "capture",
+	}).Info("upstream error captured")
+
+	hf.capturePayload(&models.Payload{
+		Request:  newCapturedRequestDetails(req, reqBody),
+		Error:    upstreamError,
+		Metadata: metadata,
+	})
+}
+
+// replayUpstreamError - reproduces failure that was captured instead of a response. Refused and reset
+// connections are reset, timeouts hang for as long as upstream took to time out and TLS errors break
+// the connection with bytes that are not a valid response. Failures that can't be reproduced on the
+// client connection, such as DNS errors, are returned as bad gateway responses.
+func (hf *Hoverfly) replayUpstreamError(req *http.Request, payload *models.Payload) *http.Response {
+	hf.Counter.CountFault(hf.Cfg.GetMode())
+
+	fault := &models.Fault{}
+	switch payload.Error.Type {
+	case models.UpstreamErrorConnectionRefused, models.UpstreamErrorConnectionReset:
+		fault.Type = models.FaultReset
+	case models.UpstreamErrorTimeout:
+		fault.Type = models.FaultHang
+		if payload.Metadata != nil {
+			fault.Duration = int(payload.Metadata.Latency / time.Millisecond)
+		}
+	case models.UpstreamErrorTLS:
+		fault.Type = models.FaultMalformed
+	default:
+		log.WithFields(log.Fields{
+			"type":        payload.Error.Type,
+			"path":        req.URL.Path,
+			"destination": req.Host,
+		}).Info("replaying upstream error")
+
+		return hoverflyError(req, errors.New(payload.Error.Message), "Could not reach destination", http.StatusBadGateway)
+	}
+
+	return hf.injectConnectionFault(req, fault)
+}
@@ -0,0 +1,146 @@
+package hoverfly
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func TestClassifyUpstreamError(t *testing.T) {
+	RegisterTestingT(t)
+
+	client := &http.Client{Transport: &http.Transport{}}
+
+	// nothing listens on the port of closed server
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	_, err := client.Get(closed.URL)
+	Expect(classifyUpstreamError(err)).To(Equal(models.UpstreamErrorConnectionRefused))
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	_, err = (&http.Client{Transport: &http.Transport{}, Timeout: 50 * time.Millisecond}).Get(slow.URL)
+	Expect(classifyUpstreamError(err)).To(Equal(models.UpstreamErrorTimeout))
+
+	untrusted := httptest.NewTLSServer(http.NotFoundHandler())
+	defer untrusted.Close()
+	_, err = client.Get(untrusted.URL)
+	Expect(classifyUpstreamError(err)).To(Equal(models.UpstreamErrorTLS))
+
+	Expect(classifyUpstreamError(&net.DNSError{Err: "no such host", Name: "unknown.invalid"})).To(Equal(models.UpstreamErrorDNS))
+	Expect(classifyUpstreamError(io.EOF)).To(Equal(models.UpstreamErrorConnectionReset))
+	Expect(classifyUpstreamError(errors.New("middleware failed"))).To(BeEmpty())
+}
+
+func TestCaptureUpstreamErrors_StoresConnectionRefused(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer dbClient.RequestCache.DeleteData()
+	server.Close()
+
+	dbClient.Cfg.CaptureUpstreamErrors = true
+	dbClient.Cfg.SetMode(CaptureMode)
+
+	r, err := http.NewRequest("GET", "http://somehost.com/down", nil)
+	Expect(err).To(BeNil())
+
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+
+	payloads, err := dbClient.RequestCache.GetAllValues()
+	Expect(err).To(BeNil())
+	Expect(payloads).To(HaveLen(1))
+
+	payload, err := models.NewPayloadFromBytes(payloads[0])
+	Expect(err).To(BeNil())
+	Expect(payload.Request.Path).To(Equal("/down"))
+	Expect(payload.Error).ToNot(BeNil())
+	Expect(payload.Error.Type).To(Equal(models.UpstreamErrorConnectionRefused))
+	Expect(payload.Error.Message).To(ContainSubstring("refused"))
+}
+
+func TestCaptureUpstreamErrors_DisabledByDefault(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer dbClient.RequestCache.DeleteData()
+	server.Close()
+
+	dbClient.Cfg.SetMode(CaptureMode)
+
+	r, err := http.NewRequest("GET", "http://somehost.com/down", nil)
+	Expect(err).To(BeNil())
+	dbClient.processRequest(r)
+
+	count, err := dbClient.RequestCache.RecordsCount()
+	Expect(err).To(BeNil())
+	Expect(count).To(Equal(0))
+}
+
+func TestSimulate_ReplaysCapturedDNSErrorAsBadGateway(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	err := dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request: models.RequestDetails{Path: "/down", Method: "GET", Destination: "somehost.com", Scheme: "http"},
+		Error:   &models.UpstreamError{Type: models.UpstreamErrorDNS, Message: "lookup somehost.com: no such host"},
+	})
+	Expect(err).To(BeNil())
+
+	r, err := http.NewRequest("GET", "http://somehost.com/down", nil)
+	Expect(err).To(BeNil())
+
+	dbClient.Cfg.SetMode(SimulateMode)
+	_, resp := dbClient.processRequest(r)
+	Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+}
+
+func TestSimulate_ReplaysCapturedConnectionRefusedAsReset(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.Cfg.ProxyPort = "9808"
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.SetMode(SimulateMode)
+	err := dbClient.StartProxy()
+	Expect(err).To(BeNil())
+	defer dbClient.StopProxy()
+
+	err = dbClient.RequestMatcher.SavePayload(&models.Payload{
+		Request: models.RequestDetails{Path: "/down", Method: "GET", Destination: "localhost:9808"},
+		Error:   &models.UpstreamError{Type: models.UpstreamErrorConnectionRefused, Message: "connection refused"},
+	})
+	Expect(err).To(BeNil())
+
+	_, err = http.Get("http://localhost:9808/down")
+	Expect(err).ToNot(BeNil())
+}
+
+func TestPayloadView_CarriesUpstreamError(t *testing.T) {
+	RegisterTestingT(t)
+
+	payload := models.Payload{
+		Request: models.RequestDetails{Path: "/down", Method: "GET", Destination: "somehost.com"},
+		Error:   &models.UpstreamError{Type: models.UpstreamErrorTimeout, Message: "i/o timeout"},
+	}
+
+	view := payload.ConvertToPayloadView()
+	Expect(view.Error.Type).To(Equal(models.UpstreamErrorTimeout))
+	Expect(models.NewPayloadFromPayloadView(*view).Error).To(Equal(payload.Error))
+}
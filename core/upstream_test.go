@@ -0,0 +1,188 @@
+package hoverfly
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SpectoLabs/hoverfly/core/certs"
+	. "github.com/onsi/gomega"
+)
+
+func writeTempPem(t *testing.T, block *pem.Block) string {
+	f, err := ioutil.TempFile("", "hoverfly-pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pem.Encode(f, block)
+	f.Close()
+	return f.Name()
+}
+
+func newClientCertificateFiles(t *testing.T) (certPath, keyPath string) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "hoverfly.client"},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, priv.Public(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath = writeTempPem(t, &pem.Block{Type: "CERTIFICATE", Bytes: raw})
+	keyPath = writeTempPem(t, certs.PemBlockForKey(priv))
+	return
+}
+
+func newClientCertServer() (*httptest.Server, *int) {
+	presented := new(int)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*presented = len(r.TLS.PeerCertificates)
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	return server, presented
+}
+
+func TestUpstreamTransport_PresentsClientCertificateToMatchingDestination(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, presented := newClientCertServer()
+	defer server.Close()
+
+	certPath, keyPath := newClientCertificateFiles(t)
+	defer os.Remove(certPath)
+	defer os.Remove(keyPath)
+
+	cfg := &Configuration{
+		ClientCertificates: []ClientCertificate{
+			{DestinationPattern: "127.0.0.1", CertificatePath: certPath, KeyPath: keyPath},
+		},
+	}
+
+	transport, err := NewUpstreamTransport(cfg)
+	Expect(err).To(BeNil())
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	Expect(*presented).To(Equal(1))
+}
+
+func TestUpstreamTransport_DoesNotPresentClientCertificateToOtherDestinations(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, presented := newClientCertServer()
+	defer server.Close()
+
+	certPath, keyPath := newClientCertificateFiles(t)
+	defer os.Remove(certPath)
+	defer os.Remove(keyPath)
+
+	cfg := &Configuration{
+		ClientCertificates: []ClientCertificate{
+			{DestinationPattern: "partner.com", CertificatePath: certPath, KeyPath: keyPath},
+		},
+	}
+
+	transport, err := NewUpstreamTransport(cfg)
+	Expect(err).To(BeNil())
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	Expect(*presented).To(Equal(0))
+}
+
+func TestUpstreamTransport_VerifiesUpstreamWithCABundle(t *testing.T) {
+	RegisterTestingT(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	bundle := writeTempPem(t, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	defer os.Remove(bundle)
+
+	// without bundle server certificate is not trusted
+	transport, err := NewUpstreamTransport(&Configuration{TLSVerification: true})
+	Expect(err).To(BeNil())
+
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	Expect(err).ToNot(BeNil())
+
+	transport, err = NewUpstreamTransport(&Configuration{TLSVerification: true, UpstreamCABundle: bundle})
+	Expect(err).To(BeNil())
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+}
+
+func TestUpstreamTransport_SkipsVerificationWhenTurnedOff(t *testing.T) {
+	RegisterTestingT(t)
+
+	transport, err := NewUpstreamTransport(&Configuration{TLSVerification: false})
+	Expect(err).To(BeNil())
+	Expect(transport.Default.TLSClientConfig.InsecureSkipVerify).To(BeTrue())
+}
+
+func TestNewUpstreamTransport_ErrorsOnInvalidDestinationPattern(t *testing.T) {
+	RegisterTestingT(t)
+
+	cfg := &Configuration{
+		ClientCertificates: []ClientCertificate{
+			{DestinationPattern: "*", CertificatePath: "cert.pem", KeyPath: "key.pem"},
+		},
+	}
+
+	_, err := NewUpstreamTransport(cfg)
+	Expect(err).ToNot(BeNil())
+}
+
+func TestNewUpstreamTransport_ErrorsOnBundleWithoutCertificates(t *testing.T) {
+	RegisterTestingT(t)
+
+	bundle := writeTempPem(t, &pem.Block{Type: "NOT A CERTIFICATE", Bytes: []byte("nope")})
+	defer os.Remove(bundle)
+
+	_, err := NewUpstreamTransport(&Configuration{UpstreamCABundle: bundle})
+	Expect(err).ToNot(BeNil())
+}
+
+func TestConfigureUpstreamTLS_ReplacesHTTPClient(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+
+	err := dbClient.ConfigureUpstreamTLS()
+	Expect(err).To(BeNil())
+
+	_, ok := dbClient.HTTP.Transport.(*UpstreamTransport)
+	Expect(ok).To(BeTrue())
+}
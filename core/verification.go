@@ -0,0 +1,20 @@
+package hoverfly
+
+import (
+	"github.com/SpectoLabs/hoverfly/core/matching"
+	"github.com/SpectoLabs/hoverfly/core/views"
+)
+
+// Verify - returns journaled requests matching given template, oldest first. Only requests still kept
+// in the journal can be verified. Journaled requests are redacted, so redacted values only match the
+// redaction placeholder.
+func (hf *Hoverfly) Verify(template matching.RequestTemplate) *views.VerificationView {
+	view := &views.VerificationView{Data: []views.JournalEntryView{}}
+	for _, entry := range hf.Journal.Entries(nil) {
+		if template.Matches(entry.ConvertToRequestDetails(), hf.Cfg.Webserver) {
+			view.Data = append(view.Data, entry.ConvertToJournalEntryView())
+		}
+	}
+	view.Count = len(view.Data)
+	return view
+}
@@ -0,0 +1,83 @@
+package hoverfly
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/matching"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func TestVerify_CountsJournaledRequestsMatchingTemplate(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	for _, body := range []string{`{"amount": 10}`, `{"amount": 10}`, `{"amount": 20}`} {
+		r, err := http.NewRequest("POST", "http://payments.com/payments", bytes.NewBufferString(body))
+		Expect(err).To(BeNil())
+		r.Header.Set("Content-Type", "application/json")
+		_, resp := dbClient.processRequest(r)
+		Expect(resp.StatusCode).To(Equal(201))
+	}
+	r, err := http.NewRequest("GET", "http://payments.com/payments", nil)
+	Expect(err).To(BeNil())
+	dbClient.processRequest(r)
+
+	method, path, body := "POST", "/payments", `{"amount": 10}`
+	verification := dbClient.Verify(matching.RequestTemplate{Method: &method, Path: &path, Body: &body})
+	Expect(verification.Count).To(Equal(2))
+	Expect(verification.Data[0].Body).To(Equal(body))
+	Expect(verification.Data[0].Status).To(Equal(201))
+
+	verification = dbClient.Verify(matching.RequestTemplate{
+		Method:  &method,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+	})
+	Expect(verification.Count).To(Equal(3))
+
+	verification = dbClient.Verify(matching.RequestTemplate{Headers: map[string][]string{"Content-Type": {"text/plain"}}})
+	Expect(verification.Count).To(Equal(0))
+	Expect(verification.Data).To(BeEmpty())
+}
+
+func TestVerifyHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+	m := getBoneRouter(dbClient)
+
+	dbClient.Cfg.SetMode(CaptureMode)
+	r, err := http.NewRequest("POST", "http://payments.com/payments", bytes.NewBufferString("paid"))
+	Expect(err).To(BeNil())
+	dbClient.processRequest(r)
+
+	req, err := http.NewRequest("POST", "/api/verify", bytes.NewBufferString(`{"method": "POST", "destination": "payments.com", "body": "paid"}`))
+	Expect(err).To(BeNil())
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+
+	respBody, err := ioutil.ReadAll(rec.Body)
+	Expect(err).To(BeNil())
+	var view views.VerificationView
+	Expect(json.Unmarshal(respBody, &view)).To(BeNil())
+	Expect(view.Count).To(Equal(1))
+	Expect(view.Data[0].Path).To(Equal("/payments"))
+
+	req, err = http.NewRequest("POST", "/api/verify", bytes.NewBufferString(`not json`))
+	Expect(err).To(BeNil())
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusBadRequest))
+}
@@ -1,13 +1,266 @@
 package views
 
+import "encoding/json"
+
 type PayloadViewData struct {
-	Data []PayloadView `json:"data"`
+	Data              []PayloadView          `json:"data"`
+	FingerprintPolicy *FingerprintPolicyView `json:"fingerprintPolicy,omitempty"`
+	RedactionRules    *RedactionRulesView    `json:"redactionRules,omitempty"`
+}
+
+// FingerprintPolicyView is used when marshalling and unmarshalling FingerprintPolicy
+type FingerprintPolicyView struct {
+	Headers            []string `json:"headers"`
+	IgnoredQueryParams []string `json:"ignoredQueryParams"`
+	SortQuery          bool     `json:"sortQuery"`
+	IgnoredBodyPaths   []string `json:"ignoredBodyPaths"`
+}
+
+// RedactionRulesView is used when marshalling and unmarshalling RedactionRules
+type RedactionRulesView struct {
+	Headers      []string `json:"headers"`
+	QueryParams  []string `json:"queryParams"`
+	BodyPaths    []string `json:"bodyPaths"`
+	BodyPatterns []string `json:"bodyPatterns"`
+	Placeholder  string   `json:"placeholder"`
+}
+
+// CaptureFilterView is used when marshalling and unmarshalling CaptureFilter
+type CaptureFilterView struct {
+	Methods      []string `json:"methods,omitempty"`
+	Path         string   `json:"path,omitempty"`
+	StatusFrom   int      `json:"statusFrom,omitempty"`
+	StatusTo     int      `json:"statusTo,omitempty"`
+	ContentTypes []string `json:"contentTypes,omitempty"`
+}
+
+// CaptureFiltersView is used when marshalling and unmarshalling CaptureFilters
+type CaptureFiltersView struct {
+	Include []CaptureFilterView `json:"include"`
+	Exclude []CaptureFilterView `json:"exclude"`
+}
+
+// CaptureTagsView is used when marshalling and unmarshalling tags added to captured payloads
+type CaptureTagsView struct {
+	Tags []string `json:"tags"`
+}
+
+// LatencyReplayView is used when marshalling and unmarshalling latency replay settings
+type LatencyReplayView struct {
+	Enabled bool    `json:"enabled"`
+	Factor  float64 `json:"factor"`
+}
+
+// ChaosView is used when marshalling and unmarshalling Chaos
+type ChaosView struct {
+	Seed  *int64          `json:"seed,omitempty"`
+	Rules []ChaosRuleView `json:"rules"`
+}
+
+// ChaosRuleView is used when marshalling and unmarshalling ChaosRule
+type ChaosRuleView struct {
+	Destination string                `json:"destination,omitempty"`
+	Path        string                `json:"path,omitempty"`
+	Percentage  float64               `json:"percentage"`
+	Responses   []ResponseDetailsView `json:"responses"`
+}
+
+// RateLimitView is used when marshalling and unmarshalling RateLimit, period is in seconds
+type RateLimitView struct {
+	Destination string `json:"destination,omitempty"`
+	Path        string `json:"path,omitempty"`
+	KeyHeader   string `json:"keyHeader,omitempty"`
+	Limit       int    `json:"limit"`
+	Period      int    `json:"period"`
+}
+
+// RateLimitsView is used when marshalling and unmarshalling rate limits
+type RateLimitsView struct {
+	Data []RateLimitView `json:"data"`
+}
+
+// RateLimitBucketView is used when marshalling state of a rate limit bucket, reset is in seconds
+type RateLimitBucketView struct {
+	Destination string `json:"destination,omitempty"`
+	Path        string `json:"path,omitempty"`
+	KeyHeader   string `json:"keyHeader,omitempty"`
+	Key         string `json:"key"`
+	Limit       int    `json:"limit"`
+	Remaining   int    `json:"remaining"`
+	Reset       int64  `json:"reset"`
+}
+
+// RateLimitStateView is used when marshalling state of all rate limit buckets
+type RateLimitStateView struct {
+	Data []RateLimitBucketView `json:"data"`
+}
+
+// JournalEntryView is used when marshalling JournalEntry, durations are in milliseconds
+type JournalEntryView struct {
+	Time           string              `json:"time"`
+	Mode           string              `json:"mode"`
+	Method         string              `json:"method"`
+	Scheme         string              `json:"scheme"`
+	Destination    string              `json:"destination"`
+	Path           string              `json:"path"`
+	Query          string              `json:"query"`
+	Headers        map[string][]string `json:"headers"`
+	Body           string              `json:"body"`
+	Match          string              `json:"match,omitempty"`
+	MatchedID      string              `json:"matchedId,omitempty"`
+	MissReason     string              `json:"missReason,omitempty"`
+	Status         int                 `json:"status"`
+	Delay          int64               `json:"delay"`
+	MiddlewareTime int64               `json:"middlewareTime"`
+	Latency        int64               `json:"latency"`
+}
+
+// JournalView is used when marshalling journal entries, oldest entry first
+type JournalView struct {
+	Data []JournalEntryView `json:"data"`
+}
+
+// VerificationView is used when marshalling journaled requests matching a request template
+type VerificationView struct {
+	Count int                `json:"count"`
+	Data  []JournalEntryView `json:"data"`
+}
+
+// CoverageEntryView describes a record, identified by its key, or a template, identified by its index,
+// and how many times it was matched
+type CoverageEntryView struct {
+	Key         string `json:"key,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Template    *int   `json:"template,omitempty"`
+	Method      string `json:"method"`
+	Destination string `json:"destination"`
+	Path        string `json:"path"`
+	Query       string `json:"query"`
+	Hits        int    `json:"hits"`
+}
+
+// CoverageView is used when marshalling simulation coverage, coverage is the percentage of records
+// and templates that were matched at least once
+type CoverageView struct {
+	Records   int                 `json:"records"`
+	Templates int                 `json:"templates"`
+	Used      int                 `json:"used"`
+	Coverage  float64             `json:"coverage"`
+	Unused    []CoverageEntryView `json:"unused"`
+	MostUsed  []CoverageEntryView `json:"mostUsed"`
+}
+
+// EventView is used when marshalling hook entries sent to event stream subscribers, data is a JSON
+// document which depends on action type
+type EventView struct {
+	ActionType string          `json:"actionType"`
+	Time       string          `json:"time"`
+	Message    string          `json:"message"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
+// ModeChangeView is used when marshalling mode switch events
+type ModeChangeView struct {
+	Mode         string `json:"mode"`
+	PreviousMode string `json:"previousMode"`
+}
+
+// ConfigurationChangeView is used when marshalling configuration change events, value is omitted when
+// setting was deleted
+type ConfigurationChangeView struct {
+	Setting string          `json:"setting"`
+	Value   json.RawMessage `json:"value,omitempty"`
+}
+
+// HookView is used when marshalling and unmarshalling hooks registered at runtime. Webhooks use URL and
+// Retries, file hooks use Path, MaxSize in bytes and MaxFiles.
+type HookView struct {
+	ID          string   `json:"id,omitempty"`
+	Type        string   `json:"type"`
+	ActionTypes []string `json:"actionTypes,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Retries     *int     `json:"retries,omitempty"`
+	Path        string   `json:"path,omitempty"`
+	MaxSize     int64    `json:"maxSize,omitempty"`
+	MaxFiles    int      `json:"maxFiles,omitempty"`
+}
+
+// HooksView is used when marshalling and unmarshalling hooks registered at runtime
+type HooksView struct {
+	Data []HookView `json:"data"`
+}
+
+// CapturePolicyView is used when marshalling and unmarshalling capture policy
+type CapturePolicyView struct {
+	Policy string `json:"policy"`
+}
+
+// CaptureHistoryEntryView is used when marshalling CaptureHistoryEntry
+type CaptureHistoryEntryView struct {
+	Index      int         `json:"index"`
+	CapturedAt string      `json:"capturedAt"`
+	Active     bool        `json:"active"`
+	Payload    PayloadView `json:"payload"`
+}
+
+// CaptureHistoryView is used when marshalling CaptureHistory of one request
+type CaptureHistoryView struct {
+	Key     string                    `json:"key"`
+	Entries []CaptureHistoryEntryView `json:"entries"`
+}
+
+// CaptureHistorySummaryView describes request with capture history
+type CaptureHistorySummaryView struct {
+	Key         string `json:"key"`
+	Method      string `json:"method"`
+	Destination string `json:"destination"`
+	Path        string `json:"path"`
+	Query       string `json:"query"`
+	Entries     int    `json:"entries"`
+}
+
+// CaptureHistoryListView is used when marshalling list of requests with capture history
+type CaptureHistoryListView struct {
+	Data []CaptureHistorySummaryView `json:"data"`
+}
+
+// PromoteCaptureView is used when unmarshalling history entry that should become active
+type PromoteCaptureView struct {
+	Index *int `json:"index"`
 }
 
 // PayloadView is used when marshalling and unmarshalling payloads.
 type PayloadView struct {
-	Response ResponseDetailsView `json:"response"`
-	Request  RequestDetailsView  `json:"request"`
+	Response ResponseDetailsView  `json:"response"`
+	Request  RequestDetailsView   `json:"request"`
+	Metadata *CaptureMetadataView `json:"metadata,omitempty"`
+	Error    *UpstreamErrorView   `json:"error,omitempty"`
+}
+
+// UpstreamErrorView is used when marshalling and unmarshalling UpstreamError
+type UpstreamErrorView struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// CaptureMetadataView is used when marshalling and unmarshalling CaptureMetadata, latency is in milliseconds
+// with sub-millisecond precision
+type CaptureMetadataView struct {
+	ID         string          `json:"id"`
+	CapturedAt string          `json:"capturedAt"`
+	Latency    float64         `json:"latency"`
+	RemoteAddr string          `json:"remoteAddr"`
+	TLS        *TLSDetailsView `json:"tls,omitempty"`
+	Mode       string          `json:"mode"`
+	Tags       []string        `json:"tags"`
+}
+
+// TLSDetailsView is used when marshalling and unmarshalling TLSDetails
+type TLSDetailsView struct {
+	Version            string `json:"version"`
+	CipherSuite        string `json:"cipherSuite"`
+	ServerName         string `json:"serverName"`
+	NegotiatedProtocol string `json:"negotiatedProtocol"`
 }
 
 // RequestDetailsView is used when marshalling and unmarshalling RequestDetails
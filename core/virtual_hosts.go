@@ -0,0 +1,86 @@
+package hoverfly
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// VirtualHost - simulated service served by the webserver. Requests are routed to a virtual host
+// either by Host header or by the port they were received on, when Port is set. Destination is
+// used as request host so every virtual host has its own namespace in the cache.
+type VirtualHost struct {
+	Destination string
+	Port        string
+}
+
+// HashWithoutHost - webserver without virtual hosts simulates a single service, so request host
+// is not part of the request hash
+func (c *Configuration) HashWithoutHost() bool {
+	return c.Webserver && len(c.VirtualHosts) == 0
+}
+
+// virtualHostDestination - returns destination of virtual host matching given Host header
+func (c *Configuration) virtualHostDestination(host string) (string, bool) {
+	hostname := hostWithoutPort(host)
+	for _, vh := range c.VirtualHosts {
+		if strings.EqualFold(vh.Destination, hostname) {
+			return vh.Destination, true
+		}
+	}
+	return "", false
+}
+
+// startVirtualHosts - starts a webserver listener for every virtual host with a port, requests
+// received on these ports are served as requests to virtual host destination
+func (hf *Hoverfly) startVirtualHosts(tlsConfig *tls.Config) error {
+	for _, vh := range hf.Cfg.VirtualHosts {
+		if vh.Port == "" {
+			continue
+		}
+
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%s", vh.Port))
+		if err != nil {
+			return err
+		}
+
+		sl, err := NewStoppableListener(listener)
+		if err != nil {
+			return err
+		}
+		sl.conns = &hf.conns
+		hf.VirtualHostSLs = append(hf.VirtualHostSLs, sl)
+
+		var serverListener net.Listener = sl
+		if tlsConfig != nil {
+			serverListener = tls.NewListener(sl, tlsConfig)
+		}
+
+		server := http.Server{Handler: virtualHostHandler(vh.Destination, hf.Proxy)}
+
+		log.WithFields(log.Fields{
+			"destination": vh.Destination,
+			"port":        vh.Port,
+		}).Info("serving virtual host")
+
+		hf.Cfg.ProxyControlWG.Add(1)
+		go func() {
+			defer hf.Cfg.ProxyControlWG.Done()
+			log.Warn(server.Serve(serverListener))
+		}()
+	}
+
+	return nil
+}
+
+// virtualHostHandler - replaces Host header of every request with virtual host destination
+func virtualHostHandler(destination string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Host = destination
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,92 @@
+package hoverfly
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/models"
+	. "github.com/onsi/gomega"
+)
+
+func TestHashWithoutHost(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect((&Configuration{}).HashWithoutHost()).To(BeFalse())
+	Expect((&Configuration{Webserver: true}).HashWithoutHost()).To(BeTrue())
+	Expect((&Configuration{Webserver: true, VirtualHosts: []VirtualHost{{Destination: "api.service.com"}}}).HashWithoutHost()).To(BeFalse())
+}
+
+func TestVirtualHostDestination_MatchesHostHeaderWithoutPort(t *testing.T) {
+	RegisterTestingT(t)
+
+	cfg := &Configuration{VirtualHosts: []VirtualHost{{Destination: "api.service.com"}}}
+
+	destination, ok := cfg.virtualHostDestination("API.service.com:8500")
+	Expect(ok).To(BeTrue())
+	Expect(destination).To(Equal("api.service.com"))
+
+	_, ok = cfg.virtualHostDestination("localhost:8500")
+	Expect(ok).To(BeFalse())
+}
+
+func TestWebserverServesVirtualHostsByHostHeaderAndPort(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+	defer dbClient.RequestCache.DeleteData()
+
+	dbClient.Cfg.ProxyPort = "9795"
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.SetMode(SimulateMode)
+	dbClient.Cfg.VirtualHosts = []VirtualHost{
+		{Destination: "users.service.com"},
+		{Destination: "orders.service.com", Port: "9796"},
+	}
+
+	err := dbClient.StartProxy()
+	Expect(err).To(BeNil())
+	defer dbClient.StopProxy()
+
+	for _, destination := range []string{"users.service.com", "orders.service.com"} {
+		err = dbClient.RequestMatcher.SavePayload(&models.Payload{
+			Request:  models.RequestDetails{Path: "/items", Method: "GET", Destination: destination},
+			Response: models.ResponseDetails{Status: 200, Body: destination},
+		})
+		Expect(err).To(BeNil())
+	}
+
+	get := func(url, host string) string {
+		req, err := http.NewRequest("GET", url, nil)
+		Expect(err).To(BeNil())
+		req.Host = host
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).To(BeNil())
+		return string(body)
+	}
+
+	Expect(get("http://localhost:9795/items", "users.service.com:9795")).To(Equal("users.service.com"))
+	Expect(get("http://localhost:9795/items", "orders.service.com")).To(Equal("orders.service.com"))
+	Expect(get("http://localhost:9796/items", "localhost:9796")).To(Equal("orders.service.com"))
+}
+
+func TestStartProxy_ErrorsWhenVirtualHostsAreUsedWithUpstream(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(200, `{'message': 'here'}`)
+	defer server.Close()
+
+	dbClient.Cfg.ProxyPort = "9797"
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.WebserverUpstream = "http://api.service.com"
+	dbClient.Cfg.VirtualHosts = []VirtualHost{{Destination: "users.service.com"}}
+
+	err := dbClient.StartProxy()
+	Expect(err).ToNot(BeNil())
+}
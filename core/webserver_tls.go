@@ -0,0 +1,75 @@
+package hoverfly
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/SpectoLabs/hoverfly/core/certs"
+	"github.com/rusenask/goproxy"
+)
+
+// DefaultWebserverCertificateValidity - validity of certificates minted for webserver hostnames
+const DefaultWebserverCertificateValidity = 365 * 24 * time.Hour
+
+// WebserverTLSEnabled - webserver is served over HTTPS when certificate, key or hostnames are configured
+func (c *Configuration) WebserverTLSEnabled() bool {
+	return c.WebserverTLSCertificate != "" || c.WebserverTLSKey != "" || len(c.WebserverTLSHosts) > 0
+}
+
+// ValidateWebserverTLS - certificate and key used to serve webserver mode over HTTPS have to be given together
+func (c *Configuration) ValidateWebserverTLS() error {
+	if (c.WebserverTLSCertificate == "") != (c.WebserverTLSKey == "") {
+		return fmt.Errorf("webserver certificate and key have to be given together")
+	}
+	return nil
+}
+
+// webserverTLSConfig - returns TLS configuration used when serving webserver mode over HTTPS. Supplied
+// certificate and key take precedence, otherwise certificate for configured hostnames is signed with
+// Hoverfly CA. Returns nil when TLS is not enabled.
+func (hf *Hoverfly) webserverTLSConfig() (*tls.Config, error) {
+	if !hf.Cfg.WebserverTLSEnabled() {
+		return nil, nil
+	}
+	if err := hf.Cfg.ValidateWebserverTLS(); err != nil {
+		return nil, err
+	}
+
+	var certificate tls.Certificate
+	if hf.Cfg.WebserverTLSCertificate != "" {
+		loaded, err := tls.LoadX509KeyPair(hf.Cfg.WebserverTLSCertificate, hf.Cfg.WebserverTLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load webserver certificate and key pair, got error: %s", err.Error())
+		}
+		certificate = loaded
+	} else {
+		minted, err := certs.NewHostCertificate(goproxy.GoproxyCa, hf.Cfg.WebserverTLSHosts, DefaultWebserverCertificateValidity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webserver certificate, got error: %s", err.Error())
+		}
+		certificate = *minted
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+	}
+
+	if hf.Cfg.WebserverClientCA != "" {
+		clientCAs, err := loadCABundle(hf.Cfg.WebserverClientCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	log.WithFields(log.Fields{
+		"hosts":                hf.Cfg.WebserverTLSHosts,
+		"certificate":          hf.Cfg.WebserverTLSCertificate,
+		"clientAuthentication": hf.Cfg.WebserverClientCA != "",
+	}).Info("webserver will be served over HTTPS")
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,132 @@
+package hoverfly
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/rusenask/goproxy"
+)
+
+func hoverflyCAPool() *x509.CertPool {
+	caCert, _ := x509.ParseCertificate(goproxy.GoproxyCa.Certificate[0])
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return pool
+}
+
+func TestWebserverTLSEnabled(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect((&Configuration{}).WebserverTLSEnabled()).To(BeFalse())
+	Expect((&Configuration{WebserverTLSCertificate: "cert.pem"}).WebserverTLSEnabled()).To(BeTrue())
+	Expect((&Configuration{WebserverTLSCertificate: "cert.pem", WebserverTLSKey: "key.pem"}).WebserverTLSEnabled()).To(BeTrue())
+	Expect((&Configuration{WebserverTLSHosts: []string{"localhost"}}).WebserverTLSEnabled()).To(BeTrue())
+}
+
+func TestValidateWebserverTLS(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect((&Configuration{}).ValidateWebserverTLS()).To(BeNil())
+	Expect((&Configuration{WebserverTLSCertificate: "cert.pem", WebserverTLSKey: "key.pem"}).ValidateWebserverTLS()).To(BeNil())
+	Expect((&Configuration{WebserverTLSCertificate: "cert.pem"}).ValidateWebserverTLS()).ToNot(BeNil())
+	Expect((&Configuration{WebserverTLSKey: "key.pem", WebserverTLSHosts: []string{"localhost"}}).ValidateWebserverTLS()).ToNot(BeNil())
+}
+
+func TestWebserverServedOverHTTPSWithCertificateSignedByHoverflyCA(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+
+	dbClient.Cfg.ProxyPort = "9781"
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.WebserverTLSHosts = []string{"localhost"}
+
+	err := dbClient.StartProxy()
+	Expect(err).To(BeNil())
+	defer dbClient.StopProxy()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: hoverflyCAPool()},
+	}}
+
+	resp, err := client.Get(fmt.Sprintf("https://localhost:%s/path", dbClient.Cfg.ProxyPort))
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusPreconditionFailed))
+	Expect(resp.TLS.PeerCertificates[0].DNSNames).To(ConsistOf("localhost"))
+}
+
+func TestWebserverOverHTTPSRequiresClientCertificateWhenClientCAIsSet(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+
+	certPath, keyPath := newClientCertificateFiles(t)
+	defer os.Remove(certPath)
+	defer os.Remove(keyPath)
+
+	dbClient.Cfg.ProxyPort = "9782"
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.WebserverTLSHosts = []string{"localhost"}
+	dbClient.Cfg.WebserverClientCA = certPath
+
+	err := dbClient.StartProxy()
+	Expect(err).To(BeNil())
+	defer dbClient.StopProxy()
+
+	url := fmt.Sprintf("https://localhost:%s/path", dbClient.Cfg.ProxyPort)
+
+	anonymous := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: hoverflyCAPool()},
+	}}
+	_, err = anonymous.Get(url)
+	Expect(err).ToNot(BeNil())
+
+	clientCertificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	Expect(err).To(BeNil())
+
+	authenticated := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:      hoverflyCAPool(),
+			Certificates: []tls.Certificate{clientCertificate},
+		},
+	}}
+	resp, err := authenticated.Get(url)
+	Expect(err).To(BeNil())
+	Expect(resp.StatusCode).To(Equal(http.StatusPreconditionFailed))
+}
+
+func TestStartProxyFailsWhenWebserverCertificateCannotBeLoaded(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+
+	dbClient.Cfg.ProxyPort = "9783"
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.WebserverTLSCertificate = "does-not-exist.pem"
+	dbClient.Cfg.WebserverTLSKey = "does-not-exist-key.pem"
+
+	err := dbClient.StartProxy()
+	Expect(err).ToNot(BeNil())
+}
+
+func TestStartProxyFailsWhenOnlyWebserverCertificateIsGiven(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, dbClient := testTools(201, `{'message': 'here'}`)
+	defer server.Close()
+
+	dbClient.Cfg.ProxyPort = "9784"
+	dbClient.Cfg.Webserver = true
+	dbClient.Cfg.WebserverTLSCertificate = "cert.pem"
+
+	err := dbClient.StartProxy()
+	Expect(err).ToNot(BeNil())
+}
@@ -0,0 +1,95 @@
+package hoverfly_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bytes"
+	"github.com/dghubble/sling"
+	"io/ioutil"
+	"net/http"
+)
+
+var _ = Describe("When comparing Hoverfly running as a webserver with Hoverfly running as a proxy", func() {
+
+	records := `{"data":[
+		{"request": {"path": "/users", "method": "GET", "destination": "api.service.com", "scheme": "http", "query": "", "body": "", "headers": {}},
+		 "response": {"status": 201, "encodedBody": false, "body": "{\"users\":[]}", "headers": {"Content-Type": ["application/json"], "Cache-Control": ["no-cache"], "Set-Cookie": ["a=1", "b=2"], "X-Multi": ["one", "two", "three"]}}},
+		{"request": {"path": "/missing", "method": "POST", "destination": "api.service.com", "scheme": "http", "query": "", "body": "", "headers": {}},
+		 "response": {"status": 404, "encodedBody": false, "body": "not found", "headers": {"Content-Type": ["text/plain"]}}}
+	]}`
+
+	var proxyResponse, webserverResponse *http.Response
+	var proxyBody, webserverBody []byte
+
+	readBody := func(response *http.Response) []byte {
+		body, err := ioutil.ReadAll(response.Body)
+		Expect(err).To(BeNil())
+		return body
+	}
+
+	withoutDate := func(header http.Header) http.Header {
+		h := http.Header{}
+		for k, v := range header {
+			if k != "Date" {
+				h[k] = v
+			}
+		}
+		return h
+	}
+
+	compare := func(request func(base string) *sling.Sling) {
+		hoverflyCmd = startHoverfly(adminPort, proxyPort)
+		ImportHoverflyRecords(bytes.NewBufferString(records))
+		proxyResponse = DoRequestThroughProxy(request("http://api.service.com"))
+		proxyBody = readBody(proxyResponse)
+		hoverflyCmd.Process.Kill()
+
+		hoverflyCmd = startHoverflyWebServer(adminPort, proxyPort)
+		ImportHoverflyRecords(bytes.NewBufferString(records))
+		webserverResponse = DoRequest(request("http://localhost:" + proxyPortAsString))
+		webserverBody = readBody(webserverResponse)
+		hoverflyCmd.Process.Kill()
+	}
+
+	Context("and a GET request with multi-value response headers is simulated", func() {
+
+		BeforeEach(func() {
+			compare(func(base string) *sling.Sling {
+				return sling.New().Get(base + "/users")
+			})
+		})
+
+		It("should return the same status code", func() {
+			Expect(webserverResponse.StatusCode).To(Equal(201))
+			Expect(webserverResponse.StatusCode).To(Equal(proxyResponse.StatusCode))
+		})
+
+		It("should return the same body", func() {
+			Expect(string(webserverBody)).To(Equal(`{"users":[]}`))
+			Expect(webserverBody).To(Equal(proxyBody))
+		})
+
+		It("should return the same headers", func() {
+			Expect(webserverResponse.Header["Set-Cookie"]).To(Equal([]string{"a=1", "b=2"}))
+			Expect(webserverResponse.Header["X-Multi"]).To(Equal([]string{"one", "two", "three"}))
+			Expect(withoutDate(webserverResponse.Header)).To(Equal(withoutDate(proxyResponse.Header)))
+		})
+	})
+
+	Context("and a recorded error response is simulated", func() {
+
+		BeforeEach(func() {
+			compare(func(base string) *sling.Sling {
+				return sling.New().Post(base + "/missing")
+			})
+		})
+
+		It("should return the same response", func() {
+			Expect(webserverResponse.StatusCode).To(Equal(404))
+			Expect(webserverResponse.StatusCode).To(Equal(proxyResponse.StatusCode))
+			Expect(webserverBody).To(Equal(proxyBody))
+			Expect(withoutDate(webserverResponse.Header)).To(Equal(withoutDate(proxyResponse.Header)))
+		})
+	})
+})
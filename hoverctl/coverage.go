@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+	"github.com/dghubble/sling"
+)
+
+// GetCoverage - returns records and templates that were never matched and top most matched ones
+func (h *Hoverfly) GetCoverage(top int) (*views.CoverageView, error) {
+	url := h.buildURL("/api/coverage?top=" + strconv.Itoa(top))
+
+	slingRequest := sling.New().Get(url)
+	response, err := h.performAPIRequest(slingRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == 401 {
+		return nil, errors.New("Hoverfly requires authentication")
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != 200 {
+		return nil, errors.New("Could not get coverage from Hoverfly: " + string(body))
+	}
+
+	var coverage views.CoverageView
+	if err = json.Unmarshal(body, &coverage); err != nil {
+		return nil, err
+	}
+
+	return &coverage, nil
+}
+
+// PrintCoverage - writes coverage summary followed by unused and most used entries
+func PrintCoverage(out io.Writer, coverage *views.CoverageView) {
+	fmt.Fprintf(out, "Coverage: %.1f%% (%d of %d records and templates used)\n",
+		coverage.Coverage, coverage.Used, coverage.Records+coverage.Templates)
+
+	fmt.Fprintf(out, "\nUnused (%d):\n", len(coverage.Unused))
+	for _, entry := range coverage.Unused {
+		fmt.Fprintf(out, "  %s\n", describeCoverageEntry(entry))
+	}
+
+	fmt.Fprintf(out, "\nMost used:\n")
+	for _, entry := range coverage.MostUsed {
+		fmt.Fprintf(out, "  %6d  %s\n", entry.Hits, describeCoverageEntry(entry))
+	}
+}
+
+func describeCoverageEntry(entry views.CoverageEntryView) string {
+	request := strings.TrimSpace(entry.Method + " " + entry.Destination + entry.Path)
+	if entry.Query != "" {
+		request += "?" + entry.Query
+	}
+
+	if entry.Template != nil {
+		return fmt.Sprintf("template %d: %s", *entry.Template, request)
+	}
+	if entry.ID != "" {
+		return fmt.Sprintf("record %s: %s", entry.ID, request)
+	}
+	return fmt.Sprintf("record %s: %s", entry.Key, request)
+}
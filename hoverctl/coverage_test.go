@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Hoverfly_GetCoverage_AsksForTopEntries(t *testing.T) {
+	RegisterTestingT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.URL.Path).To(Equal("/api/coverage"))
+		Expect(r.URL.Query().Get("top")).To(Equal("3"))
+		w.Write([]byte(`{"records": 2, "templates": 0, "used": 1, "coverage": 50, "unused": [{"key": "abc", "hits": 0}], "mostUsed": [{"key": "def", "hits": 4}]}`))
+	}))
+	defer server.Close()
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	Expect(err).To(BeNil())
+	hoverfly := Hoverfly{Host: host, AdminPort: port, httpClient: http.DefaultClient}
+
+	coverage, err := hoverfly.GetCoverage(3)
+	Expect(err).To(BeNil())
+	Expect(coverage.Coverage).To(Equal(50.0))
+	Expect(coverage.Unused[0].Key).To(Equal("abc"))
+	Expect(coverage.MostUsed[0].Hits).To(Equal(4))
+}
+
+func Test_PrintCoverage(t *testing.T) {
+	RegisterTestingT(t)
+
+	template := 0
+	var out bytes.Buffer
+	PrintCoverage(&out, &views.CoverageView{
+		Records:   2,
+		Templates: 1,
+		Used:      1,
+		Coverage:  100.0 / 3,
+		Unused: []views.CoverageEntryView{
+			{Key: "abc", Method: "GET", Destination: "api.com", Path: "/unused", Query: "q=1"},
+			{Template: &template, Path: "/template"},
+		},
+		MostUsed: []views.CoverageEntryView{
+			{Key: "def", ID: "capture-1", Method: "POST", Destination: "api.com", Path: "/used", Hits: 4},
+		},
+	})
+
+	Expect(out.String()).To(Equal(`Coverage: 33.3% (1 of 3 records and templates used)
+
+Unused (2):
+  record abc: GET api.com/unused?q=1
+  template 0: /template
+
+Most used:
+       4  record capture-1: POST api.com/used
+`))
+}
@@ -25,9 +25,26 @@ type APIDelaySchema struct {
 }
 
 type ResponseDelaySchema struct {
-	UrlPattern string `json:"urlpattern"`
-	Delay      int `json:"delay"`
-	HttpMethod string `json:"httpmethod"`
+	UrlPattern   string   `json:"urlpattern"`
+	Delay        int      `json:"delay"`
+	HttpMethod   string   `json:"httpmethod"`
+	Distribution string   `json:"distribution,omitempty"`
+	Min          int      `json:"min,omitempty"`
+	Max          int      `json:"max,omitempty"`
+	P50          int      `json:"p50,omitempty"`
+	P95          int      `json:"p95,omitempty"`
+	P99          int      `json:"p99,omitempty"`
+	Probability  *float64 `json:"probability,omitempty"`
+}
+
+// String - prints delay the way %+v does, probability is printed as its value rather than its address
+func (d ResponseDelaySchema) String() string {
+	probability := "<nil>"
+	if d.Probability != nil {
+		probability = strconv.FormatFloat(*d.Probability, 'g', -1, 64)
+	}
+	return fmt.Sprintf("{UrlPattern:%s Delay:%d HttpMethod:%s Distribution:%s Min:%d Max:%d P50:%d P95:%d P99:%d Probability:%s}",
+		d.UrlPattern, d.Delay, d.HttpMethod, d.Distribution, d.Min, d.Max, d.P50, d.P95, d.P99, probability)
 }
 
 type HoverflyAuthSchema struct {
@@ -176,7 +193,7 @@ func (h *Hoverfly) GetMode() (string, error) {
 
 // Set will go the state endpoint in Hoverfly, sending JSON that will set the mode of Hoverfly
 func (h *Hoverfly) SetMode(mode string) (string, error) {
-	if mode != "simulate" && mode != "capture" && mode != "modify" && mode != "synthesize" {
+	if mode != "simulate" && mode != "capture" && mode != "modify" && mode != "synthesize" && mode != "spy" {
 		return "", errors.New(mode + " is not a valid mode")
 	}
 
@@ -207,7 +224,7 @@ func (h *Hoverfly) SetMode(mode string) (string, error) {
 	}
 
 	if response.StatusCode == 403 {
-		return "", errors.New("Cannot change the mode of Hoverfly when running as a webserver")
+		return "", errors.New("Cannot change the mode of Hoverfly when running as a webserver without an upstream")
 	}
 
 
@@ -330,8 +347,11 @@ func (h *Hoverfly) ImportSimulation(payload string) (error) {
 	return nil
 }
 
-func (h *Hoverfly) ExportSimulation() ([]byte, error) {
+func (h *Hoverfly) ExportSimulation(usedOnly bool) ([]byte, error) {
 	url := h.buildURL("/api/records")
+	if usedOnly {
+		url = h.buildURL("/api/records?used=true")
+	}
 
 	slingRequest := sling.New().Get(url)
 	slingRequest, err := h.addAuthIfNeeded(slingRequest)
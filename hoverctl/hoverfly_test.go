@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"testing"
 	. "github.com/onsi/gomega"
 )
@@ -33,4 +34,17 @@ func Test_Hoverfly_isLocal_WhenAnotherDNS(t *testing.T) {
 	result := hoverfly.isLocal()
 
 	Expect(result).To(BeFalse())
-}
\ No newline at end of file
+}
+
+func Test_ResponseDelaySchema_KeepsZeroProbability(t *testing.T) {
+	RegisterTestingT(t)
+
+	var delay ResponseDelaySchema
+	Expect(json.Unmarshal([]byte(`{"urlpattern": ".", "delay": 100, "probability": 0}`), &delay)).To(BeNil())
+	Expect(delay.Probability).ToNot(BeNil())
+
+	data, err := json.Marshal(delay)
+	Expect(err).To(BeNil())
+	Expect(string(data)).To(ContainSubstring(`"probability":0`))
+	Expect(delay.String()).To(HaveSuffix("Probability:0}"))
+}
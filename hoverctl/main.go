@@ -29,6 +29,7 @@ var (
 
 	exportCommand = kingpin.Command("export", "Exports data out of Hoverfly")
 	exportNameArg = exportCommand.Arg("name", "Name of exported simulation").Required().String()
+	exportUsedOnlyFlag = exportCommand.Flag("used-only", "Export only records that were matched since coverage was last reset").Bool()
 
 	importCommand = kingpin.Command("import", "Imports data into Hoverfly")
 	importNameArg = importCommand.Arg("name", "Name of imported simulation").Required().String()
@@ -52,6 +53,20 @@ var (
 	templatesCommand = kingpin.Command("templates", "Get set of request templates currently loaded in Hoverfly")
 	templatesPathArg = templatesCommand.Arg("path", "Add JSON config to set of request templates in Hoverfly").String()
 
+	verifyCommand = kingpin.Command("verify", "Verify that Hoverfly received requests matching a request template, exits with non-zero status when it didn't. Requests are journaled with redaction rules applied, so redacted headers, query params and body values only match the redaction placeholder")
+	verifyTemplateArg = verifyCommand.Arg("template", "JSON file with request template, flags take precedence").String()
+	verifyMethodFlag = verifyCommand.Flag("method", "Method of the request").String()
+	verifySchemeFlag = verifyCommand.Flag("scheme", "Scheme of the request").String()
+	verifyDestinationFlag = verifyCommand.Flag("destination", "Destination of the request").String()
+	verifyPathFlag = verifyCommand.Flag("path", "Path of the request").String()
+	verifyQueryFlag = verifyCommand.Flag("query", "Raw query of the request").String()
+	verifyBodyFlag = verifyCommand.Flag("body", "Body of the request").String()
+	verifyHeaderFlags = verifyCommand.Flag("header", "Header of the request as 'Name: value', can be repeated").Strings()
+	verifyTimesFlag = verifyCommand.Flag("times", "Exact number of matching requests expected, at least one is expected by default").Default("-1").Int()
+
+	coverageCommand = kingpin.Command("coverage", "Get records and templates that were never matched and the most matched ones")
+	coverageTopFlag = coverageCommand.Flag("top", "Number of the most matched records and templates to list").Default("10").Int()
+
 )
 
 func main() {
@@ -138,7 +153,7 @@ func main() {
 			simulation, err := NewSimulation(*exportNameArg)
 			handleIfError(err)
 
-			simulationData, err := hoverfly.ExportSimulation()
+			simulationData, err := hoverfly.ExportSimulation(*exportUsedOnlyFlag)
 			handleIfError(err)
 
 			err = localCache.WriteSimulation(simulation, simulationData)
@@ -279,6 +294,34 @@ func main() {
 				}
 				fmt.Println(string(requestTemplatesJson))
 			}
+		case verifyCommand.FullCommand():
+			template, err := NewVerificationTemplate(*verifyTemplateArg, VerificationFlags{
+				Method: *verifyMethodFlag,
+				Scheme: *verifySchemeFlag,
+				Destination: *verifyDestinationFlag,
+				Path: *verifyPathFlag,
+				Query: *verifyQueryFlag,
+				Body: *verifyBodyFlag,
+				Headers: *verifyHeaderFlags,
+			})
+			handleIfError(err)
+
+			verification, err := hoverfly.Verify(template)
+			handleIfError(err)
+
+			for _, request := range verification.Data {
+				log.Debug(request.Time, " ", request.Method, " ", request.Scheme, "://", request.Destination, request.Path, " ", request.Status)
+			}
+
+			err = CheckVerification(verification, *verifyTimesFlag)
+			handleIfError(err)
+
+			log.Info("Hoverfly received ", verification.Count, " matching requests")
+		case coverageCommand.FullCommand():
+			coverage, err := hoverfly.GetCoverage(*coverageTopFlag)
+			handleIfError(err)
+
+			PrintCoverage(os.Stdout, coverage)
 		}
 }
 
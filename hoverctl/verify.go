@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/SpectoLabs/hoverfly/core/matching"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	"github.com/dghubble/sling"
+)
+
+// VerificationFlags - request fields given on the command line, empty fields are not matched
+type VerificationFlags struct {
+	Method      string
+	Scheme      string
+	Destination string
+	Path        string
+	Query       string
+	Body        string
+	Headers     []string
+}
+
+// NewVerificationTemplate - reads request template from JSON file when path is given, fields set by flags
+// take precedence. Headers are given as 'Name: value', names are canonicalized as they are in requests.
+func NewVerificationTemplate(path string, flags VerificationFlags) (matching.RequestTemplate, error) {
+	var template matching.RequestTemplate
+
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return template, err
+		}
+		if err = json.Unmarshal(data, &template); err != nil {
+			return template, errors.New("Could not read request template from " + path + ": " + err.Error())
+		}
+	}
+
+	setIfGiven(&template.Method, flags.Method)
+	setIfGiven(&template.Scheme, flags.Scheme)
+	setIfGiven(&template.Destination, flags.Destination)
+	setIfGiven(&template.Path, flags.Path)
+	setIfGiven(&template.Query, flags.Query)
+	setIfGiven(&template.Body, flags.Body)
+
+	for _, header := range flags.Headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return template, errors.New("Header must be given as 'Name: value', got '" + header + "'")
+		}
+		if template.Headers == nil {
+			template.Headers = make(map[string][]string)
+		}
+		name := http.CanonicalHeaderKey(strings.TrimSpace(parts[0]))
+		template.Headers[name] = append(template.Headers[name], strings.TrimSpace(parts[1]))
+	}
+
+	return template, nil
+}
+
+func setIfGiven(field **string, value string) {
+	if value != "" {
+		*field = &value
+	}
+}
+
+// Verify - returns requests received by Hoverfly that match given template
+func (h *Hoverfly) Verify(template matching.RequestTemplate) (*views.VerificationView, error) {
+	url := h.buildURL("/api/verify")
+
+	slingRequest := sling.New().Post(url).BodyJSON(template)
+	response, err := h.performAPIRequest(slingRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == 401 {
+		return nil, errors.New("Hoverfly requires authentication")
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != 200 {
+		return nil, errors.New("Requests could not be verified: " + string(body))
+	}
+
+	var verification views.VerificationView
+	if err = json.Unmarshal(body, &verification); err != nil {
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// CheckVerification - returns error when number of matching requests is not the expected one, negative
+// times expects at least one request
+func CheckVerification(verification *views.VerificationView, times int) error {
+	if times < 0 && verification.Count == 0 {
+		return errors.New("Hoverfly received no matching requests")
+	}
+	if times >= 0 && verification.Count != times {
+		return fmt.Errorf("Expected %d matching requests, Hoverfly received %d", times, verification.Count)
+	}
+	return nil
+}
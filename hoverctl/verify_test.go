@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/SpectoLabs/hoverfly/core/matching"
+	"github.com/SpectoLabs/hoverfly/core/views"
+	. "github.com/onsi/gomega"
+)
+
+func Test_NewVerificationTemplate_FlagsTakePrecedenceOverFile(t *testing.T) {
+	RegisterTestingT(t)
+
+	file, err := ioutil.TempFile("", "verify")
+	Expect(err).To(BeNil())
+	defer os.Remove(file.Name())
+	file.WriteString(`{"method": "GET", "path": "/payments", "body": "{}"}`)
+	file.Close()
+
+	template, err := NewVerificationTemplate(file.Name(), VerificationFlags{
+		Method:  "POST",
+		Headers: []string{"content-type: application/json"},
+	})
+	Expect(err).To(BeNil())
+
+	Expect(*template.Method).To(Equal("POST"))
+	Expect(*template.Path).To(Equal("/payments"))
+	Expect(*template.Body).To(Equal("{}"))
+	Expect(template.Destination).To(BeNil())
+	Expect(template.Headers).To(Equal(map[string][]string{"Content-Type": {"application/json"}}))
+}
+
+func Test_NewVerificationTemplate_RejectsMalformedHeader(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewVerificationTemplate("", VerificationFlags{Headers: []string{"no-value"}})
+	Expect(err).ToNot(BeNil())
+}
+
+func Test_Hoverfly_Verify_PostsTemplate(t *testing.T) {
+	RegisterTestingT(t)
+
+	var received matching.RequestTemplate
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Method).To(Equal("POST"))
+		Expect(r.URL.Path).To(Equal("/api/verify"))
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"count": 2, "data": [{"method": "POST"}, {"method": "POST"}]}`))
+	}))
+	defer server.Close()
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	Expect(err).To(BeNil())
+	hoverfly := Hoverfly{Host: host, AdminPort: port, httpClient: http.DefaultClient}
+
+	method := "POST"
+	verification, err := hoverfly.Verify(matching.RequestTemplate{Method: &method})
+	Expect(err).To(BeNil())
+	Expect(*received.Method).To(Equal("POST"))
+	Expect(verification.Count).To(Equal(2))
+	Expect(verification.Data).To(HaveLen(2))
+}
+
+func Test_CheckVerification(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(CheckVerification(&views.VerificationView{Count: 1}, -1)).To(BeNil())
+	Expect(CheckVerification(&views.VerificationView{Count: 0}, -1)).ToNot(BeNil())
+	Expect(CheckVerification(&views.VerificationView{Count: 2}, 2)).To(BeNil())
+	Expect(CheckVerification(&views.VerificationView{Count: 3}, 2)).ToNot(BeNil())
+	Expect(CheckVerification(&views.VerificationView{Count: 0}, 0)).To(BeNil())
+}